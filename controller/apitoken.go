@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"final-project/dto"
+	"final-project/helper"
+	"final-project/helper/response"
+	"final-project/service"
+	"net/http"
+	"strconv"
+)
+
+type apiTokenController struct {
+	apiTokenService service.APITokenService
+}
+
+func NewAPITokenController(apiTokenService service.APITokenService) *apiTokenController {
+	return &apiTokenController{apiTokenService}
+}
+
+// APITokenCreate godoc
+// @Summary create an API token for scripts and bots
+// @Description the token is only returned once, send it as a Bearer token. API tokens can't be used to manage API tokens
+// @Tags API Token
+// @Accept json
+// @Produce json
+// @Security BearerToken
+// @Param request body dto.APITokenRequest true "required body"
+// @Success 201 {object} response.Response[dto.APITokenCreateResponse]
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 403 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /users/tokens [post]
+func (c *apiTokenController) Create(w http.ResponseWriter, r *http.Request) {
+	var (
+		data dto.APITokenRequest
+		resp = response.New[dto.APITokenCreateResponse](response.APITokenCreate)
+	)
+
+	err := json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	err = data.Validate()
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	token, err := c.apiTokenService.Create(r.Context(), data)
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	resp.Success(true).Data(token).Code(http.StatusCreated).Send(w)
+}
+
+// APITokenGetAll godoc
+// @Summary list the current user's API tokens
+// @Tags API Token
+// @Produce json
+// @Security BearerToken
+// @Success 200 {object} response.Response[[]dto.APITokenResponse]
+// @Failure 401 {object} response.Response[any]
+// @Failure 403 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /users/tokens [get]
+func (c *apiTokenController) GetAll(w http.ResponseWriter, r *http.Request) {
+	var resp = response.New[[]dto.APITokenResponse](response.APITokenGetAll)
+
+	tokens, err := c.apiTokenService.GetAll(r.Context())
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	resp.Success(true).Data(tokens).Code(http.StatusOK).Send(w)
+}
+
+// APITokenDelete godoc
+// @Summary revoke an API token
+// @Tags API Token
+// @Produce json
+// @Security BearerToken
+// @Param tokenID path int true "API token ID"
+// @Success 200 {object} response.Response[any]
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 403 {object} response.Response[any]
+// @Failure 404 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /users/tokens/{tokenID} [delete]
+func (c *apiTokenController) Delete(w http.ResponseWriter, r *http.Request) {
+	var resp = response.New[any](response.APITokenDelete)
+
+	tokenIDStr := r.PathValue("tokenID")
+	tokenID, err := strconv.ParseUint(tokenIDStr, 10, 64)
+	if err != nil {
+		resp.Error(helper.ErrInvalidID).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	err = c.apiTokenService.Delete(r.Context(), tokenID)
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	resp.Success(true).Code(http.StatusOK).Send(w)
+}
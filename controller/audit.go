@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"errors"
+	"final-project/dto"
+	"final-project/helper"
+	"final-project/helper/response"
+	"final-project/service"
+	"net/http"
+)
+
+type auditController struct {
+	auditService service.AuditService
+}
+
+func NewAuditController(auditService service.AuditService) *auditController {
+	return &auditController{auditService}
+}
+
+// AuditGetMine godoc
+// @Summary list security events of the current user's account
+// @Description logins, failed logins, lockouts and changes to the account, newest first
+// @Tags User
+// @Produce json
+// @Security BearerToken
+// @Param action query string false "only events with this action"
+// @Param since query string false "only events at or after this RFC 3339 time"
+// @Param until query string false "only events before this RFC 3339 time"
+// @Param before_id query int false "only events older than this id, for paging"
+// @Param limit query int false "number of events, 1 to 200, defaults to 50"
+// @Success 200 {object} response.Response[[]dto.AuditEventResponse]
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /users/security-events [get]
+func (c *auditController) GetMine(w http.ResponseWriter, r *http.Request) {
+	var resp = response.New[[]dto.AuditEventResponse](response.AuditGetMine)
+
+	query, err := dto.ParseAuditEventQuery(r.URL.Query())
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	events, err := c.auditService.GetMine(r.Context(), query)
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	resp.Success(true).Data(events).Code(http.StatusOK).Send(w)
+}
+
+// AuditSearch godoc
+// @Summary search the audit log
+// @Description for admins only, newest first
+// @Tags Admin
+// @Produce json
+// @Security BearerToken
+// @Param actor_id query int false "only events done by this user"
+// @Param user_id query int false "only events about this user's account"
+// @Param action query string false "only events with this action"
+// @Param target_type query string false "only events on this kind of target"
+// @Param target_id query string false "only events on this target"
+// @Param ip query string false "only events from this client address"
+// @Param since query string false "only events at or after this RFC 3339 time"
+// @Param until query string false "only events before this RFC 3339 time"
+// @Param before_id query int false "only events older than this id, for paging"
+// @Param limit query int false "number of events, 1 to 200, defaults to 50"
+// @Success 200 {object} response.Response[[]dto.AuditEventResponse]
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 403 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /admin/audit-events [get]
+func (c *auditController) Search(w http.ResponseWriter, r *http.Request) {
+	var resp = response.New[[]dto.AuditEventResponse](response.AuditSearch)
+
+	query, err := dto.ParseAuditEventQuery(r.URL.Query())
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	events, err := c.auditService.Search(r.Context(), query)
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	resp.Success(true).Data(events).Code(http.StatusOK).Send(w)
+}
@@ -104,10 +104,14 @@ func (c *commentController) GetAll(w http.ResponseWriter, r *http.Request) {
 // @Security BearerToken
 // @Param commentID path int true "comment ID"
 // @Param request body dto.CommentUpdate true "required body"
+// @Param If-Match header string false "ETag of the resource"
 // @Success 200 {object} response.Response[dto.CommentUpdateResponse]
+// @Header 200 {string} ETag "version of the resource"
 // @Failure 400 {object} response.Response[any]
 // @Failure 401 {object} response.Response[any]
 // @Failure 404 {object} response.Response[any]
+// @Failure 412 {object} response.Response[any]
+// @Failure 428 {object} response.Response[any]
 // @Failure 500 {object} response.Response[any]
 // @Router /comments/{commentID} [put]
 func (c *commentController) Update(w http.ResponseWriter, r *http.Request) {
@@ -146,6 +150,7 @@ func (c *commentController) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", helper.ETag(comment.ID, comment.UpdatedAt))
 	resp.Success(true).Data(comment).Code(http.StatusOK).Send(w)
 }
 
@@ -155,10 +160,13 @@ func (c *commentController) Update(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Security BearerToken
 // @Param commentID path int true "comment ID"
+// @Param If-Match header string false "ETag of the resource"
 // @Success 200 {object} response.Response[any]
 // @Failure 400 {object} response.Response[any]
 // @Failure 401 {object} response.Response[any]
 // @Failure 404 {object} response.Response[any]
+// @Failure 412 {object} response.Response[any]
+// @Failure 428 {object} response.Response[any]
 // @Failure 500 {object} response.Response[any]
 // @Router /comments/{commentID} [delete]
 func (c *commentController) Delete(w http.ResponseWriter, r *http.Request) {
@@ -191,7 +199,10 @@ func (c *commentController) Delete(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Security BearerToken
 // @Param commentID path int true "comment ID"
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} response.Response[dto.CommentResponse]
+// @Header 200 {string} ETag "version of the resource"
+// @Success 304 "not modified"
 // @Failure 400 {object} response.Response[any]
 // @Failure 401 {object} response.Response[any]
 // @Failure 404 {object} response.Response[any]
@@ -218,6 +229,13 @@ func (c *commentController) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	etag := helper.ETag(comment.ID, comment.UpdateAt)
+	w.Header().Set("ETag", etag)
+	if helper.MatchETag(r.Header.Get("If-None-Match"), etag, true) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	resp.Data(comment).Success(true).Code(http.StatusOK).Send(w)
 }
 
@@ -267,13 +285,13 @@ func (c *commentController) GetByPhotoID(w http.ResponseWriter, r *http.Request)
 func (c *commentController) GetMine(w http.ResponseWriter, r *http.Request) {
 	var resp = response.New[[]dto.CommentGetByUserIDResponse](response.CommentGetMine)
 
-	userID, ok := r.Context().Value(helper.UserIDKey).(float64)
+	principal, ok := helper.UserFromContext(r.Context())
 	if !ok {
 		resp.Error(helper.ErrInternal).Code(http.StatusInternalServerError).Send(w)
 		return
 	}
 
-	comments, err := c.commentService.GetByUserID(r.Context(), uint64(userID))
+	comments, err := c.commentService.GetByUserID(r.Context(), principal.UserID)
 	if err != nil {
 		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
 		return
@@ -281,3 +299,100 @@ func (c *commentController) GetMine(w http.ResponseWriter, r *http.Request) {
 
 	resp.Success(true).Data(comments).Code(http.StatusOK).Send(w)
 }
+
+// CommentGetRevisions godoc
+// @Summary get the edit history of a comment
+// @Tags Comment
+// @Produce json
+// @Security BearerToken
+// @Param commentID path int true "comment ID"
+// @Success 200 {object} response.Response[[]dto.CommentRevisionResponse]
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 404 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /comments/{commentID}/revisions [get]
+func (c *commentController) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	var resp = response.New[[]dto.CommentRevisionResponse](response.CommentGetRevisions)
+
+	commentIDStr := r.PathValue("commentID")
+	commentID, err := strconv.ParseUint(commentIDStr, 10, 64)
+	if err != nil {
+		resp.Error(helper.ErrInvalidID).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	revisions, err := c.commentService.GetRevisions(r.Context(), commentID)
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	resp.Data(revisions).Success(true).Code(http.StatusOK).Send(w)
+}
+
+// CommentPatch godoc
+// @Summary partially update a comment
+// @Description only the fields present in the body are updated, fields set to null are cleared when allowed (JSON Merge Patch, RFC 7396)
+// @Tags Comment
+// @Accept json
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerToken
+// @Param commentID path int true "comment ID"
+// @Param request body dto.CommentPatch true "fields to update"
+// @Param If-Match header string false "ETag of the resource"
+// @Success 200 {object} response.Response[dto.CommentUpdateResponse]
+// @Header 200 {string} ETag "version of the resource"
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 404 {object} response.Response[any]
+// @Failure 409 {object} response.Response[any]
+// @Failure 412 {object} response.Response[any]
+// @Failure 428 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /comments/{commentID} [patch]
+func (c *commentController) Patch(w http.ResponseWriter, r *http.Request) {
+	var (
+		data dto.CommentPatchRequest
+		resp = response.New[dto.CommentUpdateResponse](response.CommentUpdate)
+	)
+
+	commentIDStr := r.PathValue("commentID")
+	commentID, err := strconv.ParseUint(commentIDStr, 10, 64)
+	if err != nil {
+		resp.Error(helper.ErrInvalidID).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	err = data.ValidatePatch()
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	comment, err := c.commentService.Patch(r.Context(), commentID, data)
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	w.Header().Set("ETag", helper.ETag(comment.ID, comment.UpdatedAt))
+	resp.Success(true).Data(comment).Code(http.StatusOK).Send(w)
+}
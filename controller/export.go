@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"errors"
+	"final-project/dto"
+	"final-project/helper"
+	"final-project/helper/response"
+	"final-project/service"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type exportController struct {
+	exportService   service.ExportService
+	downloadTimeout time.Duration
+}
+
+func NewExportController(exportService service.ExportService, downloadTimeout time.Duration) *exportController {
+	return &exportController{exportService, downloadTimeout}
+}
+
+// ExportCreate godoc
+// @Summary request an export of the current user's data
+// @Description the archive is built in the background, poll the status endpoint until it's ready
+// @Tags User
+// @Produce json
+// @Security BearerToken
+// @Success 202 {object} response.Response[dto.ExportResponse]
+// @Header 202 {string} Location "status endpoint of the export"
+// @Failure 401 {object} response.Response[any]
+// @Failure 409 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Failure 503 {object} response.Response[any]
+// @Router /users/export [post]
+func (c *exportController) Create(w http.ResponseWriter, r *http.Request) {
+	var resp = response.New[dto.ExportResponse](response.ExportCreate)
+
+	export, err := c.exportService.Create(r.Context())
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("export/%d/status", export.ID))
+	resp.Success(true).Data(export).Code(http.StatusAccepted).Send(w)
+}
+
+// ExportGetByID godoc
+// @Summary get export status
+// @Tags User
+// @Produce json
+// @Security BearerToken
+// @Param exportID path int true "export ID"
+// @Success 200 {object} response.Response[dto.ExportResponse]
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 404 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /users/export/{exportID}/status [get]
+func (c *exportController) GetByID(w http.ResponseWriter, r *http.Request) {
+	var resp = response.New[dto.ExportResponse](response.ExportGetByID)
+
+	exportIDStr := r.PathValue("exportID")
+	exportID, err := strconv.ParseUint(exportIDStr, 10, 64)
+	if err != nil {
+		resp.Error(helper.ErrInvalidID).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	export, err := c.exportService.GetByID(r.Context(), exportID)
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	resp.Data(export).Success(true).Code(http.StatusOK).Send(w)
+}
+
+// ExportDownload godoc
+// @Summary download a finished export
+// @Tags User
+// @Produce application/zip
+// @Security BearerToken
+// @Param exportID path int true "export ID"
+// @Success 200 {file} file "zip archive with JSON and CSV files"
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 404 {object} response.Response[any]
+// @Failure 409 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /users/export/{exportID}/download [get]
+func (c *exportController) Download(w http.ResponseWriter, r *http.Request) {
+	var resp = response.New[any](response.ExportDownload)
+
+	exportIDStr := r.PathValue("exportID")
+	exportID, err := strconv.ParseUint(exportIDStr, 10, 64)
+	if err != nil {
+		resp.Error(helper.ErrInvalidID).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	path, err := c.exportService.GetFile(r.Context(), exportID)
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	// the server's WriteTimeout is sized for API responses, not archives
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(c.downloadTimeout))
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="mygram-export-%d.zip"`, exportID))
+	http.ServeFile(w, r, path)
+}
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"encoding/json"
+	"final-project/dto"
+	"final-project/lib/health"
+	"log/slog"
+	"net/http"
+)
+
+type healthController struct {
+	checker *health.Checker
+	logger  *slog.Logger
+}
+
+func NewHealthController(checker *health.Checker, logger *slog.Logger) *healthController {
+	return &healthController{
+		checker: checker,
+		logger:  logger,
+	}
+}
+
+// Live and Ready are probes for the orchestrator. Like the JWKS they're
+// served at the server root without the response envelope.
+func (c *healthController) Live(w http.ResponseWriter, r *http.Request) {
+	c.send(w, http.StatusOK, dto.HealthResponse{Status: dto.HealthStatusOK})
+}
+
+func (c *healthController) Ready(w http.ResponseWriter, r *http.Request) {
+	results, ok := c.checker.Ready(r.Context())
+	for name, err := range results {
+		if err != nil {
+			c.logger.WarnContext(r.Context(), err.Error(), "check", name)
+		}
+	}
+
+	code := http.StatusOK
+	if !ok {
+		code = http.StatusServiceUnavailable
+	}
+	c.send(w, code, dto.NewHealthResponse(results, ok))
+}
+
+func (c *healthController) send(w http.ResponseWriter, code int, resp dto.HealthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(resp)
+}
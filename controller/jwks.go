@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"final-project/helper"
+	"final-project/helper/response"
+	"log/slog"
+	"net/http"
+)
+
+type jwksController struct {
+	keys   *helper.KeySet
+	logger *slog.Logger
+}
+
+func NewJWKSController(keys *helper.KeySet, logger *slog.Logger) *jwksController {
+	return &jwksController{
+		keys:   keys,
+		logger: logger,
+	}
+}
+
+// JWKS serves the JSON Web Key Set (RFC 7517) at the server root rather
+// than under base_path, so it isn't part of the swagger docs.
+func (c *jwksController) JWKS(w http.ResponseWriter, r *http.Request) {
+	jwks, err := c.keys.JWKS()
+	if err != nil {
+		c.logger.ErrorContext(r.Context(), err.Error())
+		response.New[any](response.Default).Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jwks)
+}
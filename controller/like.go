@@ -148,13 +148,13 @@ func (c *likeController) GetMine(w http.ResponseWriter, r *http.Request) {
 		err  error
 	)
 
-	userID, ok := r.Context().Value(helper.UserIDKey).(float64)
+	principal, ok := helper.UserFromContext(r.Context())
 	if !ok {
 		resp.Error(helper.ErrInternal).Code(http.StatusInternalServerError).Send(w)
 		return
 	}
 
-	photos, err := c.likeService.GetByUserID(r.Context(), uint64(userID))
+	photos, err := c.likeService.GetByUserID(r.Context(), principal.UserID)
 	if err != nil {
 		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
 		return
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"encoding/json"
+	"final-project/dto"
+	"final-project/helper/response"
+	"final-project/model"
+	"final-project/service"
+	"log/slog"
+	"net/http"
+)
+
+type logController struct {
+	level  *slog.LevelVar
+	audit  service.AuditRecorder
+	logger *slog.Logger
+}
+
+func NewLogController(level *slog.LevelVar, audit service.AuditRecorder, logger *slog.Logger) *logController {
+	return &logController{level, audit, logger}
+}
+
+// LogLevelGet godoc
+// @Summary get the current log level
+// @Tags Admin
+// @Produce json
+// @Security BearerToken
+// @Success 200 {object} response.Response[dto.LogLevelResponse]
+// @Failure 401 {object} response.Response[any]
+// @Failure 403 {object} response.Response[any]
+// @Router /admin/log-level [get]
+func (c *logController) Get(w http.ResponseWriter, r *http.Request) {
+	var resp = response.New[dto.LogLevelResponse](response.LogLevelGet)
+
+	resp.Success(true).Data(dto.LogLevelResponse{Level: c.level.Level().String()}).Code(http.StatusOK).Send(w)
+}
+
+// LogLevelUpdate godoc
+// @Summary change the log level until the server restarts
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerToken
+// @Param request body dto.LogLevelRequest true "required body"
+// @Success 200 {object} response.Response[dto.LogLevelResponse]
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 403 {object} response.Response[any]
+// @Router /admin/log-level [put]
+func (c *logController) Update(w http.ResponseWriter, r *http.Request) {
+	var (
+		data dto.LogLevelRequest
+		resp = response.New[dto.LogLevelResponse](response.LogLevelUpdate)
+	)
+
+	err := json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	err = data.Validate()
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	previous := c.level.Level()
+	_ = c.level.UnmarshalText([]byte(data.Level))
+	c.logger.WarnContext(r.Context(), "log level changed", "from", previous.String(), "to", c.level.Level().String())
+	c.audit.Record(r.Context(), model.AuditEvent{
+		Action:     model.AuditLogLevelChanged,
+		TargetType: "log_level",
+		Metadata:   map[string]string{"from": previous.String(), "to": c.level.Level().String()},
+	})
+
+	resp.Success(true).Data(dto.LogLevelResponse{Level: c.level.Level().String()}).Code(http.StatusOK).Send(w)
+}
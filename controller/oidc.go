@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"final-project/dto"
+	"final-project/helper"
+	"final-project/helper/response"
+	"net/http"
+)
+
+const oidcStateCookie = "oidc_state"
+
+// UserOIDCLogin godoc
+// @Summary log in with an OpenID Connect provider
+// @Description redirects to the provider, which sends the user back to the callback endpoint
+// @Tags User
+// @Param provider path string true "provider name"
+// @Success 302
+// @Header 302 {string} Location "authorization endpoint of the provider"
+// @Failure 404 {object} response.Response[any]
+// @Failure 502 {object} response.Response[any]
+// @Router /users/oidc/{provider}/login [get]
+func (u *userController) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	var resp = response.New[any](response.UserOIDCLogin)
+
+	authURL, stateToken, err := u.userService.OIDCAuthURL(r.Context(), r.PathValue("provider"))
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    stateToken,
+		Path:     "/",
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// UserOIDCCallback godoc
+// @Summary finish logging in with an OpenID Connect provider
+// @Description logs in the user the identity is linked to, or the user with the same email when both sides have verified it. When only the email matches link_required is set, and the link_token has to be sent to /users/oidc/link after logging in with the password. Otherwise signup_required is set and the signup_token has to be sent to /users/oidc/register
+// @Tags User
+// @Produce json
+// @Param provider path string true "provider name"
+// @Param code query string true "authorization code"
+// @Param state query string true "state"
+// @Success 200 {object} response.Response[dto.UserLoginResponse]
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 404 {object} response.Response[any]
+// @Failure 422 {object} response.Response[any]
+// @Failure 423 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /users/oidc/{provider}/callback [get]
+func (u *userController) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	var resp = response.New[dto.UserLoginResponse](response.UserOIDCLogin)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	query := r.URL.Query()
+	if query.Get("error") != "" {
+		resp.Error(helper.ErrOIDCLoginFailed).Code(http.StatusUnauthorized).Send(w)
+		return
+	}
+
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil {
+		resp.Error(helper.ErrInvalidOIDCState).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	token, err := u.userService.OIDCCallback(r.Context(), r.PathValue("provider"), query.Get("code"), query.Get("state"), cookie.Value)
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	resp.Success(true).Data(token).Code(http.StatusOK).Send(w)
+}
+
+// UserOIDCRegister godoc
+// @Summary sign up with an OpenID Connect identity
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body dto.OIDCRegisterRequest true "required body"
+// @Success 201 {object} response.Response[dto.UserLoginResponse]
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 409 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /users/oidc/register [post]
+func (u *userController) OIDCRegister(w http.ResponseWriter, r *http.Request) {
+	var (
+		data dto.OIDCRegisterRequest
+		resp = response.New[dto.UserLoginResponse](response.UserOIDCRegister)
+	)
+
+	err := json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	err = data.Validate()
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	token, err := u.userService.OIDCRegister(r.Context(), data)
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	resp.Success(true).Data(token).Code(http.StatusCreated).Send(w)
+}
+
+// UserOIDCLink godoc
+// @Summary link an OpenID Connect identity to the logged in user
+// @Description the link_token comes from /users/oidc/{provider}/callback when the identity matched the user's email but it wasn't verified on both sides
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerToken
+// @Param request body dto.OIDCLinkRequest true "required body"
+// @Success 200 {object} response.Response[any]
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /users/oidc/link [post]
+func (u *userController) OIDCLink(w http.ResponseWriter, r *http.Request) {
+	var (
+		data dto.OIDCLinkRequest
+		resp = response.New[any](response.UserOIDCLink)
+	)
+
+	err := json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	err = data.Validate()
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	err = u.userService.OIDCLink(r.Context(), data)
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	resp.Success(true).Code(http.StatusOK).Send(w)
+}
@@ -98,11 +98,15 @@ func (c *photoController) GetAll(w http.ResponseWriter, r *http.Request) {
 // @Security BearerToken
 // @Param photoID path int true "photo id"
 // @Param request body dto.PhotoUpdate true "required body"
+// @Param If-Match header string false "ETag of the resource"
 // @Success 200 {object} response.Response[dto.PhotoUpdateResponse]
+// @Header 200 {string} ETag "version of the resource"
 // @Failure 400 {object} response.Response[any]
 // @Failure 401 {object} response.Response[any]
 // @Failure 404 {object} response.Response[any]
 // @Failure 409 {object} response.Response[any]
+// @Failure 412 {object} response.Response[any]
+// @Failure 428 {object} response.Response[any]
 // @Failure 500 {object} response.Response[any]
 // @Router /photos/{photoID} [put]
 func (c *photoController) Update(w http.ResponseWriter, r *http.Request) {
@@ -141,6 +145,7 @@ func (c *photoController) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", helper.ETag(photo.ID, photo.UpdatedAt))
 	resp.Success(true).Data(photo).Code(http.StatusOK).Send(w)
 }
 
@@ -150,10 +155,13 @@ func (c *photoController) Update(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Security BearerToken
 // @Param photoID path int true "photo id"
+// @Param If-Match header string false "ETag of the resource"
 // @Success 200 {object} response.Response[any]
 // @Failure 400 {object} response.Response[any]
 // @Failure 401 {object} response.Response[any]
 // @Failure 404 {object} response.Response[any]
+// @Failure 412 {object} response.Response[any]
+// @Failure 428 {object} response.Response[any]
 // @Failure 500 {object} response.Response[any]
 // @Router /photos/{photoID} [delete]
 func (c *photoController) Delete(w http.ResponseWriter, r *http.Request) {
@@ -186,7 +194,10 @@ func (c *photoController) Delete(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Security BearerToken
 // @Param photoID path int true "photo id"
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} response.Response[dto.PhotoResponse]
+// @Header 200 {string} ETag "version of the resource"
+// @Success 304 "not modified"
 // @Failure 400 {object} response.Response[any]
 // @Failure 401 {object} response.Response[any]
 // @Failure 404 {object} response.Response[any]
@@ -213,6 +224,13 @@ func (c *photoController) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	etag := helper.ETag(photo.ID, photo.UpdatedAt)
+	w.Header().Set("ETag", etag)
+	if helper.MatchETag(r.Header.Get("If-None-Match"), etag, true) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	resp.Data(photo).Success(true).Code(http.StatusOK).Send(w)
 }
 
@@ -228,13 +246,13 @@ func (c *photoController) GetByID(w http.ResponseWriter, r *http.Request) {
 func (c *photoController) GetMine(w http.ResponseWriter, r *http.Request) {
 	var resp = response.New[[]dto.PhotoResponse](response.PhotoGetMine)
 
-	userID, ok := r.Context().Value(helper.UserIDKey).(float64)
+	principal, ok := helper.UserFromContext(r.Context())
 	if !ok {
 		resp.Error(helper.ErrInternal).Code(http.StatusInternalServerError).Send(w)
 		return
 	}
 
-	photos, err := c.photoService.GetByUserID(r.Context(), uint64(userID))
+	photos, err := c.photoService.GetByUserID(r.Context(), principal.UserID)
 	if err != nil {
 		respErr := new(helper.ResponseError)
 		if errors.As(err, &respErr) {
@@ -278,3 +296,100 @@ func (c *photoController) GetByUsername(w http.ResponseWriter, r *http.Request)
 
 	resp.Data(photos).Success(true).Code(http.StatusOK).Send(w)
 }
+
+// PhotoGetRevisions godoc
+// @Summary get the edit history of a photo
+// @Tags Photo
+// @Produce json
+// @Security BearerToken
+// @Param photoID path int true "photo id"
+// @Success 200 {object} response.Response[[]dto.PhotoRevisionResponse]
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 404 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /photos/{photoID}/revisions [get]
+func (c *photoController) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	var resp = response.New[[]dto.PhotoRevisionResponse](response.PhotoGetRevisions)
+
+	photoIDStr := r.PathValue("photoID")
+	photoID, err := strconv.ParseUint(photoIDStr, 10, 64)
+	if err != nil {
+		resp.Error(helper.ErrInvalidID).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	revisions, err := c.photoService.GetRevisions(r.Context(), photoID)
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	resp.Data(revisions).Success(true).Code(http.StatusOK).Send(w)
+}
+
+// PhotoPatch godoc
+// @Summary partially update a photo
+// @Description only the fields present in the body are updated, fields set to null are cleared when allowed (JSON Merge Patch, RFC 7396)
+// @Tags Photo
+// @Accept json
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerToken
+// @Param photoID path int true "photo id"
+// @Param request body dto.PhotoPatch true "fields to update"
+// @Param If-Match header string false "ETag of the resource"
+// @Success 200 {object} response.Response[dto.PhotoUpdateResponse]
+// @Header 200 {string} ETag "version of the resource"
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 404 {object} response.Response[any]
+// @Failure 409 {object} response.Response[any]
+// @Failure 412 {object} response.Response[any]
+// @Failure 428 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /photos/{photoID} [patch]
+func (c *photoController) Patch(w http.ResponseWriter, r *http.Request) {
+	var (
+		data dto.PhotoPatchRequest
+		resp = response.New[dto.PhotoUpdateResponse](response.PhotoUpdate)
+	)
+
+	photoIDStr := r.PathValue("photoID")
+	photoID, err := strconv.ParseUint(photoIDStr, 10, 64)
+	if err != nil {
+		resp.Error(helper.ErrInvalidID).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	err = data.ValidatePatch()
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	photo, err := c.photoService.Patch(r.Context(), photoID, data)
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	w.Header().Set("ETag", helper.ETag(photo.ID, photo.UpdatedAt))
+	resp.Success(true).Data(photo).Code(http.StatusOK).Send(w)
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"errors"
+	"final-project/dto"
+	"final-project/helper"
+	"final-project/helper/response"
+	"final-project/service"
+	"net/http"
+	"strconv"
+)
+
+type sessionController struct {
+	sessionService service.SessionService
+}
+
+func NewSessionController(sessionService service.SessionService) *sessionController {
+	return &sessionController{sessionService}
+}
+
+// SessionGetAll godoc
+// @Summary list the devices the current user is logged in on
+// @Tags User
+// @Produce json
+// @Security BearerToken
+// @Success 200 {object} response.Response[[]dto.SessionResponse]
+// @Failure 401 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /users/sessions [get]
+func (c *sessionController) GetAll(w http.ResponseWriter, r *http.Request) {
+	var resp = response.New[[]dto.SessionResponse](response.SessionGetAll)
+
+	sessions, err := c.sessionService.GetAll(r.Context())
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	resp.Success(true).Data(sessions).Code(http.StatusOK).Send(w)
+}
+
+// SessionDelete godoc
+// @Summary log out a device
+// @Description revokes the session, tokens issued for it are rejected from then on
+// @Tags User
+// @Produce json
+// @Security BearerToken
+// @Param sessionID path int true "session ID"
+// @Success 200 {object} response.Response[any]
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 404 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /users/sessions/{sessionID} [delete]
+func (c *sessionController) Delete(w http.ResponseWriter, r *http.Request) {
+	var resp = response.New[any](response.SessionDelete)
+
+	sessionIDStr := r.PathValue("sessionID")
+	sessionID, err := strconv.ParseUint(sessionIDStr, 10, 64)
+	if err != nil {
+		resp.Error(helper.ErrInvalidID).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	err = c.sessionService.Delete(r.Context(), sessionID)
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	resp.Success(true).Code(http.StatusOK).Send(w)
+}
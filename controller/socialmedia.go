@@ -97,11 +97,15 @@ func (c *socialMediaController) GetAll(w http.ResponseWriter, r *http.Request) {
 // @Security BearerToken
 // @Param socialMediaID path int true "social media ID"
 // @Param request body dto.SocialMediaUpdate true "required body"
+// @Param If-Match header string false "ETag of the resource"
 // @Success 200 {object} response.Response[dto.SocialMediaUpdateResponse]
+// @Header 200 {string} ETag "version of the resource"
 // @Failure 400 {object} response.Response[any]
 // @Failure 401 {object} response.Response[any]
 // @Failure 404 {object} response.Response[any]
 // @Failure 409 {object} response.Response[any]
+// @Failure 412 {object} response.Response[any]
+// @Failure 428 {object} response.Response[any]
 // @Failure 500 {object} response.Response[any]
 // @Router /socialmedias/{socialMediaID} [put]
 func (c *socialMediaController) Update(w http.ResponseWriter, r *http.Request) {
@@ -140,6 +144,7 @@ func (c *socialMediaController) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", helper.ETag(socialMedia.ID, socialMedia.UpdatedAt))
 	resp.Success(true).Data(socialMedia).Code(http.StatusOK).Send(w)
 }
 
@@ -149,10 +154,13 @@ func (c *socialMediaController) Update(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Security BearerToken
 // @Param socialMediaID path int true "social media ID"
+// @Param If-Match header string false "ETag of the resource"
 // @Success 200 {object} response.Response[any]
 // @Failure 400 {object} response.Response[any]
 // @Failure 401 {object} response.Response[any]
 // @Failure 404 {object} response.Response[any]
+// @Failure 412 {object} response.Response[any]
+// @Failure 428 {object} response.Response[any]
 // @Failure 500 {object} response.Response[any]
 // @Router /socialmedias/{socialMediaID} [delete]
 func (c *socialMediaController) Delete(w http.ResponseWriter, r *http.Request) {
@@ -185,7 +193,10 @@ func (c *socialMediaController) Delete(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Security BearerToken
 // @Param socialMediaID path int true "social media ID"
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} response.Response[dto.SocialMediaResponse]
+// @Header 200 {string} ETag "version of the resource"
+// @Success 304 "not modified"
 // @Failure 400 {object} response.Response[any]
 // @Failure 401 {object} response.Response[any]
 // @Failure 404 {object} response.Response[any]
@@ -212,6 +223,13 @@ func (c *socialMediaController) GetByID(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	etag := helper.ETag(socialMedia.ID, socialMedia.UpdatedAt)
+	w.Header().Set("ETag", etag)
+	if helper.MatchETag(r.Header.Get("If-None-Match"), etag, true) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	resp.Data(socialMedia).Success(true).Code(http.StatusOK).Send(w)
 }
 
@@ -226,13 +244,13 @@ func (c *socialMediaController) GetByID(w http.ResponseWriter, r *http.Request)
 // @Router /socialmedias/my [get]
 func (c *socialMediaController) GetMine(w http.ResponseWriter, r *http.Request) {
 	var resp = response.New[[]dto.SocialMediaGetByUserIDResponse](response.SocialMediaGetMine)
-	userID, ok := r.Context().Value(helper.UserIDKey).(float64)
+	principal, ok := helper.UserFromContext(r.Context())
 	if !ok {
 		resp.Error(helper.ErrInternal).Code(http.StatusInternalServerError).Send(w)
 		return
 	}
 
-	socialMedia, err := c.socialMediaService.GetByUserID(r.Context(), uint64(userID))
+	socialMedia, err := c.socialMediaService.GetByUserID(r.Context(), principal.UserID)
 	if err != nil {
 		respErr := new(helper.ResponseError)
 		if errors.As(err, &respErr) {
@@ -245,3 +263,64 @@ func (c *socialMediaController) GetMine(w http.ResponseWriter, r *http.Request)
 
 	resp.Data(socialMedia).Success(true).Code(http.StatusOK).Send(w)
 }
+
+// SocialMediaPatch godoc
+// @Summary partially update a social media
+// @Description only the fields present in the body are updated, fields set to null are cleared when allowed (JSON Merge Patch, RFC 7396)
+// @Tags Social Media
+// @Accept json
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerToken
+// @Param socialMediaID path int true "social media ID"
+// @Param request body dto.SocialMediaPatch true "fields to update"
+// @Param If-Match header string false "ETag of the resource"
+// @Success 200 {object} response.Response[dto.SocialMediaUpdateResponse]
+// @Header 200 {string} ETag "version of the resource"
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 404 {object} response.Response[any]
+// @Failure 409 {object} response.Response[any]
+// @Failure 412 {object} response.Response[any]
+// @Failure 428 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /socialmedias/{socialMediaID} [patch]
+func (c *socialMediaController) Patch(w http.ResponseWriter, r *http.Request) {
+	var (
+		data dto.SocialMediaPatchRequest
+		resp = response.New[dto.SocialMediaUpdateResponse](response.SocialMediaUpdate)
+	)
+
+	socialMediaIDStr := r.PathValue("socialMediaID")
+	socialMediaID, err := strconv.ParseUint(socialMediaIDStr, 10, 64)
+	if err != nil {
+		resp.Error(helper.ErrInvalidID).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	err = data.ValidatePatch()
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	socialMedia, err := c.socialMediaService.Patch(r.Context(), socialMediaID, data)
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	w.Header().Set("ETag", helper.ETag(socialMedia.ID, socialMedia.UpdatedAt))
+	resp.Success(true).Data(socialMedia).Code(http.StatusOK).Send(w)
+}
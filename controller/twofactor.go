@@ -0,0 +1,221 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"final-project/dto"
+	"final-project/helper"
+	"final-project/helper/response"
+	"net/http"
+)
+
+// UserLoginTOTP godoc
+// @Summary finish a login with a two-factor code
+// @Description second step of the login for users with two-factor authentication, code is either a TOTP code or an unused recovery code
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body dto.UserLoginTOTPRequest true "required body"
+// @Success 200 {object} response.Response[dto.UserLoginResponse]
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 423 {object} response.Response[any]
+// @Failure 429 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /users/login/2fa [post]
+func (u *userController) LoginTOTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		data dto.UserLoginTOTPRequest
+		resp = response.New[dto.UserLoginResponse](response.UserLogin)
+	)
+
+	err := json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	err = data.Validate()
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	token, err := u.userService.LoginTOTP(r.Context(), data)
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	resp.Success(true).Data(token).Code(http.StatusOK).Send(w)
+}
+
+// UserSetupTOTP godoc
+// @Summary start two-factor authentication enrollment
+// @Description returns a new secret, it's only enabled once a code from it is sent to /users/2fa/verify
+// @Tags User
+// @Produce json
+// @Security BearerToken
+// @Success 200 {object} response.Response[dto.TOTPSetupResponse]
+// @Failure 401 {object} response.Response[any]
+// @Failure 409 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /users/2fa/setup [post]
+func (u *userController) SetupTOTP(w http.ResponseWriter, r *http.Request) {
+	var resp = response.New[dto.TOTPSetupResponse](response.UserTOTPSetup)
+
+	setup, err := u.userService.SetupTOTP(r.Context())
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	resp.Success(true).Data(setup).Code(http.StatusOK).Send(w)
+}
+
+// UserVerifyTOTP godoc
+// @Summary enable two-factor authentication
+// @Description the recovery codes are only shown once
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerToken
+// @Param request body dto.TOTPCodeRequest true "code from the authenticator app"
+// @Success 200 {object} response.Response[dto.RecoveryCodesResponse]
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 409 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /users/2fa/verify [post]
+func (u *userController) VerifyTOTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		data dto.TOTPCodeRequest
+		resp = response.New[dto.RecoveryCodesResponse](response.UserTOTPVerify)
+	)
+
+	err := json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	err = data.Validate()
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	codes, err := u.userService.EnableTOTP(r.Context(), data)
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	resp.Success(true).Data(codes).Code(http.StatusOK).Send(w)
+}
+
+// UserDisableTOTP godoc
+// @Summary disable two-factor authentication
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerToken
+// @Param request body dto.TOTPCodeRequest true "TOTP code or recovery code"
+// @Success 200 {object} response.Response[any]
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 409 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /users/2fa/disable [post]
+func (u *userController) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		data dto.TOTPCodeRequest
+		resp = response.New[any](response.UserTOTPDisable)
+	)
+
+	err := json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	err = data.Validate()
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	err = u.userService.DisableTOTP(r.Context(), data)
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	resp.Success(true).Code(http.StatusOK).Send(w)
+}
+
+// UserRegenerateRecoveryCodes godoc
+// @Summary replace the two-factor recovery codes
+// @Description the previous codes stop working, the new ones are only shown once
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerToken
+// @Param request body dto.TOTPCodeRequest true "TOTP code or recovery code"
+// @Success 200 {object} response.Response[dto.RecoveryCodesResponse]
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 409 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /users/2fa/recovery-codes [post]
+func (u *userController) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	var (
+		data dto.TOTPCodeRequest
+		resp = response.New[dto.RecoveryCodesResponse](response.UserRecoveryCodes)
+	)
+
+	err := json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	err = data.Validate()
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	codes, err := u.userService.RegenerateRecoveryCodes(r.Context(), data)
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	resp.Success(true).Data(codes).Code(http.StatusOK).Send(w)
+}
@@ -8,6 +8,7 @@ import (
 	"final-project/helper/response"
 	"final-project/service"
 	"net/http"
+	"strconv"
 )
 
 type userController struct {
@@ -72,6 +73,8 @@ func (u *userController) Register(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} response.Response[dto.UserLoginResponse]
 // @Failure 400 {object} response.Response[any]
 // @Failure 401 {object} response.Response[any]
+// @Failure 423 {object} response.Response[any]
+// @Failure 429 {object} response.Response[any]
 // @Failure 500 {object} response.Response[any]
 // @Router /users/login [post]
 func (u *userController) Login(w http.ResponseWriter, r *http.Request) {
@@ -106,6 +109,44 @@ func (u *userController) Login(w http.ResponseWriter, r *http.Request) {
 	resp.Success(true).Data(token).Code(http.StatusOK).Send(w)
 }
 
+// UserGet godoc
+// @Summary get the current user
+// @Description the ETag is what PUT, PATCH and DELETE /users expect in If-Match
+// @Tags User
+// @Produce json
+// @Security BearerToken
+// @Param If-None-Match header string false "ETag from a previous response"
+// @Success 200 {object} response.Response[dto.UserResponse]
+// @Header 200 {string} ETag "version of the resource"
+// @Success 304 "not modified"
+// @Failure 401 {object} response.Response[any]
+// @Failure 404 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /users [get]
+func (u *userController) Get(w http.ResponseWriter, r *http.Request) {
+	var resp = response.New[dto.UserResponse](response.UserGet)
+
+	user, err := u.userService.Get(r.Context())
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	etag := helper.ETag(user.ID, user.UpdatedAt)
+	w.Header().Set("ETag", etag)
+	if helper.MatchETag(r.Header.Get("If-None-Match"), etag, true) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	resp.Success(true).Data(user).Code(http.StatusOK).Send(w)
+}
+
 // UserUpdate godoc
 // @Summary update user
 // @Tags User
@@ -113,10 +154,14 @@ func (u *userController) Login(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Security BearerToken
 // @Param request body dto.UserUpdate true "required body"
+// @Param If-Match header string false "ETag of the resource"
 // @Success 200 {object} response.Response[dto.UserUpdateResponse]
+// @Header 200 {string} ETag "version of the resource"
 // @Failure 400 {object} response.Response[any]
 // @Failure 404 {object} response.Response[any]
 // @Failure 409 {object} response.Response[any]
+// @Failure 412 {object} response.Response[any]
+// @Failure 428 {object} response.Response[any]
 // @Failure 500 {object} response.Response[any]
 // @Router /users [put]
 func (u *userController) Update(w http.ResponseWriter, r *http.Request) {
@@ -148,22 +193,117 @@ func (u *userController) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", helper.ETag(user.ID, user.UpdatedAt))
 	resp.Success(true).Data(user).Code(http.StatusOK).Send(w)
 }
 
 // UserDelete godoc
-// @Summary delete user
+// @Summary schedule the current user for deletion
+// @Description the account is removed once delete_after has passed, logging in before then cancels the deletion
 // @Tags User
 // @Produce json
 // @Security BearerToken
-// @Success 200 {object} response.Response[any]
+// @Param If-Match header string false "ETag of the resource"
+// @Success 200 {object} response.Response[dto.UserDeleteResponse]
 // @Failure 404 {object} response.Response[any]
+// @Failure 412 {object} response.Response[any]
+// @Failure 428 {object} response.Response[any]
 // @Failure 500 {object} response.Response[any]
 // @Router /users [delete]
 func (u *userController) Delete(w http.ResponseWriter, r *http.Request) {
-	var resp = response.New[any](response.UserDelete)
+	var resp = response.New[dto.UserDeleteResponse](response.UserDelete)
+
+	deletion, err := u.userService.Delete(r.Context())
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	resp.Success(true).Data(deletion).Code(http.StatusOK).Send(w)
+}
+
+// UserPatch godoc
+// @Summary partially update the current user
+// @Description only the fields present in the body are updated, fields set to null are cleared when allowed (JSON Merge Patch, RFC 7396)
+// @Tags User
+// @Accept json
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerToken
+// @Param request body dto.UserPatch true "fields to update"
+// @Param If-Match header string false "ETag of the resource"
+// @Success 200 {object} response.Response[dto.UserUpdateResponse]
+// @Header 200 {string} ETag "version of the resource"
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 404 {object} response.Response[any]
+// @Failure 409 {object} response.Response[any]
+// @Failure 412 {object} response.Response[any]
+// @Failure 428 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /users [patch]
+func (u *userController) Patch(w http.ResponseWriter, r *http.Request) {
+	var (
+		data dto.UserPatchRequest
+		resp = response.New[dto.UserUpdateResponse](response.UserUpdate)
+	)
+
+	err := json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	err = data.ValidatePatch()
+	if err != nil {
+		resp.Error(err).Code(http.StatusBadRequest).Send(w)
+		return
+	}
+
+	user, err := u.userService.Patch(r.Context(), data)
+	if err != nil {
+		respErr := new(helper.ResponseError)
+		if errors.As(err, &respErr) {
+			resp.Error(respErr).Code(respErr.Code()).Send(w)
+			return
+		}
+		resp.Error(err).Code(http.StatusInternalServerError).Send(w)
+		return
+	}
+
+	w.Header().Set("ETag", helper.ETag(user.ID, user.UpdatedAt))
+	resp.Success(true).Data(user).Code(http.StatusOK).Send(w)
+}
+
+// UserUnlock godoc
+// @Summary unlock a user locked out after failed logins
+// @Tags Admin
+// @Produce json
+// @Security BearerToken
+// @Param userID path int true "user ID"
+// @Success 200 {object} response.Response[any]
+// @Failure 400 {object} response.Response[any]
+// @Failure 401 {object} response.Response[any]
+// @Failure 403 {object} response.Response[any]
+// @Failure 404 {object} response.Response[any]
+// @Failure 500 {object} response.Response[any]
+// @Router /admin/users/{userID}/unlock [post]
+func (u *userController) Unlock(w http.ResponseWriter, r *http.Request) {
+	var resp = response.New[any](response.UserUnlock)
+
+	userIDStr := r.PathValue("userID")
+	userID, err := strconv.ParseUint(userIDStr, 10, 64)
+	if err != nil {
+		resp.Error(helper.ErrInvalidID).Code(http.StatusBadRequest).Send(w)
+		return
+	}
 
-	err := u.userService.Delete(r.Context())
+	err = u.userService.Unlock(r.Context(), userID)
 	if err != nil {
 		respErr := new(helper.ResponseError)
 		if errors.As(err, &respErr) {
@@ -15,25 +15,94 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/comments": {
+        "/admin/audit-events": {
             "get": {
                 "security": [
                     {
                         "BearerToken": []
                     }
                 ],
+                "description": "for admins only, newest first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Comment"
+                    "Admin"
+                ],
+                "summary": "search the audit log",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "only events done by this user",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only events about this user's account",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only events with this action",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only events on this kind of target",
+                        "name": "target_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only events on this target",
+                        "name": "target_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only events from this client address",
+                        "name": "ip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only events at or after this RFC 3339 time",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only events before this RFC 3339 time",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only events older than this id, for paging",
+                        "name": "before_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "number of events, 1 to 200, defaults to 50",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "get all comments",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-array_dto_CommentResponse"
+                            "$ref": "#/definitions/response.Response-array_dto_AuditEventResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
                         }
                     },
                     "401": {
@@ -42,6 +111,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response-any"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -51,7 +126,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/comments/my": {
+        "/admin/log-level": {
             "get": {
                 "security": [
                     {
@@ -62,14 +137,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Comment"
+                    "Admin"
                 ],
-                "summary": "get current user's comments",
+                "summary": "get the current log level",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-array_dto_CommentGetByUserIDResponse"
+                            "$ref": "#/definitions/response.Response-dto_LogLevelResponse"
                         }
                     },
                     "401": {
@@ -78,43 +153,46 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response-any"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/response.Response-any"
                         }
                     }
                 }
-            }
-        },
-        "/comments/{commentID}": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "BearerToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Comment"
+                    "Admin"
                 ],
-                "summary": "get a comment by ID",
+                "summary": "change the log level until the server restarts",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "comment ID",
-                        "name": "commentID",
-                        "in": "path",
-                        "required": true
+                        "description": "required body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LogLevelRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-dto_CommentResponse"
+                            "$ref": "#/definitions/response.Response-dto_LogLevelResponse"
                         }
                     },
                     "400": {
@@ -129,59 +207,43 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response-any"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/response.Response-any"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/response.Response-any"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/admin/users/{userID}/unlock": {
+            "post": {
                 "security": [
                     {
                         "BearerToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Comment"
+                    "Admin"
                 ],
-                "summary": "update a comment",
+                "summary": "unlock a user locked out after failed logins",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "comment ID",
-                        "name": "commentID",
+                        "description": "user ID",
+                        "name": "userID",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "required body",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.CommentUpdate"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-dto_CommentUpdateResponse"
+                            "$ref": "#/definitions/response.Response-any"
                         }
                     },
                     "400": {
@@ -196,6 +258,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response-any"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -209,8 +277,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/comments": {
+            "get": {
                 "security": [
                     {
                         "BearerToken": []
@@ -222,27 +292,12 @@ const docTemplate = `{
                 "tags": [
                     "Comment"
                 ],
-                "summary": "delete a comment",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "comment ID",
-                        "name": "commentID",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
+                "summary": "get all comments",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-any"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/response.Response-any"
+                            "$ref": "#/definitions/response.Response-array_dto_CommentResponse"
                         }
                     },
                     "401": {
@@ -251,12 +306,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response-any"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/response.Response-any"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -266,32 +315,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/likes/my": {
+        "/comments/my": {
             "get": {
                 "security": [
                     {
                         "BearerToken": []
                     }
                 ],
-                "description": "Get list of photo that user liked",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Like"
+                    "Comment"
                 ],
-                "summary": "Get list of photo that user liked",
+                "summary": "get current user's comments",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-array_dto_PhotoResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/response.Response-any"
+                            "$ref": "#/definitions/response.Response-array_dto_CommentGetByUserIDResponse"
                         }
                     },
                     "401": {
@@ -309,28 +351,55 @@ const docTemplate = `{
                 }
             }
         },
-        "/photos": {
+        "/comments/{commentID}": {
             "get": {
                 "security": [
                     {
                         "BearerToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Photo"
+                    "Comment"
+                ],
+                "summary": "get a comment by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "comment ID",
+                        "name": "commentID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
                 ],
-                "summary": "get all photos",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-array_dto_PhotoResponse"
+                            "$ref": "#/definitions/response.Response-dto_CommentResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the resource"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
                         }
                     },
                     "401": {
@@ -339,6 +408,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response-any"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -347,7 +422,7 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
+            "put": {
                 "security": [
                     {
                         "BearerToken": []
@@ -360,25 +435,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Photo"
+                    "Comment"
                 ],
-                "summary": "create a new photo",
+                "summary": "update a comment",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "comment ID",
+                        "name": "commentID",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "description": "required body",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.PhotoCreate"
+                            "$ref": "#/definitions/dto.CommentUpdate"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the resource",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-dto_PhotoCreateResponse"
+                            "$ref": "#/definitions/response.Response-dto_CommentUpdateResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the resource"
+                            }
                         }
                     },
                     "400": {
@@ -393,38 +487,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response-any"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/response.Response-any"
                         }
-                    }
-                }
-            }
-        },
-        "/photos/my": {
-            "get": {
-                "security": [
-                    {
-                        "BearerToken": []
-                    }
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Photo"
-                ],
-                "summary": "get current user's photos",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-array_dto_PhotoResponse"
+                            "$ref": "#/definitions/response.Response-any"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "428": {
+                        "description": "Precondition Required",
                         "schema": {
                             "$ref": "#/definitions/response.Response-any"
                         }
@@ -436,10 +512,8 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/photos/{photoID}": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerToken": []
@@ -449,23 +523,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Photo"
+                    "Comment"
                 ],
-                "summary": "get a photo by id",
+                "summary": "delete a comment",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "photo id",
-                        "name": "photoID",
+                        "description": "comment ID",
+                        "name": "commentID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the resource",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-dto_PhotoResponse"
+                            "$ref": "#/definitions/response.Response-any"
                         }
                     },
                     "400": {
@@ -486,6 +566,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response-any"
                         }
                     },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -494,45 +586,59 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
+            "patch": {
                 "security": [
                     {
                         "BearerToken": []
                     }
                 ],
+                "description": "only the fields present in the body are updated, fields set to null are cleared when allowed (JSON Merge Patch, RFC 7396)",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/merge-patch+json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Photo"
+                    "Comment"
                 ],
-                "summary": "update a photo",
+                "summary": "partially update a comment",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "photo id",
-                        "name": "photoID",
+                        "description": "comment ID",
+                        "name": "commentID",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "required body",
+                        "description": "fields to update",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.PhotoUpdate"
+                            "$ref": "#/definitions/dto.CommentPatch"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the resource",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-dto_PhotoUpdateResponse"
+                            "$ref": "#/definitions/response.Response-dto_CommentUpdateResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the resource"
+                            }
                         }
                     },
                     "400": {
@@ -559,6 +665,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response-any"
                         }
                     },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -566,8 +684,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/comments/{commentID}/revisions": {
+            "get": {
                 "security": [
                     {
                         "BearerToken": []
@@ -577,14 +697,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Photo"
+                    "Comment"
                 ],
-                "summary": "delete a photo",
+                "summary": "get the edit history of a comment",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "photo id",
-                        "name": "photoID",
+                        "description": "comment ID",
+                        "name": "commentID",
                         "in": "path",
                         "required": true
                     }
@@ -593,7 +713,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-any"
+                            "$ref": "#/definitions/response.Response-array_dto_CommentRevisionResponse"
                         }
                     },
                     "400": {
@@ -623,34 +743,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/photos/{photoID}/comments": {
+        "/likes/my": {
             "get": {
                 "security": [
                     {
                         "BearerToken": []
                     }
                 ],
+                "description": "Get list of photo that user liked",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Comment"
-                ],
-                "summary": "get all comments by photo ID",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "photo ID",
-                        "name": "photoID",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Like"
                 ],
+                "summary": "Get list of photo that user liked",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-array_dto_CommentGetByPhotoIDResponse"
+                            "$ref": "#/definitions/response.Response-array_dto_PhotoResponse"
                         }
                     },
                     "400": {
@@ -665,8 +777,41 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response-any"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/photos": {
+            "get": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Photo"
+                ],
+                "summary": "get all photos",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-array_dto_PhotoResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/response.Response-any"
                         }
@@ -692,9 +837,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Comment"
+                    "Photo"
                 ],
-                "summary": "create a new comment",
+                "summary": "create a new photo",
                 "parameters": [
                     {
                         "description": "required body",
@@ -702,22 +847,15 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.CommentCreate"
+                            "$ref": "#/definitions/dto.PhotoCreate"
                         }
-                    },
-                    {
-                        "type": "integer",
-                        "description": "photo ID",
-                        "name": "photoID",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-dto_CommentCreateResponse"
+                            "$ref": "#/definitions/response.Response-dto_PhotoCreateResponse"
                         }
                     },
                     "400": {
@@ -741,181 +879,211 @@ const docTemplate = `{
                 }
             }
         },
-        "/photos/{photoID}/likes": {
+        "/photos/my": {
             "get": {
                 "security": [
                     {
                         "BearerToken": []
                     }
                 ],
-                "description": "Get likes by photo ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Like"
-                ],
-                "summary": "Get likes by photo ID",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Photo ID",
-                        "name": "photoID",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Photo"
                 ],
+                "summary": "get current user's photos",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.LikeResponse"
-                            }
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/helper.ResponseError"
+                            "$ref": "#/definitions/response.Response-array_dto_PhotoResponse"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/helper.ResponseError"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/helper.ResponseError"
+                            "$ref": "#/definitions/response.Response-any"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/helper.ResponseError"
+                            "$ref": "#/definitions/response.Response-any"
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/photos/{photoID}": {
+            "get": {
                 "security": [
                     {
                         "BearerToken": []
                     }
                 ],
-                "description": "Create a like",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Like"
+                    "Photo"
                 ],
-                "summary": "Create a like",
+                "summary": "get a photo by id",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Photo ID",
+                        "description": "photo id",
                         "name": "photoID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.LikeCreateResponse"
+                            "$ref": "#/definitions/response.Response-dto_PhotoResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the resource"
+                            }
                         }
                     },
+                    "304": {
+                        "description": "not modified"
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/helper.ResponseError"
+                            "$ref": "#/definitions/response.Response-any"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/helper.ResponseError"
+                            "$ref": "#/definitions/response.Response-any"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/helper.ResponseError"
+                            "$ref": "#/definitions/response.Response-any"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/helper.ResponseError"
+                            "$ref": "#/definitions/response.Response-any"
                         }
                     }
                 }
             },
-            "delete": {
+            "put": {
                 "security": [
                     {
                         "BearerToken": []
                     }
                 ],
-                "description": "Delete a like",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Like"
+                    "Photo"
                 ],
-                "summary": "Delete a like",
+                "summary": "update a photo",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Photo ID",
+                        "description": "photo id",
                         "name": "photoID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "required body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PhotoUpdate"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the resource",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "boolean"
+                            "$ref": "#/definitions/response.Response-dto_PhotoUpdateResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the resource"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/helper.ResponseError"
+                            "$ref": "#/definitions/response.Response-any"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/helper.ResponseError"
+                            "$ref": "#/definitions/response.Response-any"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/helper.ResponseError"
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/helper.ResponseError"
+                            "$ref": "#/definitions/response.Response-any"
                         }
                     }
                 }
-            }
-        },
-        "/socialmedias": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerToken": []
@@ -925,14 +1093,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Social Media"
+                    "Photo"
                 ],
-                "summary": "get all social media",
-                "responses": {
-                    "200": {
+                "summary": "delete a photo",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "photo id",
+                        "name": "photoID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the resource",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-array_dto_SocialMediaResponse"
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
                         }
                     },
                     "401": {
@@ -941,6 +1130,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response-any"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -949,38 +1156,59 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
+            "patch": {
                 "security": [
                     {
                         "BearerToken": []
                     }
                 ],
+                "description": "only the fields present in the body are updated, fields set to null are cleared when allowed (JSON Merge Patch, RFC 7396)",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/merge-patch+json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Social Media"
+                    "Photo"
                 ],
-                "summary": "create a new social media",
+                "summary": "partially update a photo",
                 "parameters": [
                     {
-                        "description": "required body",
+                        "type": "integer",
+                        "description": "photo id",
+                        "name": "photoID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "fields to update",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.SocialMediaCreate"
+                            "$ref": "#/definitions/dto.PhotoPatch"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the resource",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-dto_SocialMediaCreateResponse"
+                            "$ref": "#/definitions/response.Response-dto_PhotoUpdateResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the resource"
+                            }
                         }
                     },
                     "400": {
@@ -995,38 +1223,26 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response-any"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/response.Response-any"
                         }
-                    }
-                }
-            }
-        },
-        "/socialmedias/my": {
-            "get": {
-                "security": [
-                    {
-                        "BearerToken": []
-                    }
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Social Media"
-                ],
-                "summary": "get my social media",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-dto_SocialMediaGetByUserIDResponse"
+                            "$ref": "#/definitions/response.Response-any"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
                         "schema": {
                             "$ref": "#/definitions/response.Response-any"
                         }
@@ -1040,7 +1256,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/socialmedias/{socialMediaID}": {
+        "/photos/{photoID}/comments": {
             "get": {
                 "security": [
                     {
@@ -1051,14 +1267,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Social Media"
+                    "Comment"
                 ],
-                "summary": "get social media by ID",
+                "summary": "get all comments by photo ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "social media ID",
-                        "name": "socialMediaID",
+                        "description": "photo ID",
+                        "name": "photoID",
                         "in": "path",
                         "required": true
                     }
@@ -1067,7 +1283,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-dto_SocialMediaResponse"
+                            "$ref": "#/definitions/response.Response-array_dto_CommentGetByPhotoIDResponse"
                         }
                     },
                     "400": {
@@ -1096,7 +1312,7 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
+            "post": {
                 "security": [
                     {
                         "BearerToken": []
@@ -1109,32 +1325,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Social Media"
+                    "Comment"
                 ],
-                "summary": "update social media",
+                "summary": "create a new comment",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "social media ID",
-                        "name": "socialMediaID",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "description": "required body",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.SocialMediaUpdate"
+                            "$ref": "#/definitions/dto.CommentCreate"
                         }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "photo ID",
+                        "name": "photoID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-dto_SocialMediaUpdateResponse"
+                            "$ref": "#/definitions/response.Response-dto_CommentCreateResponse"
                         }
                     },
                     "400": {
@@ -1149,18 +1365,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response-any"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/response.Response-any"
-                        }
-                    },
-                    "409": {
-                        "description": "Conflict",
-                        "schema": {
-                            "$ref": "#/definitions/response.Response-any"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1168,25 +1372,28 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/photos/{photoID}/likes": {
+            "get": {
                 "security": [
                     {
                         "BearerToken": []
                     }
                 ],
+                "description": "Get likes by photo ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Social Media"
+                    "Like"
                 ],
-                "summary": "delete social media",
+                "summary": "Get likes by photo ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "social media ID",
-                        "name": "socialMediaID",
+                        "description": "Photo ID",
+                        "name": "photoID",
                         "in": "path",
                         "required": true
                     }
@@ -1195,93 +1402,90 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-any"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.LikeResponse"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-any"
+                            "$ref": "#/definitions/helper.ResponseError"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-any"
+                            "$ref": "#/definitions/helper.ResponseError"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-any"
+                            "$ref": "#/definitions/helper.ResponseError"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-any"
+                            "$ref": "#/definitions/helper.ResponseError"
                         }
                     }
                 }
-            }
-        },
-        "/users": {
-            "put": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Create a like",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Like"
                 ],
-                "summary": "update user",
+                "summary": "Create a like",
                 "parameters": [
                     {
-                        "description": "required body",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.UserUpdate"
-                        }
+                        "type": "integer",
+                        "description": "Photo ID",
+                        "name": "photoID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-dto_UserUpdateResponse"
+                            "$ref": "#/definitions/dto.LikeCreateResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-any"
+                            "$ref": "#/definitions/helper.ResponseError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-any"
+                            "$ref": "#/definitions/helper.ResponseError"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-any"
+                            "$ref": "#/definitions/helper.ResponseError"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-any"
+                            "$ref": "#/definitions/helper.ResponseError"
                         }
                     }
                 }
@@ -1292,30 +1496,1273 @@ const docTemplate = `{
                         "BearerToken": []
                     }
                 ],
+                "description": "Delete a like",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Like"
+                ],
+                "summary": "Delete a like",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Photo ID",
+                        "name": "photoID",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "delete user",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-any"
+                            "type": "boolean"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/helper.ResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/helper.ResponseError"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-any"
+                            "$ref": "#/definitions/helper.ResponseError"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-any"
+                            "$ref": "#/definitions/helper.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/photos/{photoID}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Photo"
+                ],
+                "summary": "get the edit history of a photo",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "photo id",
+                        "name": "photoID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-array_dto_PhotoRevisionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/socialmedias": {
+            "get": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Social Media"
+                ],
+                "summary": "get all social media",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-array_dto_SocialMediaResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Social Media"
+                ],
+                "summary": "create a new social media",
+                "parameters": [
+                    {
+                        "description": "required body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SocialMediaCreate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-dto_SocialMediaCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/socialmedias/my": {
+            "get": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Social Media"
+                ],
+                "summary": "get my social media",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-dto_SocialMediaGetByUserIDResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/socialmedias/{socialMediaID}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Social Media"
+                ],
+                "summary": "get social media by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "social media ID",
+                        "name": "socialMediaID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-dto_SocialMediaResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the resource"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Social Media"
+                ],
+                "summary": "update social media",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "social media ID",
+                        "name": "socialMediaID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "required body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SocialMediaUpdate"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the resource",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-dto_SocialMediaUpdateResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the resource"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Social Media"
+                ],
+                "summary": "delete social media",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "social media ID",
+                        "name": "socialMediaID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the resource",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "only the fields present in the body are updated, fields set to null are cleared when allowed (JSON Merge Patch, RFC 7396)",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Social Media"
+                ],
+                "summary": "partially update a social media",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "social media ID",
+                        "name": "socialMediaID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "fields to update",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SocialMediaPatch"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the resource",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-dto_SocialMediaUpdateResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the resource"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "the ETag is what PUT, PATCH and DELETE /users expect in If-Match",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "get the current user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-dto_UserResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the resource"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "not modified"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "update user",
+                "parameters": [
+                    {
+                        "description": "required body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserUpdate"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the resource",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-dto_UserUpdateResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the resource"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "the account is removed once delete_after has passed, logging in before then cancels the deletion",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "schedule the current user for deletion",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of the resource",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-dto_UserDeleteResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "only the fields present in the body are updated, fields set to null are cleared when allowed (JSON Merge Patch, RFC 7396)",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "partially update the current user",
+                "parameters": [
+                    {
+                        "description": "fields to update",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserPatch"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the resource",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-dto_UserUpdateResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the resource"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/2fa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "disable two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "TOTP code or recovery code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TOTPCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/2fa/recovery-codes": {
+            "post": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "the previous codes stop working, the new ones are only shown once",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "replace the two-factor recovery codes",
+                "parameters": [
+                    {
+                        "description": "TOTP code or recovery code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TOTPCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-dto_RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/2fa/setup": {
+            "post": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "returns a new secret, it's only enabled once a code from it is sent to /users/2fa/verify",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "start two-factor authentication enrollment",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-dto_TOTPSetupResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/2fa/verify": {
+            "post": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "the recovery codes are only shown once",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "enable two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "code from the authenticator app",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TOTPCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-dto_RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/export": {
+            "post": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "the archive is built in the background, poll the status endpoint until it's ready",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "request an export of the current user's data",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-dto_ExportResponse"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "status endpoint of the export"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/export/{exportID}/download": {
+            "get": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "download a finished export",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "export ID",
+                        "name": "exportID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "zip archive with JSON and CSV files",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/export/{exportID}/status": {
+            "get": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "get export status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "export ID",
+                        "name": "exportID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-dto_ExportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
                         }
                     }
                 }
@@ -1332,7 +2779,368 @@ const docTemplate = `{
                 "tags": [
                     "User"
                 ],
-                "summary": "login user",
+                "summary": "login user",
+                "parameters": [
+                    {
+                        "description": "required body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserLogin"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-dto_UserLoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login/2fa": {
+            "post": {
+                "description": "second step of the login for users with two-factor authentication, code is either a TOTP code or an unused recovery code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "finish a login with a two-factor code",
+                "parameters": [
+                    {
+                        "description": "required body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserLoginTOTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-dto_UserLoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/oidc/link": {
+            "post": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "the link_token comes from /users/oidc/{provider}/callback when the identity matched the user's email but it wasn't verified on both sides",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "link an OpenID Connect identity to the logged in user",
+                "parameters": [
+                    {
+                        "description": "required body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.OIDCLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/oidc/register": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "sign up with an OpenID Connect identity",
+                "parameters": [
+                    {
+                        "description": "required body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.OIDCRegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-dto_UserLoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/oidc/{provider}/callback": {
+            "get": {
+                "description": "logs in the user the identity is linked to, or the user with the same email when both sides have verified it. When only the email matches link_required is set, and the link_token has to be sent to /users/oidc/link after logging in with the password. Otherwise signup_required is set and the signup_token has to be sent to /users/oidc/register",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "finish logging in with an OpenID Connect provider",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "state",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-dto_UserLoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/oidc/{provider}/login": {
+            "get": {
+                "description": "redirects to the provider, which sends the user back to the callback endpoint",
+                "tags": [
+                    "User"
+                ],
+                "summary": "log in with an OpenID Connect provider",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Found",
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "authorization endpoint of the provider"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/register": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "register a new user",
                 "parameters": [
                     {
                         "description": "required body",
@@ -1340,15 +3148,90 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UserLogin"
+                            "$ref": "#/definitions/dto.UserRegister"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-dto_UserCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
                         }
                     }
+                }
+            }
+        },
+        "/users/security-events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "logins, failed logins, lockouts and changes to the account, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "list security events of the current user's account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "only events with this action",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only events at or after this RFC 3339 time",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only events before this RFC 3339 time",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only events older than this id, for paging",
+                        "name": "before_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "number of events, 1 to 200, defaults to 50",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-dto_UserLoginResponse"
+                            "$ref": "#/definitions/response.Response-array_dto_AuditEventResponse"
                         }
                     },
                     "400": {
@@ -1372,8 +3255,148 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/register": {
+        "/users/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "list the devices the current user is logged in on",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-array_dto_SessionResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/sessions/{sessionID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "revokes the session, tokens issued for it are rejected from then on",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "log out a device",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "session ID",
+                        "name": "sessionID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/tokens": {
+            "get": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API Token"
+                ],
+                "summary": "list the current user's API tokens",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-array_dto_APITokenResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            },
             "post": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "the token is only returned once, send it as a Bearer token. API tokens can't be used to manage API tokens",
                 "consumes": [
                     "application/json"
                 ],
@@ -1381,25 +3404,82 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "API Token"
+                ],
+                "summary": "create an API token for scripts and bots",
+                "parameters": [
+                    {
+                        "description": "required body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.APITokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-dto_APITokenCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/tokens/{tokenID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API Token"
                 ],
-                "summary": "register a new user",
+                "summary": "revoke an API token",
                 "parameters": [
                     {
-                        "description": "required body",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.UserRegister"
-                        }
+                        "type": "integer",
+                        "description": "API token ID",
+                        "name": "tokenID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response-dto_UserCreateResponse"
+                            "$ref": "#/definitions/response.Response-any"
                         }
                     },
                     "400": {
@@ -1408,8 +3488,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response-any"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response-any"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/response.Response-any"
                         }
@@ -1482,6 +3574,128 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "dto.APITokenCreateResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.APITokenRequest": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "type": "string",
+                    "example": "720h"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "upload bot"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "photos:read",
+                        "photos:write"
+                    ]
+                }
+            }
+        },
+        "dto.APITokenResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.AuditEventResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "target_id": {
+                    "type": "string"
+                },
+                "target_type": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "dto.CommentCreate": {
             "type": "object",
             "properties": {
@@ -1517,6 +3731,12 @@ const docTemplate = `{
                 "created_at": {
                     "type": "string"
                 },
+                "edited": {
+                    "type": "boolean"
+                },
+                "edited_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -1543,6 +3763,12 @@ const docTemplate = `{
                 "created_at": {
                     "type": "string"
                 },
+                "edited": {
+                    "type": "boolean"
+                },
+                "edited_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -1563,12 +3789,27 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.CommentPatch": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "buset ganteng pol nih fotonya"
+                }
+            }
+        },
         "dto.CommentResponse": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
+                "edited": {
+                    "type": "boolean"
+                },
+                "edited_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -1592,6 +3833,26 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.CommentRevisionResponse": {
+            "type": "object",
+            "properties": {
+                "comment_id": {
+                    "type": "integer"
+                },
+                "edited_at": {
+                    "type": "string"
+                },
+                "edited_by": {
+                    "$ref": "#/definitions/dto.User"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
         "dto.CommentUpdate": {
             "type": "object",
             "properties": {
@@ -1604,6 +3865,12 @@ const docTemplate = `{
         "dto.CommentUpdateResponse": {
             "type": "object",
             "properties": {
+                "edited": {
+                    "type": "boolean"
+                },
+                "edited_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -1621,6 +3888,26 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.ExportResponse": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
         "dto.LikeCreateResponse": {
             "type": "object",
             "properties": {
@@ -1658,12 +3945,59 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.LogLevelRequest": {
+            "type": "object",
+            "properties": {
+                "level": {
+                    "type": "string",
+                    "example": "debug"
+                }
+            }
+        },
+        "dto.LogLevelResponse": {
+            "type": "object",
+            "properties": {
+                "level": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.OIDCLinkRequest": {
+            "type": "object",
+            "properties": {
+                "link_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.OIDCRegisterRequest": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "type": "integer",
+                    "example": 25
+                },
+                "signup_token": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "budiganteng"
+                }
+            }
+        },
         "dto.Photo": {
             "type": "object",
             "properties": {
                 "caption": {
                     "type": "string"
                 },
+                "edited": {
+                    "type": "boolean"
+                },
+                "edited_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -1683,19 +4017,59 @@ const docTemplate = `{
             "properties": {
                 "caption": {
                     "type": "string",
-                    "example": "Ini adalah foto Budi yang sangat ganteng"
+                    "example": "Ini adalah foto Budi yang sangat ganteng"
+                },
+                "photo_url": {
+                    "type": "string",
+                    "example": "https://www.budiganteng.com/gambarnya-budi-ganteng.jpg"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Gambarnya Budi Ganteng"
+                }
+            }
+        },
+        "dto.PhotoCreateResponse": {
+            "type": "object",
+            "properties": {
+                "caption": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "photo_url": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.PhotoPatch": {
+            "type": "object",
+            "properties": {
+                "caption": {
+                    "type": "string",
+                    "example": "Ini adalah foto Budi yang ganteng pol"
                 },
                 "photo_url": {
                     "type": "string",
-                    "example": "https://www.budiganteng.com/gambarnya-budi-ganteng.jpg"
+                    "example": "https://www.budiganteng.com/pol.jpg"
                 },
                 "title": {
                     "type": "string",
-                    "example": "Gambarnya Budi Ganteng"
+                    "example": "Gambarnya Budi Ganteng Pol"
                 }
             }
         },
-        "dto.PhotoCreateResponse": {
+        "dto.PhotoResponse": {
             "type": "object",
             "properties": {
                 "caption": {
@@ -1704,6 +4078,12 @@ const docTemplate = `{
                 "created_at": {
                     "type": "string"
                 },
+                "edited": {
+                    "type": "boolean"
+                },
+                "edited_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -1713,37 +4093,40 @@ const docTemplate = `{
                 "title": {
                     "type": "string"
                 },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/dto.User"
+                },
                 "user_id": {
                     "type": "integer"
                 }
             }
         },
-        "dto.PhotoResponse": {
+        "dto.PhotoRevisionResponse": {
             "type": "object",
             "properties": {
                 "caption": {
                     "type": "string"
                 },
-                "created_at": {
+                "edited_at": {
                     "type": "string"
                 },
+                "edited_by": {
+                    "$ref": "#/definitions/dto.User"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "photo_id": {
+                    "type": "integer"
+                },
                 "photo_url": {
                     "type": "string"
                 },
                 "title": {
                     "type": "string"
-                },
-                "updated_at": {
-                    "type": "string"
-                },
-                "user": {
-                    "$ref": "#/definitions/dto.User"
-                },
-                "user_id": {
-                    "type": "integer"
                 }
             }
         },
@@ -1770,6 +4153,12 @@ const docTemplate = `{
                 "caption": {
                     "type": "string"
                 },
+                "edited": {
+                    "type": "boolean"
+                },
+                "edited_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -1787,6 +4176,43 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.SessionResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
         "dto.SocialMediaCreate": {
             "type": "object",
             "properties": {
@@ -1843,6 +4269,19 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.SocialMediaPatch": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Bluesky"
+                },
+                "social_media_url": {
+                    "type": "string",
+                    "example": "https://bsky.app/profile/budiganteng"
+                }
+            }
+        },
         "dto.SocialMediaResponse": {
             "type": "object",
             "properties": {
@@ -1902,6 +4341,26 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.TOTPCodeRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "dto.TOTPSetupResponse": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
         "dto.User": {
             "type": "object",
             "properties": {
@@ -1933,6 +4392,14 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.UserDeleteResponse": {
+            "type": "object",
+            "properties": {
+                "delete_after": {
+                    "type": "string"
+                }
+            }
+        },
         "dto.UserLogin": {
             "type": "object",
             "properties": {
@@ -1949,8 +4416,57 @@ const docTemplate = `{
         "dto.UserLoginResponse": {
             "type": "object",
             "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "deletion_cancelled": {
+                    "type": "boolean"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "link_required": {
+                    "type": "boolean"
+                },
+                "link_token": {
+                    "type": "string"
+                },
+                "signup_required": {
+                    "type": "boolean"
+                },
+                "signup_token": {
+                    "type": "string"
+                },
                 "token": {
                     "type": "string"
+                },
+                "two_factor_required": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.UserLoginTOTPRequest": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "dto.UserPatch": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "budigantengpol@rocketmail.com"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "budigantengpol"
                 }
             }
         },
@@ -1969,52 +4485,208 @@ const docTemplate = `{
                     "type": "string",
                     "example": "budiganteng123"
                 },
-                "username": {
-                    "type": "string",
-                    "example": "budiganteng"
+                "username": {
+                    "type": "string",
+                    "example": "budiganteng"
+                }
+            }
+        },
+        "dto.UserResponse": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_verified": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.UserUpdate": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "budigantengbanget@rocketmail.com"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "budigantengbanget"
+                }
+            }
+        },
+        "dto.UserUpdateResponse": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "type": "integer"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "helper.ResponseError": {
+            "type": "object"
+        },
+        "response.Response-any": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "response.Response-array_dto_APITokenResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.APITokenResponse"
+                    }
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "response.Response-array_dto_AuditEventResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AuditEventResponse"
+                    }
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
                 }
             }
         },
-        "dto.UserUpdate": {
+        "response.Response-array_dto_CommentGetByPhotoIDResponse": {
             "type": "object",
             "properties": {
-                "email": {
-                    "type": "string",
-                    "example": "budigantengbanget@rocketmail.com"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CommentGetByPhotoIDResponse"
+                    }
                 },
-                "username": {
-                    "type": "string",
-                    "example": "budigantengbanget"
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
                 }
             }
         },
-        "dto.UserUpdateResponse": {
+        "response.Response-array_dto_CommentGetByUserIDResponse": {
             "type": "object",
             "properties": {
-                "age": {
-                    "type": "integer"
-                },
-                "email": {
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CommentGetByUserIDResponse"
+                    }
                 },
-                "id": {
-                    "type": "integer"
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "updated_at": {
+                "message": {
                     "type": "string"
                 },
-                "username": {
+                "request_id": {
                     "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
                 }
             }
         },
-        "helper.ResponseError": {
-            "type": "object"
-        },
-        "response.Response-any": {
+        "response.Response-array_dto_CommentResponse": {
             "type": "object",
             "properties": {
-                "data": {},
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CommentResponse"
+                    }
+                },
                 "errors": {
                     "type": "array",
                     "items": {
@@ -2024,18 +4696,21 @@ const docTemplate = `{
                 "message": {
                     "type": "string"
                 },
+                "request_id": {
+                    "type": "string"
+                },
                 "success": {
                     "type": "boolean"
                 }
             }
         },
-        "response.Response-array_dto_CommentGetByPhotoIDResponse": {
+        "response.Response-array_dto_CommentRevisionResponse": {
             "type": "object",
             "properties": {
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dto.CommentGetByPhotoIDResponse"
+                        "$ref": "#/definitions/dto.CommentRevisionResponse"
                     }
                 },
                 "errors": {
@@ -2047,18 +4722,21 @@ const docTemplate = `{
                 "message": {
                     "type": "string"
                 },
+                "request_id": {
+                    "type": "string"
+                },
                 "success": {
                     "type": "boolean"
                 }
             }
         },
-        "response.Response-array_dto_CommentGetByUserIDResponse": {
+        "response.Response-array_dto_PhotoResponse": {
             "type": "object",
             "properties": {
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dto.CommentGetByUserIDResponse"
+                        "$ref": "#/definitions/dto.PhotoResponse"
                     }
                 },
                 "errors": {
@@ -2070,18 +4748,21 @@ const docTemplate = `{
                 "message": {
                     "type": "string"
                 },
+                "request_id": {
+                    "type": "string"
+                },
                 "success": {
                     "type": "boolean"
                 }
             }
         },
-        "response.Response-array_dto_CommentResponse": {
+        "response.Response-array_dto_PhotoRevisionResponse": {
             "type": "object",
             "properties": {
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dto.CommentResponse"
+                        "$ref": "#/definitions/dto.PhotoRevisionResponse"
                     }
                 },
                 "errors": {
@@ -2093,18 +4774,21 @@ const docTemplate = `{
                 "message": {
                     "type": "string"
                 },
+                "request_id": {
+                    "type": "string"
+                },
                 "success": {
                     "type": "boolean"
                 }
             }
         },
-        "response.Response-array_dto_PhotoResponse": {
+        "response.Response-array_dto_SessionResponse": {
             "type": "object",
             "properties": {
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dto.PhotoResponse"
+                        "$ref": "#/definitions/dto.SessionResponse"
                     }
                 },
                 "errors": {
@@ -2116,6 +4800,9 @@ const docTemplate = `{
                 "message": {
                     "type": "string"
                 },
+                "request_id": {
+                    "type": "string"
+                },
                 "success": {
                     "type": "boolean"
                 }
@@ -2139,6 +4826,32 @@ const docTemplate = `{
                 "message": {
                     "type": "string"
                 },
+                "request_id": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "response.Response-dto_APITokenCreateResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/dto.APITokenCreateResponse"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
                 "success": {
                     "type": "boolean"
                 }
@@ -2159,6 +4872,9 @@ const docTemplate = `{
                 "message": {
                     "type": "string"
                 },
+                "request_id": {
+                    "type": "string"
+                },
                 "success": {
                     "type": "boolean"
                 }
@@ -2179,6 +4895,9 @@ const docTemplate = `{
                 "message": {
                     "type": "string"
                 },
+                "request_id": {
+                    "type": "string"
+                },
                 "success": {
                     "type": "boolean"
                 }
@@ -2199,6 +4918,55 @@ const docTemplate = `{
                 "message": {
                     "type": "string"
                 },
+                "request_id": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "response.Response-dto_ExportResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/dto.ExportResponse"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "response.Response-dto_LogLevelResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/dto.LogLevelResponse"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
                 "success": {
                     "type": "boolean"
                 }
@@ -2219,6 +4987,9 @@ const docTemplate = `{
                 "message": {
                     "type": "string"
                 },
+                "request_id": {
+                    "type": "string"
+                },
                 "success": {
                     "type": "boolean"
                 }
@@ -2239,6 +5010,9 @@ const docTemplate = `{
                 "message": {
                     "type": "string"
                 },
+                "request_id": {
+                    "type": "string"
+                },
                 "success": {
                     "type": "boolean"
                 }
@@ -2259,6 +5033,32 @@ const docTemplate = `{
                 "message": {
                     "type": "string"
                 },
+                "request_id": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "response.Response-dto_RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/dto.RecoveryCodesResponse"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
                 "success": {
                     "type": "boolean"
                 }
@@ -2279,6 +5079,9 @@ const docTemplate = `{
                 "message": {
                     "type": "string"
                 },
+                "request_id": {
+                    "type": "string"
+                },
                 "success": {
                     "type": "boolean"
                 }
@@ -2299,6 +5102,9 @@ const docTemplate = `{
                 "message": {
                     "type": "string"
                 },
+                "request_id": {
+                    "type": "string"
+                },
                 "success": {
                     "type": "boolean"
                 }
@@ -2319,6 +5125,9 @@ const docTemplate = `{
                 "message": {
                     "type": "string"
                 },
+                "request_id": {
+                    "type": "string"
+                },
                 "success": {
                     "type": "boolean"
                 }
@@ -2339,6 +5148,32 @@ const docTemplate = `{
                 "message": {
                     "type": "string"
                 },
+                "request_id": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "response.Response-dto_TOTPSetupResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/dto.TOTPSetupResponse"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
                 "success": {
                     "type": "boolean"
                 }
@@ -2359,6 +5194,32 @@ const docTemplate = `{
                 "message": {
                     "type": "string"
                 },
+                "request_id": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "response.Response-dto_UserDeleteResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/dto.UserDeleteResponse"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
                 "success": {
                     "type": "boolean"
                 }
@@ -2379,6 +5240,32 @@ const docTemplate = `{
                 "message": {
                     "type": "string"
                 },
+                "request_id": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "response.Response-dto_UserResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/dto.UserResponse"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
                 "success": {
                     "type": "boolean"
                 }
@@ -2399,6 +5286,9 @@ const docTemplate = `{
                 "message": {
                     "type": "string"
                 },
+                "request_id": {
+                    "type": "string"
+                },
                 "success": {
                     "type": "boolean"
                 }
@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"errors"
+	"final-project/helper"
+	"fmt"
+	"slices"
+	"time"
+)
+
+type APITokenRequest struct {
+	Name      string   `json:"name" example:"upload bot"`
+	Scopes    []string `json:"scopes" example:"photos:read,photos:write"`
+	ExpiresIn string   `json:"expires_in,omitempty" example:"720h"`
+}
+
+func (a APITokenRequest) Validate() error {
+	var errs error
+
+	if a.Name == "" {
+		errs = errors.Join(errs, helper.ErrEmptyTokenName)
+	} else if len(a.Name) > 100 {
+		errs = errors.Join(errs, helper.ErrTokenNameTooLong)
+	}
+
+	if len(a.Scopes) == 0 {
+		errs = errors.Join(errs, helper.ErrEmptyScopes)
+	}
+
+	for i, scope := range a.Scopes {
+		if !helper.IsValidScope(scope) || slices.Index(a.Scopes, scope) != i {
+			errs = errors.Join(errs, fmt.Errorf("%w: %q", helper.ErrInvalidScope, scope))
+		}
+	}
+
+	if a.ExpiresIn != "" {
+		if d, err := time.ParseDuration(a.ExpiresIn); err != nil || d <= 0 {
+			errs = errors.Join(errs, helper.ErrInvalidExpiresIn)
+		}
+	}
+
+	return errs
+}
+
+type APITokenResponse struct {
+	ID         uint64     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// APITokenCreateResponse is the only time the token itself is returned,
+// only its hash is stored.
+type APITokenCreateResponse struct {
+	APITokenResponse
+	Token string `json:"token"`
+}
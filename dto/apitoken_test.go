@@ -0,0 +1,32 @@
+package dto_test
+
+import (
+	"errors"
+	"final-project/dto"
+	"final-project/helper"
+	"testing"
+)
+
+func TestAPITokenRequestValidate(t *testing.T) {
+	testcases := []struct {
+		name string
+		in   dto.APITokenRequest
+		want error
+	}{
+		{"valid", dto.APITokenRequest{Name: "bot", Scopes: []string{"photos:read", "photos:write"}, ExpiresIn: "720h"}, nil},
+		{"no name", dto.APITokenRequest{Scopes: []string{"photos:read"}}, helper.ErrEmptyTokenName},
+		{"no scopes", dto.APITokenRequest{Name: "bot"}, helper.ErrEmptyScopes},
+		{"unknown scope", dto.APITokenRequest{Name: "bot", Scopes: []string{"photos:delete"}}, helper.ErrInvalidScope},
+		{"duplicate scope", dto.APITokenRequest{Name: "bot", Scopes: []string{"photos:read", "photos:read"}}, helper.ErrInvalidScope},
+		{"negative expiry", dto.APITokenRequest{Name: "bot", Scopes: []string{"photos:read"}, ExpiresIn: "-1h"}, helper.ErrInvalidExpiresIn},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.in.Validate()
+			if (tt.want == nil) != (err == nil) || (tt.want != nil && !errors.Is(err, tt.want)) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package dto
+
+import (
+	"errors"
+	"final-project/helper"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	auditDefaultLimit = 50
+	auditMaxLimit     = 200
+)
+
+// AuditEventQuery holds the filters of an audit log search, read from the
+// query string. Times are RFC 3339, older pages are fetched by passing the
+// smallest id seen so far as before_id.
+type AuditEventQuery struct {
+	ActorID    uint64
+	UserID     uint64
+	Action     string
+	TargetType string
+	TargetID   string
+	IP         string
+	Since      time.Time
+	Until      time.Time
+	BeforeID   uint64
+	Limit      uint64
+}
+
+func ParseAuditEventQuery(q url.Values) (AuditEventQuery, error) {
+	var (
+		query = AuditEventQuery{
+			Action:     q.Get("action"),
+			TargetType: q.Get("target_type"),
+			TargetID:   q.Get("target_id"),
+			IP:         q.Get("ip"),
+			Limit:      auditDefaultLimit,
+		}
+		errs error
+	)
+
+	ids := []struct {
+		name string
+		dst  *uint64
+	}{
+		{"actor_id", &query.ActorID},
+		{"user_id", &query.UserID},
+		{"before_id", &query.BeforeID},
+	}
+	for _, id := range ids {
+		if v := q.Get(id.name); v != "" {
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil || n == 0 {
+				errs = errors.Join(errs, fmt.Errorf("%w: %s", helper.ErrInvalidID, id.name))
+				continue
+			}
+			*id.dst = n
+		}
+	}
+
+	times := []struct {
+		name string
+		dst  *time.Time
+	}{
+		{"since", &query.Since},
+		{"until", &query.Until},
+	}
+	for _, t := range times {
+		if v := q.Get(t.name); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("%w: %s", helper.ErrInvalidTime, t.name))
+				continue
+			}
+			*t.dst = parsed
+		}
+	}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || n == 0 || n > auditMaxLimit {
+			errs = errors.Join(errs, helper.ErrInvalidLimit)
+		} else {
+			query.Limit = n
+		}
+	}
+
+	return query, errs
+}
+
+type AuditEventResponse struct {
+	ID         uint64            `json:"id"`
+	ActorID    *uint64           `json:"actor_id"`
+	UserID     *uint64           `json:"user_id"`
+	Action     string            `json:"action"`
+	TargetType string            `json:"target_type,omitempty"`
+	TargetID   string            `json:"target_id,omitempty"`
+	IP         string            `json:"ip"`
+	UserAgent  string            `json:"user_agent"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
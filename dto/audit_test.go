@@ -0,0 +1,55 @@
+package dto_test
+
+import (
+	"errors"
+	"final-project/dto"
+	"final-project/helper"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseAuditEventQuery(t *testing.T) {
+	testcases := []struct {
+		name string
+		in   string
+		want error
+	}{
+		{"empty", "", nil},
+		{"all filters", "actor_id=1&user_id=2&action=login&target_type=session&target_id=3&ip=127.0.0.1&since=2024-01-01T00:00:00Z&until=2024-02-01T00:00:00Z&before_id=10&limit=200", nil},
+		{"invalid user id", "user_id=abc", helper.ErrInvalidID},
+		{"zero before id", "before_id=0", helper.ErrInvalidID},
+		{"invalid time", "since=yesterday", helper.ErrInvalidTime},
+		{"limit too big", "limit=201", helper.ErrInvalidLimit},
+		{"zero limit", "limit=0", helper.ErrInvalidLimit},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			q, _ := url.ParseQuery(tt.in)
+			_, err := dto.ParseAuditEventQuery(q)
+			if (tt.want == nil) != (err == nil) || (tt.want != nil && !errors.Is(err, tt.want)) {
+				t.Errorf("ParseAuditEventQuery() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAuditEventQueryValues(t *testing.T) {
+	q, _ := url.ParseQuery("user_id=2&since=2024-01-01T00:00:00Z")
+
+	query, err := dto.ParseAuditEventQuery(q)
+	if err != nil {
+		t.Fatalf("ParseAuditEventQuery() error = %v", err)
+	}
+
+	if query.UserID != 2 {
+		t.Errorf("UserID = %d, want 2", query.UserID)
+	}
+	if want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); !query.Since.Equal(want) {
+		t.Errorf("Since = %v, want %v", query.Since, want)
+	}
+	if query.Limit != 50 {
+		t.Errorf("Limit = %d, want the default of 50", query.Limit)
+	}
+}
@@ -30,14 +30,16 @@ type CommentCreateResponse struct {
 }
 
 type CommentResponse struct {
-	ID        uint64    `json:"id"`
-	Message   string    `json:"message"`
-	PhotoID   uint64    `json:"photo_id"`
-	UserID    uint64    `json:"user_id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdateAt  time.Time `json:"updated_at"`
-	User      User      `json:"user"`
-	Photo     Photo     `json:"photo"`
+	ID        uint64     `json:"id"`
+	Message   string     `json:"message"`
+	PhotoID   uint64     `json:"photo_id"`
+	UserID    uint64     `json:"user_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdateAt  time.Time  `json:"updated_at"`
+	Edited    bool       `json:"edited"`
+	EditedAt  *time.Time `json:"edited_at"`
+	User      User       `json:"user"`
+	Photo     Photo      `json:"photo"`
 }
 
 func (c CommentRequest) ValidateUpdate() error {
@@ -51,29 +53,64 @@ func (c CommentRequest) ValidateUpdate() error {
 }
 
 type CommentUpdateResponse struct {
-	ID        uint64    `json:"id"`
-	Message   string    `json:"message"`
-	PhotoID   uint64    `json:"photo_id"`
-	UserID    uint64    `json:"user_id"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uint64     `json:"id"`
+	Message   string     `json:"message"`
+	PhotoID   uint64     `json:"photo_id"`
+	UserID    uint64     `json:"user_id"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Edited    bool       `json:"edited"`
+	EditedAt  *time.Time `json:"edited_at"`
 }
 
 type CommentGetByPhotoIDResponse struct {
-	ID        uint64    `json:"id"`
-	Message   string    `json:"message"`
-	PhotoID   uint64    `json:"photo_id"`
-	UserID    uint64    `json:"user_id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdateAt  time.Time `json:"updated_at"`
-	User      User      `json:"user"`
+	ID        uint64     `json:"id"`
+	Message   string     `json:"message"`
+	PhotoID   uint64     `json:"photo_id"`
+	UserID    uint64     `json:"user_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdateAt  time.Time  `json:"updated_at"`
+	Edited    bool       `json:"edited"`
+	EditedAt  *time.Time `json:"edited_at"`
+	User      User       `json:"user"`
 }
 
 type CommentGetByUserIDResponse struct {
+	ID        uint64     `json:"id"`
+	Message   string     `json:"message"`
+	PhotoID   uint64     `json:"photo_id"`
+	UserID    uint64     `json:"user_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdateAt  time.Time  `json:"updated_at"`
+	Edited    bool       `json:"edited"`
+	EditedAt  *time.Time `json:"edited_at"`
+	Photo     Photo      `json:"photo"`
+}
+
+// CommentRevisionResponse holds the message a comment had before it was
+// edited by EditedBy at EditedAt.
+type CommentRevisionResponse struct {
 	ID        uint64    `json:"id"`
+	CommentID uint64    `json:"comment_id"`
 	Message   string    `json:"message"`
-	PhotoID   uint64    `json:"photo_id"`
-	UserID    uint64    `json:"user_id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdateAt  time.Time `json:"updated_at"`
-	Photo     Photo     `json:"photo"`
+	EditedAt  time.Time `json:"edited_at"`
+
+	EditedBy User `json:"edited_by"`
+}
+
+type CommentPatchRequest struct {
+	Message Optional[string] `json:"message"`
+}
+
+func (c CommentPatchRequest) ValidatePatch() error {
+	var errs error
+
+	if !c.Message.Set {
+		return helper.ErrEmptyPatch
+	}
+
+	if c.Message.Null || c.Message.Value == "" {
+		errs = errors.Join(errs, helper.ErrEmptyMessage)
+	}
+
+	return errs
 }
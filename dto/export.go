@@ -0,0 +1,55 @@
+package dto
+
+import "time"
+
+type ExportResponse struct {
+	ID          uint64     `json:"id"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+// The types below are the files inside an account export archive.
+
+type ExportProfile struct {
+	ID        uint64    `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Age       uint64    `json:"age"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ExportPhoto struct {
+	ID        uint64     `json:"id"`
+	Title     string     `json:"title"`
+	Caption   string     `json:"caption"`
+	URL       string     `json:"photo_url"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	EditedAt  *time.Time `json:"edited_at"`
+}
+
+type ExportComment struct {
+	ID        uint64     `json:"id"`
+	PhotoID   uint64     `json:"photo_id"`
+	Message   string     `json:"message"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	EditedAt  *time.Time `json:"edited_at"`
+}
+
+type ExportLike struct {
+	ID        uint64    `json:"id"`
+	PhotoID   uint64    `json:"photo_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExportSocialMedia struct {
+	ID        uint64    `json:"id"`
+	Name      string    `json:"name"`
+	URL       string    `json:"social_media_url"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,37 @@
+package dto
+
+const (
+	HealthStatusOK      = "ok"
+	HealthStatusFailing = "failing"
+)
+
+type HealthCheckResponse struct {
+	Status string `json:"status"`
+}
+
+type HealthResponse struct {
+	Status string                         `json:"status"`
+	Checks map[string]HealthCheckResponse `json:"checks,omitempty"`
+}
+
+// NewHealthResponse only reports whether each check passed, the errors
+// themselves are logged since the probes aren't authenticated.
+func NewHealthResponse(results map[string]error, ok bool) HealthResponse {
+	resp := HealthResponse{
+		Status: HealthStatusOK,
+		Checks: make(map[string]HealthCheckResponse, len(results)),
+	}
+	if !ok {
+		resp.Status = HealthStatusFailing
+	}
+
+	for name, err := range results {
+		status := HealthStatusOK
+		if err != nil {
+			status = HealthStatusFailing
+		}
+		resp.Checks[name] = HealthCheckResponse{Status: status}
+	}
+
+	return resp
+}
@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"final-project/helper"
+	"log/slog"
+)
+
+type LogLevelRequest struct {
+	Level string `json:"level" example:"debug"`
+}
+
+func (l LogLevelRequest) Validate() error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(l.Level)); err != nil {
+		return helper.ErrInvalidLogLevel
+	}
+
+	return nil
+}
+
+type LogLevelResponse struct {
+	Level string `json:"level"`
+}
@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"errors"
+	"final-project/helper"
+)
+
+type OIDCRegisterRequest struct {
+	SignupToken string `json:"signup_token"`
+	Username    string `json:"username" example:"budiganteng"`
+	Age         uint64 `json:"age" example:"25"`
+}
+
+func (o OIDCRegisterRequest) Validate() error {
+	var errs error
+
+	if o.SignupToken == "" {
+		errs = errors.Join(errs, helper.ErrEmptySignupToken)
+	}
+
+	if o.Username == "" {
+		errs = errors.Join(errs, helper.ErrEmptyUsername)
+	} else if len(o.Username) > 100 {
+		errs = errors.Join(errs, helper.ErrUsernameTooLong)
+	}
+
+	if o.Age < 8 {
+		errs = errors.Join(errs, helper.ErrAgeTooYoung)
+	}
+
+	return errs
+}
+
+type OIDCLinkRequest struct {
+	LinkToken string `json:"link_token"`
+}
+
+func (o OIDCLinkRequest) Validate() error {
+	if o.LinkToken == "" {
+		return helper.ErrEmptyLinkToken
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package dto
+
+import "encoding/json"
+
+// Optional is a field of a JSON Merge Patch (RFC 7396) document. Set is false
+// when the field is absent from the document, Null is true when it is
+// explicitly set to null.
+type Optional[T any] struct {
+	Value T
+	Set   bool
+	Null  bool
+}
+
+func (o *Optional[T]) UnmarshalJSON(b []byte) error {
+	o.Set = true
+	if string(b) == "null" {
+		o.Null = true
+		return nil
+	}
+	return json.Unmarshal(b, &o.Value)
+}
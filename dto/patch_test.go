@@ -0,0 +1,62 @@
+package dto_test
+
+import (
+	"encoding/json"
+	"final-project/dto"
+	"testing"
+)
+
+func TestOptionalUnmarshal(t *testing.T) {
+	testcases := []struct {
+		in    string
+		set   bool
+		null  bool
+		value string
+	}{
+		{`{}`, false, false, ""},
+		{`{"caption": null}`, true, true, ""},
+		{`{"caption": ""}`, true, false, ""},
+		{`{"caption": "budi"}`, true, false, "budi"},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.in, func(t *testing.T) {
+			var data dto.PhotoPatchRequest
+			if err := json.Unmarshal([]byte(tt.in), &data); err != nil {
+				t.Fatalf("json.Unmarshal(%s) error = %v", tt.in, err)
+			}
+			got := data.Caption
+			if got.Set != tt.set || got.Null != tt.null || got.Value != tt.value {
+				t.Errorf("json.Unmarshal(%s) caption = %+v, want {Value:%s Set:%t Null:%t}", tt.in, got, tt.value, tt.set, tt.null)
+			}
+		})
+	}
+}
+
+func TestPhotoPatchValidate(t *testing.T) {
+	testcases := []struct {
+		in    string
+		valid bool
+	}{
+		{`{}`, false},
+		{`{"caption": null}`, true},
+		{`{"title": null}`, false},
+		{`{"title": ""}`, false},
+		{`{"title": "budi"}`, true},
+		{`{"photo_url": null}`, false},
+		{`{"photo_url": "budi"}`, false},
+		{`{"photo_url": "https://budi.com/budi.jpg", "caption": "ganteng"}`, true},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.in, func(t *testing.T) {
+			var data dto.PhotoPatchRequest
+			if err := json.Unmarshal([]byte(tt.in), &data); err != nil {
+				t.Fatalf("json.Unmarshal(%s) error = %v", tt.in, err)
+			}
+			if err := data.ValidatePatch(); (err == nil) != tt.valid {
+				t.Errorf("ValidatePatch(%s) error = %v, want valid = %t", tt.in, err, tt.valid)
+			}
+		})
+	}
+}
@@ -40,13 +40,15 @@ type PhotoCreateResponse struct {
 }
 
 type PhotoResponse struct {
-	ID        uint64    `json:"id"`
-	Title     string    `json:"title"`
-	Caption   string    `json:"caption"`
-	URL       string    `json:"photo_url"`
-	UserID    uint64    `json:"user_id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uint64     `json:"id"`
+	Title     string     `json:"title"`
+	Caption   string     `json:"caption"`
+	URL       string     `json:"photo_url"`
+	UserID    uint64     `json:"user_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Edited    bool       `json:"edited"`
+	EditedAt  *time.Time `json:"edited_at"`
 
 	User User `json:"user"`
 }
@@ -70,18 +72,67 @@ func (p PhotoRequest) ValidateUpdate() error {
 }
 
 type PhotoUpdateResponse struct {
-	ID        uint64    `json:"id"`
-	Title     string    `json:"title"`
-	Caption   string    `json:"caption"`
-	URL       string    `json:"photo_url"`
-	UserID    uint64    `json:"user_id"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uint64     `json:"id"`
+	Title     string     `json:"title"`
+	Caption   string     `json:"caption"`
+	URL       string     `json:"photo_url"`
+	UserID    uint64     `json:"user_id"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Edited    bool       `json:"edited"`
+	EditedAt  *time.Time `json:"edited_at"`
 }
 
 type Photo struct {
-	ID      uint64 `json:"id"`
-	Title   string `json:"title"`
-	Caption string `json:"caption"`
-	URL     string `json:"photo_url"`
-	UserID  uint64 `json:"user_id"`
+	ID       uint64     `json:"id"`
+	Title    string     `json:"title"`
+	Caption  string     `json:"caption"`
+	URL      string     `json:"photo_url"`
+	UserID   uint64     `json:"user_id"`
+	Edited   bool       `json:"edited"`
+	EditedAt *time.Time `json:"edited_at"`
+}
+
+// PhotoRevisionResponse holds the content a photo had before it was edited
+// by EditedBy at EditedAt.
+type PhotoRevisionResponse struct {
+	ID       uint64    `json:"id"`
+	PhotoID  uint64    `json:"photo_id"`
+	Title    string    `json:"title"`
+	Caption  string    `json:"caption"`
+	URL      string    `json:"photo_url"`
+	EditedAt time.Time `json:"edited_at"`
+
+	EditedBy User `json:"edited_by"`
+}
+
+type PhotoPatchRequest struct {
+	Title   Optional[string] `json:"title"`
+	Caption Optional[string] `json:"caption"`
+	URL     Optional[string] `json:"photo_url"`
+}
+
+func (p PhotoPatchRequest) ValidatePatch() error {
+	var errs error
+
+	if !p.Title.Set && !p.Caption.Set && !p.URL.Set {
+		return helper.ErrEmptyPatch
+	}
+
+	if p.Title.Set {
+		if p.Title.Null || p.Title.Value == "" {
+			errs = errors.Join(errs, helper.ErrEmptyTitle)
+		} else if len(p.Title.Value) > 100 {
+			errs = errors.Join(errs, helper.ErrTitleTooLong)
+		}
+	}
+
+	if p.URL.Set {
+		if p.URL.Null || p.URL.Value == "" {
+			errs = errors.Join(errs, helper.ErrEmptyPhotoURL)
+		} else if !helper.IsValidURL(p.URL.Value) {
+			errs = errors.Join(errs, helper.ErrInvalidPhotoURL)
+		}
+	}
+
+	return errs
 }
@@ -77,3 +77,30 @@ type SocialMediaGetByUserIDResponse struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+type SocialMediaPatchRequest struct {
+	Name Optional[string] `json:"name"`
+	URL  Optional[string] `json:"social_media_url"`
+}
+
+func (s SocialMediaPatchRequest) ValidatePatch() error {
+	var errs error
+
+	if !s.Name.Set && !s.URL.Set {
+		return helper.ErrEmptyPatch
+	}
+
+	if s.Name.Set && (s.Name.Null || s.Name.Value == "") {
+		errs = errors.Join(errs, helper.ErrEmptyName)
+	}
+
+	if s.URL.Set {
+		if s.URL.Null || s.URL.Value == "" {
+			errs = errors.Join(errs, helper.ErrEmptySocialMediaURL)
+		} else if !helper.IsValidURL(s.URL.Value) {
+			errs = errors.Join(errs, helper.ErrInvalidSocialMediaURL)
+		}
+	}
+
+	return errs
+}
@@ -48,3 +48,23 @@ type SocialMediaUpdate struct {
 	Name string `json:"name" example:"X"`
 	URL  string `json:"social_media_url" example:"https://x.com/budiganteng"`
 }
+
+type UserPatch struct {
+	Email    string `json:"email,omitempty" example:"budigantengpol@rocketmail.com"`
+	Username string `json:"username,omitempty" example:"budigantengpol"`
+}
+
+type PhotoPatch struct {
+	Title   string  `json:"title,omitempty" example:"Gambarnya Budi Ganteng Pol"`
+	Caption *string `json:"caption,omitempty" example:"Ini adalah foto Budi yang ganteng pol"`
+	URL     string  `json:"photo_url,omitempty" example:"https://www.budiganteng.com/pol.jpg"`
+}
+
+type CommentPatch struct {
+	Message string `json:"message,omitempty" example:"buset ganteng pol nih fotonya"`
+}
+
+type SocialMediaPatch struct {
+	Name string `json:"name,omitempty" example:"Bluesky"`
+	URL  string `json:"social_media_url,omitempty" example:"https://bsky.app/profile/budiganteng"`
+}
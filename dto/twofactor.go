@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"errors"
+	"final-project/helper"
+)
+
+type TOTPCodeRequest struct {
+	Code string `json:"code" example:"123456"`
+}
+
+func (t TOTPCodeRequest) Validate() error {
+	if t.Code == "" {
+		return helper.ErrEmptyTOTPCode
+	}
+	return nil
+}
+
+type UserLoginTOTPRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code" example:"123456"`
+}
+
+func (u UserLoginTOTPRequest) Validate() error {
+	var errs error
+
+	if u.ChallengeToken == "" {
+		errs = errors.Join(errs, helper.ErrEmptyChallengeToken)
+	}
+
+	if u.Code == "" {
+		errs = errors.Join(errs, helper.ErrEmptyTOTPCode)
+	}
+
+	return errs
+}
+
+type TOTPSetupResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
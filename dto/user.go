@@ -3,6 +3,8 @@ package dto
 import (
 	"errors"
 	"final-project/helper"
+	"fmt"
+	"slices"
 	"time"
 )
 
@@ -13,6 +15,8 @@ type UserRequest struct {
 	Email    string `json:"email" example:"budi@rocketmail.com"`
 	Password string `json:"password" example:"budigantengbanget123"`
 	Age      uint64 `json:"age" example:"25"`
+	// Scopes limits the token issued at login, it gets every scope when empty.
+	Scopes []string `json:"scopes,omitempty" example:"photos:read,comments:read"`
 }
 
 func (u UserRequest) ValidateCreate() error {
@@ -30,13 +34,8 @@ func (u UserRequest) ValidateCreate() error {
 		errs = errors.Join(errs, helper.ErrUsernameTooLong)
 	}
 
-	if u.Password == "" {
-		errs = errors.Join(errs, helper.ErrEmptyPassword)
-	} else if u.Password != "" && len(u.Password) < 6 {
-		errs = errors.Join(errs, helper.ErrPasswordTooShort)
-	} else if len(u.Password) > 72 {
-		// bcrypt.GenerateFromPassword only accepts at most 72 characters
-		errs = errors.Join(errs, helper.ErrPasswordTooLong)
+	if err := helper.Passwords.Check(u.Password, u.Username, u.Email); err != nil {
+		errs = errors.Join(errs, err)
 	}
 
 	if u.Age < 8 {
@@ -62,17 +61,31 @@ func (u UserRequest) ValidateLogin() error {
 		errs = errors.Join(errs, helper.ErrInvalidEmail)
 	}
 
+	// the policy isn't checked here, passwords set before it changed still
+	// have to work
 	if u.Password == "" {
 		errs = errors.Join(errs, helper.ErrEmptyPassword)
-	} else if u.Password != "" && len(u.Password) < 6 {
-		errs = errors.Join(errs, helper.ErrPasswordTooShort)
+	}
+
+	for i, scope := range u.Scopes {
+		if !helper.IsValidScope(scope) || slices.Index(u.Scopes, scope) != i {
+			errs = errors.Join(errs, fmt.Errorf("%w: %q", helper.ErrInvalidScope, scope))
+		}
 	}
 
 	return errs
 }
 
 type UserLoginResponse struct {
-	Token string `json:"token"`
+	Token             string `json:"token,omitempty"`
+	DeletionCancelled bool   `json:"deletion_cancelled,omitempty"`
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+	SignupRequired    bool   `json:"signup_required,omitempty"`
+	SignupToken       string `json:"signup_token,omitempty"`
+	LinkRequired      bool   `json:"link_required,omitempty"`
+	LinkToken         string `json:"link_token,omitempty"`
+	Email             string `json:"email,omitempty"`
 }
 
 func (u UserRequest) ValidateUpdate() error {
@@ -101,8 +114,53 @@ type UserUpdateResponse struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+type UserResponse struct {
+	ID            uint64    `json:"id"`
+	Age           uint64    `json:"age"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	Username      string    `json:"username"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type UserDeleteResponse struct {
+	DeleteAfter time.Time `json:"delete_after"`
+}
+
 type User struct {
 	ID       uint64 `json:"id"`
 	Email    string `json:"email"`
 	Username string `json:"username"`
 }
+
+type UserPatchRequest struct {
+	Username Optional[string] `json:"username"`
+	Email    Optional[string] `json:"email"`
+}
+
+func (u UserPatchRequest) ValidatePatch() error {
+	var errs error
+
+	if !u.Username.Set && !u.Email.Set {
+		return helper.ErrEmptyPatch
+	}
+
+	if u.Username.Set {
+		if u.Username.Null || u.Username.Value == "" {
+			errs = errors.Join(errs, helper.ErrEmptyUsername)
+		} else if len(u.Username.Value) > 100 {
+			errs = errors.Join(errs, helper.ErrUsernameTooLong)
+		}
+	}
+
+	if u.Email.Set {
+		if u.Email.Null || u.Email.Value == "" {
+			errs = errors.Join(errs, helper.ErrEmptyEmail)
+		} else if !isValidEmail(u.Email.Value) {
+			errs = errors.Join(errs, helper.ErrInvalidEmail)
+		}
+	}
+
+	return errs
+}
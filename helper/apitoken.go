@@ -0,0 +1,34 @@
+package helper
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// APITokenPrefix marks API tokens so they can be told apart from JWTs in
+// the Authorization header and found by secret scanners.
+const APITokenPrefix = "mgp_"
+
+// GenerateAPIToken returns a new API token and the part of it that's kept
+// in clear text to recognize it in listings.
+func GenerateAPIToken() (token, prefix string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token = APITokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, token[:len(APITokenPrefix)+6], nil
+}
+
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
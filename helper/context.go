@@ -1,7 +1,52 @@
 package helper
 
+import "context"
+
 type contextKey string
 
 var (
-	UserIDKey = contextKey("userID")
+	IfMatchKey   = contextKey("ifMatch")
+	ClientIPKey  = contextKey("clientIP")
+	UserAgentKey = contextKey("userAgent")
+	userKey      = contextKey("user")
+	requestIDKey = contextKey("requestID")
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
 )
+
+// Principal is the authenticated caller of a request. APITokenID is set
+// when the request was authenticated with an API token instead of a JWT,
+// SessionID otherwise.
+type Principal struct {
+	UserID     uint64
+	Role       string
+	TokenID    string
+	SessionID  uint64
+	APITokenID uint64
+	Scopes     []string
+}
+
+func ContextWithUser(ctx context.Context, user Principal) context.Context {
+	return context.WithValue(ctx, userKey, user)
+}
+
+// UserFromContext returns the principal set by middleware.NewAuth, ok is false
+// on routes that aren't authenticated.
+func UserFromContext(ctx context.Context) (Principal, bool) {
+	user, ok := ctx.Value(userKey).(Principal)
+	return user, ok
+}
+
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestIDFromContext returns the ID set by middleware.RequestID, or an
+// empty string outside of a request.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
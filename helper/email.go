@@ -5,6 +5,7 @@ import (
 	"net/mail"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 )
 
 var (
@@ -46,6 +47,18 @@ func IsValidEmail(email string, allowLocal bool) bool {
 	return IsValidURL(fmt.Sprintf("https://%s", host)) && validDomain
 }
 
+// MaskEmail keeps the first character of the local part and the domain, so
+// an address can be recognised without being stored, e.g. in the audit log
+// which outlives purged accounts.
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 1 {
+		return "***"
+	}
+	_, size := utf8.DecodeRuneInString(email)
+	return email[:size] + "***" + email[at:]
+}
+
 func IsValidEmailRegex(pattern string) func(string) bool {
 	re := regexp.MustCompile(pattern)
 	return func(email string) bool {
@@ -103,3 +103,21 @@ func BenchmarkGoMailPKGNoLocal(b *testing.B) {
 		helper.IsValidEmail("budi@wow.1aa", false)
 	}
 }
+
+func TestMaskEmail(t *testing.T) {
+	cases := map[string]string{
+		"budi@rocketmail.com": "b***@rocketmail.com",
+		"b@rocketmail.com":    "b***@rocketmail.com",
+		"édith@example.com":   "é***@example.com",
+		"a@b@example.com":     "a***@example.com",
+		"@example.com":        "***",
+		"not an email":        "***",
+		"":                    "***",
+	}
+
+	for email, want := range cases {
+		if got := helper.MaskEmail(email); got != want {
+			t.Errorf("MaskEmail(%q) = %q, want %q", email, got, want)
+		}
+	}
+}
@@ -5,40 +5,97 @@ import (
 )
 
 var (
-	ErrInternal              = errors.New("there's something wrong. it's our fault, not yours")
-	ErrNotAllowed            = errors.New("you're not allowed to perform this action")
-	ErrDuplicateUserEmail    = errors.New("user with given username or email already exists")
-	ErrInvalidLogin          = errors.New("invalid email or password")
-	ErrUserNotFound          = errors.New("user not found")
-	ErrPhotoNotFound         = errors.New("photo with given id not found")
-	ErrLikeNotFound          = errors.New("you haven't liked this photo yet")
-	ErrMultipleLikes         = errors.New("you've liked this photo")
-	ErrCommentNotFound       = errors.New("comment with given id not found")
-	ErrSocialMediaNotFound   = errors.New("social media with given id not found")
-	ErrNotLoggedIn           = errors.New("you're not logged in")
-	ErrInvalidID             = errors.New("id must be a positive integer")
-	ErrInvalidContentType    = errors.New("invalid content type")
-	ErrEmptyEmail            = errors.New("email can't be empty")
-	ErrInvalidEmail          = errors.New("invalid email format")
-	ErrEmptyUsername         = errors.New("username can't be empty")
-	ErrUsernameTooLong       = errors.New("username can't be more than 100 characters")
-	ErrEmptyPassword         = errors.New("password can't be empty")
-	ErrPasswordTooShort      = errors.New("password must be at least 6 characters")
-	ErrPasswordTooLong       = errors.New("password can't be more than 72 characters")
-	ErrAgeTooYoung           = errors.New("age must be at least 8 years old")
-	ErrEmptyTitle            = errors.New("title can't be empty")
-	ErrTitleTooLong          = errors.New("title can't be more than 100 characters")
-	ErrEmptyPhotoURL         = errors.New("photo_url can't be empty")
-	ErrInvalidPhotoURL       = errors.New("invalid photo_url format")
-	ErrEmptyMessage          = errors.New("message can't be empty")
-	ErrEmptyPhotoID          = errors.New("photo_id can't be empty")
-	ErrEmptyName             = errors.New("name can't be empty")
-	ErrEmptySocialMediaURL   = errors.New("social_media_url can't be empty")
-	ErrInvalidSocialMediaURL = errors.New("invalid social_media_url format")
-	ErrInvalidJWT            = errors.New("invalid JWT token")
-	ErrInvalidBasePath       = errors.New("base_path must start and end with a single '/' and can't contain any special characters except '-' and '/'")
-	ErrInvalidDuration       = errors.New("invalid duration format")
-	ErrUpdateConflict        = errors.New("the data you're trying to update has been modified by someone else")
+	ErrInternal                = errors.New("there's something wrong. it's our fault, not yours")
+	ErrNotAllowed              = errors.New("you're not allowed to perform this action")
+	ErrDuplicateUserEmail      = errors.New("user with given username or email already exists")
+	ErrInvalidLogin            = errors.New("invalid email or password")
+	ErrUserNotFound            = errors.New("user not found")
+	ErrPhotoNotFound           = errors.New("photo with given id not found")
+	ErrLikeNotFound            = errors.New("you haven't liked this photo yet")
+	ErrMultipleLikes           = errors.New("you've liked this photo")
+	ErrCommentNotFound         = errors.New("comment with given id not found")
+	ErrSocialMediaNotFound     = errors.New("social media with given id not found")
+	ErrNotLoggedIn             = errors.New("you're not logged in")
+	ErrInvalidID               = errors.New("id must be a positive integer")
+	ErrInvalidContentType      = errors.New("invalid content type")
+	ErrEmptyEmail              = errors.New("email can't be empty")
+	ErrInvalidEmail            = errors.New("invalid email format")
+	ErrEmptyUsername           = errors.New("username can't be empty")
+	ErrUsernameTooLong         = errors.New("username can't be more than 100 characters")
+	ErrEmptyPassword           = errors.New("password can't be empty")
+	ErrPasswordTooShort        = errors.New("password is too short")
+	ErrPasswordTooLong         = errors.New("password can't be more than 72 characters")
+	ErrAgeTooYoung             = errors.New("age must be at least 8 years old")
+	ErrEmptyTitle              = errors.New("title can't be empty")
+	ErrTitleTooLong            = errors.New("title can't be more than 100 characters")
+	ErrEmptyPhotoURL           = errors.New("photo_url can't be empty")
+	ErrInvalidPhotoURL         = errors.New("invalid photo_url format")
+	ErrEmptyMessage            = errors.New("message can't be empty")
+	ErrEmptyPhotoID            = errors.New("photo_id can't be empty")
+	ErrEmptyName               = errors.New("name can't be empty")
+	ErrEmptySocialMediaURL     = errors.New("social_media_url can't be empty")
+	ErrInvalidSocialMediaURL   = errors.New("invalid social_media_url format")
+	ErrInvalidJWT              = errors.New("invalid JWT token")
+	ErrInvalidBasePath         = errors.New("base_path must start and end with a single '/' and can't contain any special characters except '-' and '/'")
+	ErrInvalidDuration         = errors.New("invalid duration format")
+	ErrUpdateConflict          = errors.New("the data you're trying to update has been modified by someone else")
+	ErrPreconditionFailed      = errors.New("the data has been modified since you last fetched it")
+	ErrPreconditionRequired    = errors.New("If-Match header is required for this request")
+	ErrEmptyPatch              = errors.New("request body doesn't contain any field to update")
+	ErrExportNotFound          = errors.New("export with given id not found")
+	ErrExportNotReady          = errors.New("export is not ready yet")
+	ErrExportTimedOut          = errors.New("export took too long, please request a new one")
+	ErrExportInProgress        = errors.New("an export is already in progress, wait for it to finish")
+	ErrServiceBusy             = errors.New("the server is busy, please try again later")
+	ErrInvalidRateLimitBackend = errors.New("rate_limit.backend must be either memory or postgres")
+	ErrInvalidRateLimitRule    = errors.New("rate limit burst and rate must be greater than 0")
+	ErrUnknownRateLimitRoute   = errors.New("rate_limit.routes has a pattern no route is registered under")
+	ErrAccountLocked           = errors.New("account is locked because of too many failed login attempts")
+	ErrTooManyLoginAttempts    = errors.New("too many failed login attempts, please try again later")
+	ErrEmptyTOTPCode           = errors.New("code can't be empty")
+	ErrEmptyChallengeToken     = errors.New("challenge_token can't be empty")
+	ErrInvalidTOTPCode         = errors.New("invalid two-factor code")
+	ErrInvalidChallengeToken   = errors.New("challenge token is invalid or expired, please login again")
+	ErrTOTPAlreadyEnabled      = errors.New("two-factor authentication is already enabled")
+	ErrTOTPNotEnabled          = errors.New("two-factor authentication is not enabled")
+	ErrTOTPNotSetUp            = errors.New("two-factor authentication hasn't been set up yet")
+	ErrUnsupportedAlgorithm    = errors.New("app.jwt_signing.algorithm must be either HS256, RS256 or EdDSA")
+	ErrUnsupportedKey          = errors.New("key must be a PEM encoded RSA or Ed25519 key")
+	ErrOIDCProviderNotFound    = errors.New("login provider not found")
+	ErrOIDCProviderUnavailable = errors.New("login provider is unavailable, please try again later")
+	ErrInvalidOIDCState        = errors.New("login session is invalid or expired, please start again")
+	ErrOIDCLoginFailed         = errors.New("login with the provider failed")
+	ErrOIDCEmailRequired       = errors.New("login provider didn't share an email address")
+	ErrEmptySignupToken        = errors.New("signup_token can't be empty")
+	ErrInvalidSignupToken      = errors.New("signup token is invalid or expired, please login again")
+	ErrOIDCEmailNotVerified    = errors.New("login provider hasn't verified your email address")
+	ErrEmptyLinkToken          = errors.New("link_token can't be empty")
+	ErrInvalidLinkToken        = errors.New("link token is invalid or expired, please login with the provider again")
+	ErrInvalidOIDCProvider     = errors.New("every app.oidc_providers entry needs an issuer, client_id and redirect_url")
+	ErrEmptyTokenName          = errors.New("name can't be empty")
+	ErrTokenNameTooLong        = errors.New("name can't be more than 100 characters")
+	ErrEmptyScopes             = errors.New("scopes can't be empty")
+	ErrInvalidScope            = errors.New("invalid scope")
+	ErrInvalidExpiresIn        = errors.New("expires_in must be a positive duration such as 720h")
+	ErrAPITokenNotFound        = errors.New("API token with given id not found")
+	ErrAPITokenNotAllowed      = errors.New("API tokens can't be managed with an API token, please login")
+	ErrMissingScope            = errors.New("token is missing the required scope")
+	ErrSessionNotFound         = errors.New("session with given id not found")
+	ErrSessionRevoked          = errors.New("session has been revoked or has expired, please login again")
+	ErrInvalidTime             = errors.New("time must be in RFC 3339 format")
+	ErrInvalidLimit            = errors.New("limit must be between 1 and 200")
+	ErrPasswordTooCommon       = errors.New("password is too common, please choose another one")
+	ErrPasswordPersonal        = errors.New("password can't be the same as your username or email")
+	ErrInvalidPasswordHashing  = errors.New("app.password_hashing.algorithm must be either bcrypt or argon2id with valid parameters")
+	ErrInvalidCORS             = errors.New("app.cors.allow_credentials can't be used when every origin is allowed")
+	ErrClientCertRequired      = errors.New("a client certificate is required for this route")
+	ErrInvalidTLS              = errors.New("app.tls_cert_file and app.tls_key_file must be set together")
+	ErrTLSRequired             = errors.New("app.admin_mtls and app.http_redirect_addr need TLS to be configured")
+	ErrClientCARequired        = errors.New("app.admin_mtls needs app.tls_client_ca_file")
+	ErrInvalidLogFormat        = errors.New("log.format must be either text or json")
+	ErrInvalidLogLevel         = errors.New("level must be one of debug, info, warn or error")
+	ErrInvalidTracingExporter  = errors.New("tracing.exporter must be one of none, stdout or otlp")
+	ErrInvalidSampleRatio      = errors.New("tracing.sample_ratio must be between 0 and 1")
 )
 
 type ResponseError struct {
@@ -0,0 +1,58 @@
+package helper
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ETag builds a strong entity tag from the resource id and its last
+// modification time, which is also the version used for optimistic locking.
+func ETag(id uint64, updatedAt time.Time) string {
+	return `"` + strconv.FormatUint(id, 36) + "-" + strconv.FormatInt(updatedAt.UnixNano(), 36) + `"`
+}
+
+// MatchETag reports whether etag is listed in the value of an If-Match or
+// If-None-Match header. If-None-Match uses the weak comparison (RFC 9110
+// section 8.8.3.2), If-Match the strong one.
+func MatchETag(header, etag string, weak bool) bool {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckIfMatch reports whether etag satisfies the If-Match precondition of
+// the current request. Requests without If-Match always pass.
+func CheckIfMatch(ctx context.Context, etag string) bool {
+	ifMatch, ok := ctx.Value(IfMatchKey).(string)
+	if !ok {
+		return true
+	}
+	return MatchETag(ifMatch, etag, false)
+}
+
+// HasIfMatch reports whether the current request carries an If-Match header.
+func HasIfMatch(ctx context.Context) bool {
+	_, ok := ctx.Value(IfMatchKey).(string)
+	return ok
+}
@@ -0,0 +1,36 @@
+package helper_test
+
+import (
+	"final-project/helper"
+	"testing"
+	"time"
+)
+
+func TestMatchETag(t *testing.T) {
+	etag := helper.ETag(42, time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC))
+
+	testcases := []struct {
+		name   string
+		header string
+		weak   bool
+		out    bool
+	}{
+		{"empty", "", false, false},
+		{"wildcard", "*", false, true},
+		{"exact", etag, false, true},
+		{"list", `"abc", ` + etag, false, true},
+		{"other", `"abc"`, false, false},
+		{"weak strong comparison", "W/" + etag, false, false},
+		{"weak weak comparison", "W/" + etag, true, true},
+		{"other version", helper.ETag(42, time.Date(2024, 3, 10, 12, 0, 0, 1000, time.UTC)), false, false},
+		{"other id", helper.ETag(43, time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)), true, false},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := helper.MatchETag(tt.header, etag, tt.weak); got != tt.out {
+				t.Errorf("MatchETag(%s, %s, %t) = %t, want %t", tt.header, etag, tt.weak, got, tt.out)
+			}
+		})
+	}
+}
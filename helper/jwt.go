@@ -1,15 +1,20 @@
 package helper
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 var (
-	JWTSecret    []byte
 	JWTExpiresIn time.Duration
+	JWTIssuer    string
+	JWTAudience  string
 )
 
 func GetJWTExpiresIn(d string, default_ time.Duration) time.Duration {
@@ -20,34 +25,267 @@ func GetJWTExpiresIn(d string, default_ time.Duration) time.Duration {
 	return duration
 }
 
-func GenerateJWT(userID uint64) (string, error) {
-	t := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": userID,
-		"exp": time.Now().Add(JWTExpiresIn).Unix(),
-	})
+const (
+	tokenTypeAccess    = "access"
+	tokenTypeChallenge = "2fa"
+	tokenTypeOIDCState = "oidc_state"
+	tokenTypeSignup    = "signup"
+	tokenTypeLink      = "link"
+
+	challengeExpiresIn = 5 * time.Minute
+	oidcExpiresIn      = 10 * time.Minute
+)
+
+type Claims struct {
+	jwt.RegisteredClaims
+	Role  string `json:"role,omitempty"`
+	Type  string `json:"typ"`
+	Scope string `json:"scope,omitempty"`
+	// SessionID is the login session the token belongs to, revoking the
+	// session revokes the token.
+	SessionID string `json:"sid,omitempty"`
+}
+
+func (c Claims) UserID() (uint64, error) {
+	return strconv.ParseUint(c.Subject, 10, 64)
+}
+
+func (c Claims) Session() (uint64, error) {
+	return strconv.ParseUint(c.SessionID, 10, 64)
+}
+
+// Scopes returns the space separated scope claim. Every token this API
+// issues has one, a token without it grants nothing.
+func (c Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// scopeClaim grants every scope when scopes is empty.
+func scopeClaim(scopes []string) string {
+	if len(scopes) == 0 {
+		scopes = Scopes
+	}
+	return strings.Join(scopes, " ")
+}
+
+// OIDCClaims carry an OIDC login between the redirect to the provider and
+// its callback (State, Nonce and Verifier), and the identity of a new user
+// until they finish signing up (Subject is then the provider's subject).
+type OIDCClaims struct {
+	Claims
+	Provider string `json:"provider"`
+	State    string `json:"state,omitempty"`
+	Nonce    string `json:"nonce,omitempty"`
+	Verifier string `json:"verifier,omitempty"`
+	Email    string `json:"email,omitempty"`
+}
+
+func newClaims(userID uint64, typ string, expiresIn time.Duration) (Claims, error) {
+	return newSubjectClaims(strconv.FormatUint(userID, 10), typ, expiresIn)
+}
+
+func newSubjectClaims(subject, typ string, expiresIn time.Duration) (Claims, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return Claims{}, err
+	}
+
+	now := time.Now()
+	return Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    JWTIssuer,
+			Subject:   subject,
+			Audience:  jwt.ClaimStrings{JWTAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiresIn)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        base64.RawURLEncoding.EncodeToString(jti),
+		},
+		Type: typ,
+	}, nil
+}
+
+// GenerateJWT issues an access token for a login session, limited to
+// scopes or with every scope when it's empty.
+func GenerateJWT(userID uint64, role string, sessionID uint64, scopes []string) (string, error) {
+	claims, err := newClaims(userID, tokenTypeAccess, JWTExpiresIn)
+	if err != nil {
+		return "", fmt.Errorf("helper.GenerateJWT: %w", err)
+	}
+	claims.Role = role
+	claims.Scope = scopeClaim(scopes)
+	claims.SessionID = strconv.FormatUint(sessionID, 10)
 
-	jwt, err := t.SignedString(JWTSecret)
+	jwt, err := JWTKeys.sign(claims)
 	if err != nil {
 		return "", fmt.Errorf("helper.GenerateJWT: %w", err)
 	}
 	return jwt, nil
 }
 
-func VerifyJWT(tokenString string) (jwt.MapClaims, error) {
-	t, err := jwt.Parse(tokenString, func(t *jwt.Token) (any, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		return JWTSecret, nil
+// GenerateChallengeJWT issues the short-lived token handed out after the
+// password step of a login when the user has two-factor authentication on.
+// It can't be used as an access token, it carries the scopes requested at
+// login over to the access token.
+func GenerateChallengeJWT(userID uint64, scopes []string) (string, error) {
+	claims, err := newClaims(userID, tokenTypeChallenge, challengeExpiresIn)
+	if err != nil {
+		return "", fmt.Errorf("helper.GenerateChallengeJWT: %w", err)
+	}
+	claims.Scope = scopeClaim(scopes)
+
+	jwt, err := JWTKeys.sign(claims)
+	if err != nil {
+		return "", fmt.Errorf("helper.GenerateChallengeJWT: %w", err)
+	}
+	return jwt, nil
+}
+
+// GenerateOIDCStateJWT seals the state of an OIDC login, it's kept in a
+// cookie until the provider redirects back.
+func GenerateOIDCStateJWT(provider, state, nonce, verifier string) (string, error) {
+	claims, err := newSubjectClaims("", tokenTypeOIDCState, oidcExpiresIn)
+	if err != nil {
+		return "", fmt.Errorf("helper.GenerateOIDCStateJWT: %w", err)
+	}
+
+	jwt, err := JWTKeys.sign(OIDCClaims{
+		Claims:   claims,
+		Provider: provider,
+		State:    state,
+		Nonce:    nonce,
+		Verifier: verifier,
+	})
+	if err != nil {
+		return "", fmt.Errorf("helper.GenerateOIDCStateJWT: %w", err)
+	}
+	return jwt, nil
+}
+
+// GenerateSignupJWT is handed out when an OIDC login doesn't match any user,
+// it proves the identity when the user then picks a username.
+func GenerateSignupJWT(provider, subject, email string) (string, error) {
+	claims, err := newSubjectClaims(subject, tokenTypeSignup, oidcExpiresIn)
+	if err != nil {
+		return "", fmt.Errorf("helper.GenerateSignupJWT: %w", err)
+	}
+
+	jwt, err := JWTKeys.sign(OIDCClaims{
+		Claims:   claims,
+		Provider: provider,
+		Email:    email,
+	})
+	if err != nil {
+		return "", fmt.Errorf("helper.GenerateSignupJWT: %w", err)
+	}
+	return jwt, nil
+}
+
+// GenerateLinkJWT is handed out when an OIDC login matches a user by an
+// email address that isn't verified on both sides. The user has to log in
+// with their password and send it to link the identity.
+func GenerateLinkJWT(provider, subject, email string) (string, error) {
+	claims, err := newSubjectClaims(subject, tokenTypeLink, oidcExpiresIn)
+	if err != nil {
+		return "", fmt.Errorf("helper.GenerateLinkJWT: %w", err)
+	}
+
+	jwt, err := JWTKeys.sign(OIDCClaims{
+		Claims:   claims,
+		Provider: provider,
+		Email:    email,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("helper.VerifyJWT: %w", err)
+		return "", fmt.Errorf("helper.GenerateLinkJWT: %w", err)
+	}
+	return jwt, nil
+}
+
+func VerifyJWT(tokenString string) (Claims, error) {
+	var claims Claims
+
+	err := parseJWT(tokenString, &claims, &claims, tokenTypeAccess)
+	if err != nil {
+		return claims, fmt.Errorf("helper.VerifyJWT: %w", err)
+	}
+
+	if _, err := claims.UserID(); err != nil {
+		return claims, fmt.Errorf("helper.VerifyJWT: %w", ErrInvalidJWT)
+	}
+
+	if _, err := claims.Session(); err != nil {
+		return claims, fmt.Errorf("helper.VerifyJWT: %w", ErrInvalidJWT)
+	}
+
+	return claims, nil
+}
+
+func VerifyChallengeJWT(tokenString string) (uint64, []string, error) {
+	var claims Claims
+
+	err := parseJWT(tokenString, &claims, &claims, tokenTypeChallenge)
+	if err != nil {
+		return 0, nil, fmt.Errorf("helper.VerifyChallengeJWT: %w", err)
+	}
+
+	userID, err := claims.UserID()
+	if err != nil {
+		return 0, nil, fmt.Errorf("helper.VerifyChallengeJWT: %w", ErrInvalidJWT)
 	}
 
-	claims, ok := t.Claims.(jwt.MapClaims)
-	if !ok || !t.Valid {
-		return nil, fmt.Errorf("helper.VerifyJWT: %w", jwt.ErrSignatureInvalid)
+	return userID, claims.Scopes(), nil
+}
+
+func VerifyOIDCStateJWT(tokenString string) (OIDCClaims, error) {
+	var claims OIDCClaims
+
+	err := parseJWT(tokenString, &claims, &claims.Claims, tokenTypeOIDCState)
+	if err != nil {
+		return claims, fmt.Errorf("helper.VerifyOIDCStateJWT: %w", err)
 	}
 
 	return claims, nil
 }
+
+func VerifySignupJWT(tokenString string) (OIDCClaims, error) {
+	var claims OIDCClaims
+
+	err := parseJWT(tokenString, &claims, &claims.Claims, tokenTypeSignup)
+	if err != nil {
+		return claims, fmt.Errorf("helper.VerifySignupJWT: %w", err)
+	}
+
+	return claims, nil
+}
+
+func VerifyLinkJWT(tokenString string) (OIDCClaims, error) {
+	var claims OIDCClaims
+
+	err := parseJWT(tokenString, &claims, &claims.Claims, tokenTypeLink)
+	if err != nil {
+		return claims, fmt.Errorf("helper.VerifyLinkJWT: %w", err)
+	}
+
+	return claims, nil
+}
+
+// parseJWT checks the signature and the registered claims, every token must
+// carry exp, iat and a jti, be issued by and for this API and be of type typ.
+// base is the Claims embedded in dst.
+func parseJWT(tokenString string, dst jwt.Claims, base *Claims, typ string) error {
+	_, err := jwt.ParseWithClaims(tokenString, dst, JWTKeys.keyFunc,
+		jwt.WithIssuer(JWTIssuer),
+		jwt.WithAudience(JWTAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if base.Type != typ || base.ID == "" {
+		return ErrInvalidJWT
+	}
+
+	return nil
+}
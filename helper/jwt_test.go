@@ -0,0 +1,102 @@
+package helper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestVerifyJWT(t *testing.T) {
+	JWTKeys = NewKeySet(NewHMACKey([]byte("secret")))
+	JWTExpiresIn = time.Minute
+	JWTIssuer, JWTAudience = "mygram", "mygram-api"
+
+	access, err := GenerateJWT(42, RoleAdmin, 7, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := VerifyJWT(access)
+	if err != nil {
+		t.Fatalf("VerifyJWT() error = %v", err)
+	}
+	if sid, _ := claims.Session(); sid != 7 {
+		t.Errorf("VerifyJWT() sid = %d, want 7", sid)
+	}
+	if id, _ := claims.UserID(); id != 42 || claims.Role != RoleAdmin || claims.ID == "" || claims.IssuedAt == nil || len(claims.Scopes()) != len(Scopes) {
+		t.Errorf("VerifyJWT() claims = %+v", claims)
+	}
+
+	challenge, err := GenerateChallengeJWT(42, []string{ScopePhotosRead})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(claims jwt.Claims) string {
+		token, err := JWTKeys.sign(claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	valid, _ := newClaims(42, tokenTypeAccess, time.Minute)
+	otherAudience := valid
+	otherAudience.Audience = jwt.ClaimStrings{"someone-else"}
+	otherIssuer := valid
+	otherIssuer.Issuer = "someone-else"
+	notYetValid := valid
+	notYetValid.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Hour))
+	withoutJTI := valid
+	withoutJTI.ID = ""
+	withoutExp := valid
+	withoutExp.ExpiresAt = nil
+
+	cases := map[string]string{
+		"challenge token":  challenge,
+		"other audience":   sign(otherAudience),
+		"other issuer":     sign(otherIssuer),
+		"not yet valid":    sign(notYetValid),
+		"without jti":      sign(withoutJTI),
+		"without exp":      sign(withoutExp),
+		"legacy map claim": sign(jwt.MapClaims{"sub": 42, "exp": time.Now().Add(time.Minute).Unix()}),
+	}
+	for name, token := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := VerifyJWT(token); err == nil {
+				t.Error("VerifyJWT() error = nil, want an error")
+			}
+		})
+	}
+
+	if id, scopes, err := VerifyChallengeJWT(challenge); err != nil || id != 42 || len(scopes) != 1 || scopes[0] != ScopePhotosRead {
+		t.Errorf("VerifyChallengeJWT() = %d, %v, %v, want 42, [%s]", id, scopes, err, ScopePhotosRead)
+	}
+	if _, _, err := VerifyChallengeJWT(access); err == nil {
+		t.Error("VerifyChallengeJWT() accepted an access token")
+	}
+}
+
+func TestVerifyJWTWithoutScopeClaim(t *testing.T) {
+	JWTKeys = NewKeySet(NewHMACKey([]byte("secret")))
+	JWTIssuer, JWTAudience = "mygram", "mygram-api"
+
+	claims, err := newClaims(42, tokenTypeAccess, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims.SessionID = "7"
+	token, err := JWTKeys.sign(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := VerifyJWT(token)
+	if err != nil {
+		t.Fatalf("VerifyJWT() error = %v", err)
+	}
+	if scopes := got.Scopes(); len(scopes) != 0 {
+		t.Errorf("Scopes() = %v, want none for a token without a scope claim", scopes)
+	}
+}
@@ -0,0 +1,208 @@
+package helper
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key is a key used to sign or verify tokens. For HS256 both the signing
+// and the verification key are the shared secret, asymmetric keys only
+// carry the private half when they're used for signing.
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	SignKey   any
+	VerifyKey any
+}
+
+// KeySet signs new tokens with a single key and verifies tokens against
+// every key it holds, which lets old keys stay valid during a rotation.
+type KeySet struct {
+	signing Key
+	keys    map[string]Key
+}
+
+var JWTKeys *KeySet
+
+func NewKeySet(signing Key, verification ...Key) *KeySet {
+	ks := &KeySet{
+		signing: signing,
+		keys:    make(map[string]Key, len(verification)+1),
+	}
+
+	ks.keys[signing.ID] = signing
+	for _, key := range verification {
+		ks.keys[key.ID] = key
+	}
+
+	return ks
+}
+
+func NewHMACKey(secret []byte) Key {
+	return Key{
+		Method:    jwt.SigningMethodHS256,
+		SignKey:   secret,
+		VerifyKey: secret,
+	}
+}
+
+// NewPrivateKey parses a PEM encoded RSA or Ed25519 private key for alg
+// (RS256 or EdDSA). An empty id is replaced with the RFC 7638 thumbprint of
+// the public key.
+func NewPrivateKey(alg, id string, pemData []byte) (Key, error) {
+	var (
+		key = Key{ID: id}
+		err error
+	)
+
+	switch alg {
+	case "RS256":
+		var priv *rsa.PrivateKey
+		priv, err = jwt.ParseRSAPrivateKeyFromPEM(pemData)
+		if err == nil {
+			key.Method, key.SignKey, key.VerifyKey = jwt.SigningMethodRS256, priv, &priv.PublicKey
+		}
+	case "EdDSA":
+		var priv crypto.PrivateKey
+		priv, err = jwt.ParseEdPrivateKeyFromPEM(pemData)
+		if err == nil {
+			signer := priv.(ed25519.PrivateKey)
+			key.Method, key.SignKey, key.VerifyKey = jwt.SigningMethodEdDSA, signer, signer.Public()
+		}
+	default:
+		return key, fmt.Errorf("helper.NewPrivateKey: %w", ErrUnsupportedAlgorithm)
+	}
+	if err != nil {
+		return key, fmt.Errorf("helper.NewPrivateKey: %w", err)
+	}
+
+	if key.ID == "" {
+		key.ID, err = thumbprint(key.VerifyKey)
+		if err != nil {
+			return key, fmt.Errorf("helper.NewPrivateKey: %w", err)
+		}
+	}
+
+	return key, nil
+}
+
+// NewPublicKey parses a PEM encoded RSA or Ed25519 public key. The
+// algorithm is inferred from the key type.
+func NewPublicKey(id string, pemData []byte) (Key, error) {
+	var key = Key{ID: id}
+
+	if pub, err := jwt.ParseRSAPublicKeyFromPEM(pemData); err == nil {
+		key.Method, key.VerifyKey = jwt.SigningMethodRS256, pub
+	} else if pub, err := jwt.ParseEdPublicKeyFromPEM(pemData); err == nil {
+		key.Method, key.VerifyKey = jwt.SigningMethodEdDSA, pub
+	} else {
+		return key, fmt.Errorf("helper.NewPublicKey: %w", ErrUnsupportedKey)
+	}
+
+	if key.ID == "" {
+		var err error
+		key.ID, err = thumbprint(key.VerifyKey)
+		if err != nil {
+			return key, fmt.Errorf("helper.NewPublicKey: %w", err)
+		}
+	}
+
+	return key, nil
+}
+
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	t := jwt.NewWithClaims(ks.signing.Method, claims)
+	if ks.signing.ID != "" {
+		t.Header["kid"] = ks.signing.ID
+	}
+	return t.SignedString(ks.signing.SignKey)
+}
+
+func (ks *KeySet) keyFunc(t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown kid %q", jwt.ErrTokenUnverifiable, kid)
+	}
+
+	if t.Method.Alg() != key.Method.Alg() {
+		return nil, jwt.ErrTokenSignatureInvalid
+	}
+
+	return key.VerifyKey, nil
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS returns the public keys of the set as a JSON Web Key Set. Shared
+// secrets are never included.
+func (ks *KeySet) JWKS() ([]byte, error) {
+	set := struct {
+		Keys []jwk `json:"keys"`
+	}{Keys: []jwk{}}
+
+	for _, key := range ks.keys {
+		k, ok := toJWK(key.VerifyKey)
+		if !ok {
+			continue
+		}
+		k.Kid, k.Use, k.Alg = key.ID, "sig", key.Method.Alg()
+		set.Keys = append(set.Keys, k)
+	}
+
+	return json.Marshal(set)
+}
+
+func toJWK(pub any) (jwk, bool) {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return jwk{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return jwk{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(pub),
+		}, true
+	}
+	return jwk{}, false
+}
+
+// thumbprint computes the RFC 7638 JWK thumbprint of a public key.
+func thumbprint(pub any) (string, error) {
+	k, ok := toJWK(pub)
+	if !ok {
+		return "", ErrUnsupportedKey
+	}
+
+	var members string
+	switch k.Kty {
+	case "RSA":
+		members = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, k.E, k.N)
+	case "OKP":
+		members = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":%q}`, k.X)
+	}
+
+	sum := sha256.Sum256([]byte(members))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
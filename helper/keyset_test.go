@@ -0,0 +1,109 @@
+package helper
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+	"time"
+)
+
+func newTestEdKey(t *testing.T, id string) (Key, Key) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signing, err := NewPrivateKey("EdDSA", id, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	verification, err := NewPublicKey(id, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signing, verification
+}
+
+func TestKeySetRotation(t *testing.T) {
+	oldSigning, oldVerification := newTestEdKey(t, "")
+	newSigning, _ := newTestEdKey(t, "2026-10")
+
+	if oldSigning.ID == "" || oldSigning.ID != oldVerification.ID {
+		t.Fatalf("thumbprint kid = %q and %q, want equal and non-empty", oldSigning.ID, oldVerification.ID)
+	}
+
+	JWTExpiresIn = time.Minute
+	JWTIssuer, JWTAudience = "mygram", "mygram-api"
+	JWTKeys = NewKeySet(oldSigning)
+	oldToken, err := GenerateJWT(1, RoleUser, 7, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	JWTKeys = NewKeySet(newSigning, oldVerification)
+	newToken, err := GenerateJWT(2, RoleUser, 7, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for token, want := range map[string]uint64{oldToken: 1, newToken: 2} {
+		claims, err := VerifyJWT(token)
+		if err != nil {
+			t.Fatalf("VerifyJWT() error = %v", err)
+		}
+		if got, _ := claims.UserID(); got != want {
+			t.Errorf("VerifyJWT() sub = %v, want %d", got, want)
+		}
+	}
+
+	JWTKeys = NewKeySet(newSigning)
+	if _, err := VerifyJWT(oldToken); err == nil {
+		t.Error("VerifyJWT() accepted a token signed by a removed key")
+	}
+
+	JWTKeys = NewKeySet(NewHMACKey([]byte("secret")))
+	if _, err := VerifyJWT(newToken); err == nil {
+		t.Error("VerifyJWT() accepted an EdDSA token with an HS256 key set")
+	}
+}
+
+func TestKeySetJWKS(t *testing.T) {
+	signing, _ := newTestEdKey(t, "current")
+	_, previous := newTestEdKey(t, "previous")
+
+	data, err := NewKeySet(signing, previous, NewHMACKey([]byte("secret"))).JWKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var set struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(set.Keys) != 2 {
+		t.Fatalf("JWKS() returned %d keys, want 2: %s", len(set.Keys), data)
+	}
+	for _, k := range set.Keys {
+		if k["kty"] != "OKP" || k["alg"] != "EdDSA" || k["x"] == "" || k["d"] != "" {
+			t.Errorf("JWKS() key = %v", k)
+		}
+	}
+}
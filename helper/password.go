@@ -1,13 +1,63 @@
 package helper
 
 import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"fmt"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	PasswordBcrypt   = "bcrypt"
+	PasswordArgon2id = "argon2id"
+
+	argon2KeyLen  = 32
+	argon2SaltLen = 16
+)
+
+// PasswordHashing is how new password hashes are made. Hashes made with
+// other parameters keep working, NeedsRehash reports them so they can be
+// upgraded the next time the password is known.
+var PasswordHashing = PasswordParams{
+	Algorithm:     PasswordBcrypt,
+	BcryptCost:    bcrypt.DefaultCost,
+	Argon2Time:    3,
+	Argon2Memory:  64 * 1024,
+	Argon2Threads: 2,
+}
+
+// PasswordParams configures password hashing, Argon2Memory is in KiB.
+type PasswordParams struct {
+	Algorithm     string
+	BcryptCost    int
+	Argon2Time    uint32
+	Argon2Memory  uint32
+	Argon2Threads uint8
+}
+
 func HashPassword(raw string) ([]byte, error) {
-	hashed, err := bcrypt.GenerateFromPassword([]byte(raw), 5)
+	p := PasswordHashing
+
+	if p.Algorithm == PasswordArgon2id {
+		salt := make([]byte, argon2SaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, fmt.Errorf("helper.HashPassword: %w", err)
+		}
+
+		key := argon2.IDKey([]byte(raw), salt, p.Argon2Time, p.Argon2Memory, p.Argon2Threads, argon2KeyLen)
+		hashed := fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+			argon2.Version, p.Argon2Memory, p.Argon2Time, p.Argon2Threads,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+
+		return []byte(hashed), nil
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(raw), p.BcryptCost)
 	if err != nil {
 		return nil, fmt.Errorf("helper.HashPassword: %w", err)
 	}
@@ -16,6 +66,70 @@ func HashPassword(raw string) ([]byte, error) {
 }
 
 func IsValidPassword(hashed []byte, raw string) bool {
+	if bytes.HasPrefix(hashed, []byte("$argon2id$")) {
+		p, salt, key, err := parseArgon2id(hashed)
+		if err != nil {
+			return false
+		}
+
+		other := argon2.IDKey([]byte(raw), salt, p.Argon2Time, p.Argon2Memory, p.Argon2Threads, uint32(len(key)))
+		return subtle.ConstantTimeCompare(key, other) == 1
+	}
+
 	err := bcrypt.CompareHashAndPassword(hashed, []byte(raw))
 	return err == nil
 }
+
+// NeedsRehash reports whether hashed was made with another algorithm or
+// weaker parameters than PasswordHashing.
+func NeedsRehash(hashed []byte) bool {
+	p := PasswordHashing
+
+	if bytes.HasPrefix(hashed, []byte("$argon2id$")) {
+		if p.Algorithm != PasswordArgon2id {
+			return true
+		}
+
+		current, _, _, err := parseArgon2id(hashed)
+		return err != nil || current.Argon2Time != p.Argon2Time || current.Argon2Memory != p.Argon2Memory || current.Argon2Threads != p.Argon2Threads
+	}
+
+	if p.Algorithm != PasswordBcrypt {
+		return true
+	}
+
+	cost, err := bcrypt.Cost(hashed)
+	return err != nil || cost != p.BcryptCost
+}
+
+func parseArgon2id(hashed []byte) (PasswordParams, []byte, []byte, error) {
+	var (
+		p       = PasswordParams{Algorithm: PasswordArgon2id}
+		version int
+	)
+
+	parts := bytes.Split(hashed, []byte("$"))
+	if len(parts) != 6 {
+		return p, nil, nil, errors.New("helper.parseArgon2id: malformed hash")
+	}
+
+	if _, err := fmt.Sscanf(string(parts[2]), "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, errors.New("helper.parseArgon2id: unsupported version")
+	}
+
+	if _, err := fmt.Sscanf(string(parts[3]), "m=%d,t=%d,p=%d", &p.Argon2Memory, &p.Argon2Time, &p.Argon2Threads); err != nil {
+		return p, nil, nil, fmt.Errorf("helper.parseArgon2id: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(string(parts[4]))
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("helper.parseArgon2id: %w", err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(string(parts[5]))
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, errors.New("helper.parseArgon2id: malformed key")
+	}
+
+	return p, salt, key, nil
+}
@@ -0,0 +1,78 @@
+package helper
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordRehash(t *testing.T) {
+	defer func(p PasswordParams) { PasswordHashing = p }(PasswordHashing)
+
+	PasswordHashing = PasswordParams{Algorithm: PasswordBcrypt, BcryptCost: bcrypt.MinCost}
+	bcryptHash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+
+	PasswordHashing = PasswordParams{Algorithm: PasswordArgon2id, Argon2Time: 1, Argon2Memory: 1024, Argon2Threads: 1}
+	argonHash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+
+	for name, hashed := range map[string][]byte{"bcrypt": bcryptHash, "argon2id": argonHash} {
+		if !IsValidPassword(hashed, "correct horse") {
+			t.Errorf("IsValidPassword(%s) = false for the right password", name)
+		}
+		if IsValidPassword(hashed, "battery staple") {
+			t.Errorf("IsValidPassword(%s) = true for a wrong password", name)
+		}
+	}
+
+	if !NeedsRehash(bcryptHash) {
+		t.Error("NeedsRehash() = false for a bcrypt hash after switching to argon2id")
+	}
+	if NeedsRehash(argonHash) {
+		t.Error("NeedsRehash() = true for a hash made with the current parameters")
+	}
+
+	PasswordHashing.Argon2Time = 2
+	if !NeedsRehash(argonHash) {
+		t.Error("NeedsRehash() = false after the argon2id parameters changed")
+	}
+
+	PasswordHashing = PasswordParams{Algorithm: PasswordBcrypt, BcryptCost: bcrypt.MinCost + 1}
+	if !NeedsRehash(bcryptHash) {
+		t.Error("NeedsRehash() = false after the bcrypt cost changed")
+	}
+}
+
+func TestPasswordPolicyCheck(t *testing.T) {
+	policy := NewPasswordPolicy(8, []string{"Hunter2Hunter2"})
+
+	cases := []struct {
+		name     string
+		password string
+		want     error
+	}{
+		{"valid", "violet-lantern-42", nil},
+		{"empty", "", ErrEmptyPassword},
+		{"too short", "v1olet", ErrPasswordTooShort},
+		{"built-in list", "Password123", ErrPasswordTooCommon},
+		{"extra list", "hunter2hunter2", ErrPasswordTooCommon},
+		{"username", "budiganteng", ErrPasswordPersonal},
+		{"email", "Budi.Santoso@Rocketmail.com", ErrPasswordPersonal},
+		{"email local part", "budi.santoso", ErrPasswordPersonal},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := policy.Check(tc.password, "budiganteng", "budi.santoso@rocketmail.com")
+			if tc.want == nil && err != nil || tc.want != nil && !errors.Is(err, tc.want) {
+				t.Errorf("Check(%q) error = %v, want %v", tc.password, err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package helper
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// Passwords is the policy new passwords are checked against.
+var Passwords = NewPasswordPolicy(8, nil)
+
+type PasswordPolicy struct {
+	MinLength int
+	common    map[string]struct{}
+}
+
+// NewPasswordPolicy returns a policy rejecting passwords shorter than
+// minLength characters and those on the built-in list of common passwords
+// or in extra, compared case-insensitively.
+func NewPasswordPolicy(minLength int, extra []string) PasswordPolicy {
+	p := PasswordPolicy{MinLength: minLength, common: make(map[string]struct{})}
+
+	for _, list := range [][]string{strings.Split(commonPasswords, "\n"), extra} {
+		for _, password := range list {
+			if password = strings.TrimSpace(password); password != "" {
+				p.common[strings.ToLower(password)] = struct{}{}
+			}
+		}
+	}
+
+	return p
+}
+
+// Check validates a new password for the account with username and email.
+func (p PasswordPolicy) Check(password, username, email string) error {
+	if password == "" {
+		return ErrEmptyPassword
+	}
+
+	var errs error
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		errs = errors.Join(errs, fmt.Errorf("%w, it must be at least %d characters", ErrPasswordTooShort, p.MinLength))
+	} else if len(password) > 72 {
+		// bcrypt.GenerateFromPassword only accepts at most 72 bytes
+		errs = errors.Join(errs, ErrPasswordTooLong)
+	}
+
+	lower := strings.ToLower(password)
+	if _, ok := p.common[lower]; ok {
+		errs = errors.Join(errs, ErrPasswordTooCommon)
+	}
+
+	localPart, _, _ := strings.Cut(email, "@")
+	for _, personal := range []string{username, email, localPart} {
+		if personal != "" && lower == strings.ToLower(personal) {
+			errs = errors.Join(errs, ErrPasswordPersonal)
+			break
+		}
+	}
+
+	return errs
+}
@@ -34,6 +34,29 @@ const (
 	SocialMediaGetMine
 	PanicRecovery
 	Authentication
+	PhotoGetRevisions
+	CommentGetRevisions
+	ExportCreate
+	ExportGetByID
+	ExportDownload
+	UserUnlock
+	UserTOTPSetup
+	UserTOTPVerify
+	UserTOTPDisable
+	UserRecoveryCodes
+	UserOIDCLogin
+	UserOIDCRegister
+	UserOIDCLink
+	APITokenCreate
+	APITokenGetAll
+	APITokenDelete
+	SessionGetAll
+	SessionDelete
+	AuditGetMine
+	AuditSearch
+	LogLevelGet
+	LogLevelUpdate
+	UserGet
 )
 
 var messages = map[ResponseFor]func(int) string{
@@ -65,7 +88,7 @@ var messages = map[ResponseFor]func(int) string{
 		if errorCount > 0 {
 			return "failed to delete user"
 		}
-		return "user deleted successfully"
+		return "user scheduled for deletion"
 	},
 	PhotoCreate: func(errorCount int) string {
 		if errorCount > 0 {
@@ -217,4 +240,142 @@ var messages = map[ResponseFor]func(int) string{
 	Authentication: func(errorCount int) string {
 		return "unauthenticated"
 	},
+	PhotoGetRevisions: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to get photo revisions"
+		}
+		return "get photo revisions success"
+	},
+	CommentGetRevisions: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to get comment revisions"
+		}
+		return "get comment revisions success"
+	},
+	ExportCreate: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to request export"
+		}
+		return "export requested successfully"
+	},
+	ExportGetByID: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to get export"
+		}
+		return "get export success"
+	},
+	ExportDownload: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to download export"
+		}
+		return "download export success"
+	},
+	UserUnlock: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to unlock user"
+		}
+		return "user unlocked successfully"
+	},
+	UserTOTPSetup: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to set up two-factor authentication"
+		}
+		return "two-factor authentication set up, verify a code to enable it"
+	},
+	UserTOTPVerify: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to enable two-factor authentication"
+		}
+		return "two-factor authentication enabled successfully"
+	},
+	UserTOTPDisable: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to disable two-factor authentication"
+		}
+		return "two-factor authentication disabled successfully"
+	},
+	UserRecoveryCodes: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to regenerate recovery codes"
+		}
+		return "recovery codes regenerated successfully"
+	},
+	UserOIDCLogin: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to login with the provider"
+		}
+		return "login success"
+	},
+	UserOIDCRegister: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to register user"
+		}
+		return "user registered successfully"
+	},
+	UserOIDCLink: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to link login provider"
+		}
+		return "login provider linked successfully"
+	},
+	APITokenCreate: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to create API token"
+		}
+		return "API token created successfully"
+	},
+	APITokenGetAll: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to get API tokens"
+		}
+		return "API tokens retrieved successfully"
+	},
+	APITokenDelete: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to revoke API token"
+		}
+		return "API token revoked successfully"
+	},
+	SessionGetAll: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to get sessions"
+		}
+		return "sessions retrieved successfully"
+	},
+	SessionDelete: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to revoke session"
+		}
+		return "session revoked successfully"
+	},
+	AuditGetMine: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to get security events"
+		}
+		return "security events retrieved successfully"
+	},
+	AuditSearch: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to search audit events"
+		}
+		return "audit events retrieved successfully"
+	},
+	LogLevelGet: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to get log level"
+		}
+		return "log level retrieved successfully"
+	},
+	LogLevelUpdate: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to change log level"
+		}
+		return "log level changed successfully"
+	},
+	UserGet: func(errorCount int) string {
+		if errorCount > 0 {
+			return "failed to get user"
+		}
+		return "user retrieved successfully"
+	},
 }
@@ -11,6 +11,7 @@ type Response[T any] struct {
 	M           string   `json:"message"`
 	E           []string `json:"errors"`
 	D           *T       `json:"data"`
+	RID         string   `json:"request_id,omitempty"`
 	code        int
 	responseFor ResponseFor
 }
@@ -45,6 +46,10 @@ func (r *Response[T]) Code(code int) Sender {
 
 func (r *Response[T]) Send(w http.ResponseWriter) {
 	r.M = messages[r.responseFor](len(r.E))
+	if len(r.E) > 0 {
+		// set by middleware.RequestID, quoting it lets a failure be found in the logs
+		r.RID = w.Header().Get("X-Request-ID")
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(r.code)
 	err := json.NewEncoder(w).Encode(r)
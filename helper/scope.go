@@ -0,0 +1,35 @@
+package helper
+
+import "slices"
+
+const (
+	ScopePhotosRead        = "photos:read"
+	ScopePhotosWrite       = "photos:write"
+	ScopeCommentsRead      = "comments:read"
+	ScopeCommentsWrite     = "comments:write"
+	ScopeLikesRead         = "likes:read"
+	ScopeLikesWrite        = "likes:write"
+	ScopeSocialMediasRead  = "socialmedias:read"
+	ScopeSocialMediasWrite = "socialmedias:write"
+	ScopeAccountRead       = "account:read"
+	ScopeAccountWrite      = "account:write"
+	ScopeAdmin             = "admin"
+)
+
+var Scopes = []string{
+	ScopePhotosRead,
+	ScopePhotosWrite,
+	ScopeCommentsRead,
+	ScopeCommentsWrite,
+	ScopeLikesRead,
+	ScopeLikesWrite,
+	ScopeSocialMediasRead,
+	ScopeSocialMediasWrite,
+	ScopeAccountRead,
+	ScopeAccountWrite,
+	ScopeAdmin,
+}
+
+func IsValidScope(scope string) bool {
+	return slices.Contains(Scopes, scope)
+}
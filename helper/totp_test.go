@@ -0,0 +1,74 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test vectors from RFC 6238 appendix B, SHA-1 variant.
+func TestHOTPRFC6238(t *testing.T) {
+	key := []byte("12345678901234567890")
+	cases := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, tc := range cases {
+		got := hotp(key, uint64(TOTPStep(time.Unix(tc.unix, 0))), 8)
+		if got != tc.want {
+			t.Errorf("hotp(T=%d) = %s, want %s", tc.unix, got, tc.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(1111111111, 0)
+	code := hotp([]byte("12345678901234567890"), uint64(TOTPStep(now)), totpDigits)
+
+	if step, ok := ValidateTOTP(secret, code, now); !ok || step != TOTPStep(now) {
+		t.Errorf("ValidateTOTP() = %d, %t, want %d, true", step, ok, TOTPStep(now))
+	}
+
+	if _, ok := ValidateTOTP(secret, code, now.Add(totpPeriod*time.Second)); !ok {
+		t.Error("ValidateTOTP() rejected a code from the previous step")
+	}
+
+	if _, ok := ValidateTOTP(secret, code, now.Add(3*totpPeriod*time.Second)); ok {
+		t.Error("ValidateTOTP() accepted a code three steps old")
+	}
+
+	if _, ok := ValidateTOTP(secret, "12345", now); ok {
+		t.Error("ValidateTOTP() accepted a code with the wrong length")
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("code %q isn't formatted as xxxxx-xxxxx", code)
+		}
+		seen[code] = true
+	}
+	if len(seen) != 10 {
+		t.Errorf("got %d distinct codes, want 10", len(seen))
+	}
+
+	if HashRecoveryCode(codes[0]) != HashRecoveryCode(strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))) {
+		t.Error("HashRecoveryCode() isn't case and dash insensitive")
+	}
+}
@@ -0,0 +1,145 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Reloader serves a certificate and key pair from disk and swaps it for the
+// new one when the files change, without dropping connections.
+type Reloader struct {
+	certFile, keyFile string
+	logger            *slog.Logger
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	modTimes [2]time.Time
+}
+
+func New(certFile, keyFile string, logger *slog.Logger) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile, logger: logger}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the pair again. The previous certificate stays in use when
+// the new one can't be loaded.
+func (r *Reloader) Reload() error {
+	modTimes, err := r.stat()
+	if err != nil {
+		return fmt.Errorf("certs.Reloader.Reload: %w", err)
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("certs.Reloader.Reload: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTimes = modTimes
+	r.mu.Unlock()
+
+	return nil
+}
+
+// ReloadIfChanged is meant to run periodically, it reloads the pair once
+// either file has a new modification time.
+func (r *Reloader) ReloadIfChanged(ctx context.Context) {
+	modTimes, err := r.stat()
+	if err != nil {
+		r.logger.ErrorContext(ctx, err.Error(), "cause", "check certificate")
+		return
+	}
+
+	r.mu.RLock()
+	changed := modTimes != r.modTimes
+	r.mu.RUnlock()
+	if !changed {
+		return
+	}
+
+	if err := r.Reload(); err != nil {
+		r.logger.ErrorContext(ctx, err.Error(), "cause", "reload certificate")
+		return
+	}
+	r.logger.InfoContext(ctx, "certificate reloaded", "cert_file", r.certFile)
+}
+
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+func (r *Reloader) stat() ([2]time.Time, error) {
+	var modTimes [2]time.Time
+	for i, name := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return modTimes, err
+		}
+		modTimes[i] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// ServerConfig returns the TLS configuration of the API server. With a
+// client CA pool, clients may present a certificate signed by it, which
+// routes can then require.
+func (r *Reloader) ServerConfig(clientCAs *x509.CertPool) *tls.Config {
+	conf := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+
+	if clientCAs != nil {
+		conf.ClientCAs = clientCAs
+		conf.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return conf
+}
+
+func LoadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("certs.LoadCertPool: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("certs.LoadCertPool: %w", errors.New("no certificate found in "+file))
+	}
+
+	return pool, nil
+}
+
+// RedirectHandler sends plain HTTP requests to the same URL over HTTPS on
+// port.
+func RedirectHandler(port uint) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if port != 443 {
+			host = net.JoinHostPort(host, strconv.FormatUint(uint64(port), 10))
+		}
+
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
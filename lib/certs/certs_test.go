@@ -0,0 +1,124 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writePair(t *testing.T, dir string, serial int64, modTime time.Time) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	files := map[string]*pem.Block{
+		certFile: {Type: "CERTIFICATE", Bytes: der},
+		keyFile:  {Type: "EC PRIVATE KEY", Bytes: keyDER},
+	}
+	for name, block := range files {
+		if err := os.WriteFile(name, pem.EncodeToMemory(block), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(name, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return certFile, keyFile
+}
+
+func serialOf(t *testing.T, r *Reloader) int64 {
+	t.Helper()
+
+	cert, err := r.GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.SerialNumber.Int64()
+}
+
+func TestReloadIfChanged(t *testing.T) {
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	start := time.Now().Add(-time.Minute)
+
+	certFile, keyFile := writePair(t, dir, 1, start)
+	r, err := New(certFile, keyFile, logger)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	r.ReloadIfChanged(context.Background())
+	if got := serialOf(t, r); got != 1 {
+		t.Fatalf("serial = %d before the files changed, want 1", got)
+	}
+
+	writePair(t, dir, 2, start.Add(time.Second))
+	r.ReloadIfChanged(context.Background())
+	if got := serialOf(t, r); got != 2 {
+		t.Errorf("serial = %d after the files changed, want 2", got)
+	}
+
+	if err := os.WriteFile(certFile, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r.ReloadIfChanged(context.Background())
+	if got := serialOf(t, r); got != 2 {
+		t.Errorf("serial = %d after a broken reload, want the previous 2", got)
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	cases := []struct {
+		port   uint
+		target string
+		want   string
+	}{
+		{443, "http://example.com/api/v1/photos?page=2", "https://example.com/api/v1/photos?page=2"},
+		{8443, "http://example.com:8080/swagger/", "https://example.com:8443/swagger/"},
+	}
+
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		RedirectHandler(tc.port).ServeHTTP(w, httptest.NewRequest("GET", tc.target, nil))
+
+		if got := w.Header().Get("Location"); got != tc.want {
+			t.Errorf("Location = %q, want %q", got, tc.want)
+		}
+	}
+}
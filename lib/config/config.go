@@ -3,16 +3,59 @@ package config
 import (
 	"encoding/json"
 	"final-project/helper"
+	"final-project/lib/ratelimit"
 	"fmt"
+	"log/slog"
 	"net/url"
 	"os"
 	"regexp"
+	"slices"
+	"strings"
 	"time"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 type Config struct {
-	DB  DB  `json:"db"`
-	App App `json:"app"`
+	DB        DB        `json:"db"`
+	App       App       `json:"app"`
+	RateLimit RateLimit `json:"rate_limit"`
+	Mail      Mail      `json:"mail"`
+	Log       Log       `json:"log"`
+	Tracing   Tracing   `json:"tracing"`
+}
+
+// Tracing selects where spans are exported: nowhere, stdout or an OTLP/HTTP
+// collector at Endpoint. SampleRatio is the share of new traces recorded,
+// traces started by a caller follow the caller's decision.
+type Tracing struct {
+	Exporter    string  `json:"exporter"`
+	Endpoint    string  `json:"endpoint"`
+	Insecure    bool    `json:"insecure"`
+	ServiceName string  `json:"service_name"`
+	SampleRatio float64 `json:"sample_ratio"`
+}
+
+// Log selects the log format and level and where logs go. File is rotated
+// once it reaches MaxSizeMB, keeping MaxBackups old files. Without any sink
+// logs go to stderr.
+type Log struct {
+	Format     string `json:"format"`
+	LevelStr   string `json:"level"`
+	AddSource  bool   `json:"add_source"`
+	Stderr     bool   `json:"stderr"`
+	File       string `json:"file"`
+	MaxSizeMB  int    `json:"max_size_mb"`
+	MaxBackups int    `json:"max_backups"`
+	Level      slog.Level
+}
+
+type Mail struct {
+	Host     string `json:"host"`
+	Port     uint   `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	From     string `json:"from"`
 }
 
 type DB struct {
@@ -34,11 +77,306 @@ func (db DB) ConnectionString() string {
 }
 
 type App struct {
-	Host         string `json:"host"`
-	Port         uint   `json:"port"`
-	JWTSecret    string `json:"jwt_secret"`
-	JWTExpiresIn string `json:"jwt_expires_in"`
-	BasePath     string `json:"base_path"`
+	Host           string `json:"host"`
+	Port           uint   `json:"port"`
+	JWTSecret      string `json:"jwt_secret"`
+	JWTExpiresIn   string `json:"jwt_expires_in"`
+	JWTIssuer      string `json:"jwt_issuer"`
+	JWTAudience    string `json:"jwt_audience"`
+	BasePath       string `json:"base_path"`
+	RequireIfMatch bool   `json:"require_if_match"`
+	ExportDir      string `json:"export_dir"`
+	Workers        int    `json:"workers"`
+	WorkerQueue    int    `json:"worker_queue"`
+
+	// ExportTimeout is how long an export may go without progress before
+	// it's failed, like one that was being built when the server crashed.
+	ExportTimeoutStr string `json:"export_timeout"`
+	ExportTimeout    time.Duration
+
+	// ExportRetention is how long finished exports and their archives are
+	// kept before they're deleted.
+	ExportRetentionStr string `json:"export_retention"`
+	ExportRetention    time.Duration
+
+	// ExportDownloadTimeout replaces WriteTimeout for export downloads,
+	// archives can take longer than an API response to send.
+	ExportDownloadTimeoutStr string `json:"export_download_timeout"`
+	ExportDownloadTimeout    time.Duration
+
+	AccountDeletionDelayStr string `json:"account_deletion_delay"`
+	AccountPurgeIntervalStr string `json:"account_purge_interval"`
+	AnonymizeComments       bool   `json:"anonymize_comments"`
+	AccountDeletionDelay    time.Duration
+	AccountPurgeInterval    time.Duration
+
+	TrustProxy   bool         `json:"trust_proxy"`
+	LoginLockout LoginLockout `json:"login_lockout"`
+	TOTPIssuer   string       `json:"totp_issuer"`
+	JWTSigning   JWTSigning   `json:"jwt_signing"`
+
+	OIDCProviders map[string]OIDCProvider `json:"oidc_providers"`
+
+	PasswordHashing PasswordHashing `json:"password_hashing"`
+	PasswordPolicy  PasswordPolicy  `json:"password_policy"`
+
+	CORS            CORS            `json:"cors"`
+	SecurityHeaders SecurityHeaders `json:"security_headers"`
+
+	// TLS is served when both files are set, they're reloaded when they
+	// change or on SIGHUP. With AdminMTLS, admin routes also need a client
+	// certificate signed by TLSClientCAFile. HTTPRedirectAddr optionally
+	// listens for plain HTTP and redirects it to HTTPS.
+	TLSCertFile          string `json:"tls_cert_file"`
+	TLSKeyFile           string `json:"tls_key_file"`
+	TLSClientCAFile      string `json:"tls_client_ca_file"`
+	TLSReloadIntervalStr string `json:"tls_reload_interval"`
+	AdminMTLS            bool   `json:"admin_mtls"`
+	HTTPRedirectAddr     string `json:"http_redirect_addr"`
+	TLSReloadInterval    time.Duration
+
+	// MetricsAddr serves /metrics on a listener of its own, meant for a
+	// network only the scraper can reach. When it's empty /metrics is served
+	// next to the API and needs an admin login.
+	MetricsAddr string `json:"metrics_addr"`
+
+	// On shutdown /readyz fails for ReadinessDrainDelay before the server
+	// stops accepting requests, so load balancers can drain it first.
+	ReadinessTimeoutStr    string `json:"readiness_timeout"`
+	ReadinessDrainDelayStr string `json:"readiness_drain_delay"`
+	ReadinessTimeout       time.Duration
+	ReadinessDrainDelay    time.Duration
+
+	// Timeouts of the HTTP server, 0 disables all but ReadHeaderTimeout.
+	// ShutdownTimeout bounds how long stopping each of the server, the
+	// workers and the database may take.
+	ReadTimeoutStr       string `json:"read_timeout"`
+	ReadHeaderTimeoutStr string `json:"read_header_timeout"`
+	WriteTimeoutStr      string `json:"write_timeout"`
+	IdleTimeoutStr       string `json:"idle_timeout"`
+	ShutdownTimeoutStr   string `json:"shutdown_timeout"`
+	ReadTimeout          time.Duration
+	ReadHeaderTimeout    time.Duration
+	WriteTimeout         time.Duration
+	IdleTimeout          time.Duration
+	ShutdownTimeout      time.Duration
+}
+
+func (app App) TLSEnabled() bool {
+	return app.TLSCertFile != ""
+}
+
+// CORS lets browser clients on other origins call the API. The API is
+// authenticated with bearer tokens, so credentials are only needed for the
+// OIDC state cookie and can't be combined with allowing every origin.
+type CORS struct {
+	AllowedOrigins   []string `json:"allowed_origins"`
+	AllowedMethods   []string `json:"allowed_methods"`
+	AllowedHeaders   []string `json:"allowed_headers"`
+	ExposedHeaders   []string `json:"exposed_headers"`
+	AllowCredentials bool     `json:"allow_credentials"`
+	MaxAgeStr        string   `json:"max_age"`
+	MaxAge           time.Duration
+}
+
+type SecurityHeaders struct {
+	HSTSMaxAgeStr                string `json:"hsts_max_age"`
+	HSTSIncludeSubdomains        bool   `json:"hsts_include_subdomains"`
+	FrameOptions                 string `json:"frame_options"`
+	ContentSecurityPolicy        string `json:"content_security_policy"`
+	SwaggerContentSecurityPolicy string `json:"swagger_content_security_policy"`
+	HSTSMaxAge                   time.Duration
+}
+
+// PasswordHashing selects how new password hashes are made. Hashes made
+// with other settings are upgraded when their owner logs in.
+type PasswordHashing struct {
+	Algorithm     string `json:"algorithm"`
+	BcryptCost    int    `json:"bcrypt_cost"`
+	Argon2Time    uint32 `json:"argon2_time"`
+	Argon2Memory  uint32 `json:"argon2_memory_kib"`
+	Argon2Threads uint8  `json:"argon2_threads"`
+}
+
+func (h PasswordHashing) Params() helper.PasswordParams {
+	return helper.PasswordParams{
+		Algorithm:     h.Algorithm,
+		BcryptCost:    h.BcryptCost,
+		Argon2Time:    h.Argon2Time,
+		Argon2Memory:  h.Argon2Memory,
+		Argon2Threads: h.Argon2Threads,
+	}
+}
+
+// PasswordPolicy is checked when a password is set. CommonPasswordsFile
+// adds a list of known passwords, one per line, to the built-in one.
+type PasswordPolicy struct {
+	MinLength           int    `json:"min_length"`
+	CommonPasswordsFile string `json:"common_passwords_file"`
+}
+
+func (p PasswordPolicy) Policy() (helper.PasswordPolicy, error) {
+	var extra []string
+
+	if p.CommonPasswordsFile != "" {
+		data, err := os.ReadFile(p.CommonPasswordsFile)
+		if err != nil {
+			return helper.PasswordPolicy{}, fmt.Errorf("config.PasswordPolicy.Policy: %w", err)
+		}
+		extra = strings.Split(string(data), "\n")
+	}
+
+	return helper.NewPasswordPolicy(p.MinLength, extra), nil
+}
+
+type OIDCProvider struct {
+	Issuer       string   `json:"issuer"`
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret"`
+	RedirectURL  string   `json:"redirect_url"`
+	Scopes       []string `json:"scopes"`
+}
+
+// JWTSigning selects how tokens are signed. HS256 keeps using jwt_secret,
+// RS256 and EdDSA sign with private_key_file and also accept tokens signed by
+// any of the verification keys, so a retired key can stay listed until the
+// tokens it signed expire.
+type JWTSigning struct {
+	Algorithm        string            `json:"algorithm"`
+	KeyID            string            `json:"key_id"`
+	PrivateKeyFile   string            `json:"private_key_file"`
+	VerificationKeys []VerificationKey `json:"verification_keys"`
+}
+
+type VerificationKey struct {
+	KeyID         string `json:"key_id"`
+	PublicKeyFile string `json:"public_key_file"`
+}
+
+func (app App) KeySet() (*helper.KeySet, error) {
+	var (
+		signing = app.JWTSigning
+		keys    []helper.Key
+	)
+
+	if signing.Algorithm == "HS256" {
+		return helper.NewKeySet(helper.NewHMACKey([]byte(app.JWTSecret))), nil
+	}
+
+	pemData, err := os.ReadFile(signing.PrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("config.App.KeySet: %w", err)
+	}
+
+	signingKey, err := helper.NewPrivateKey(signing.Algorithm, signing.KeyID, pemData)
+	if err != nil {
+		return nil, fmt.Errorf("config.App.KeySet: %w", err)
+	}
+
+	for _, v := range signing.VerificationKeys {
+		pemData, err := os.ReadFile(v.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("config.App.KeySet: %w", err)
+		}
+
+		key, err := helper.NewPublicKey(v.KeyID, pemData)
+		if err != nil {
+			return nil, fmt.Errorf("config.App.KeySet: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return helper.NewKeySet(signingKey, keys...), nil
+}
+
+type LoginLockout struct {
+	MaxFailures   uint64 `json:"max_failures"`
+	DurationStr   string `json:"duration"`
+	IPMaxFailures uint64 `json:"ip_max_failures"`
+	IPWindowStr   string `json:"ip_window"`
+	DelayBaseStr  string `json:"delay_base"`
+	DelayMaxStr   string `json:"delay_max"`
+	Duration      time.Duration
+	IPWindow      time.Duration
+	DelayBase     time.Duration
+	DelayMax      time.Duration
+}
+
+type RateLimit struct {
+	Backend string `json:"backend"`
+	TTLStr  string `json:"ttl"`
+	TTL     time.Duration
+	RateLimitRule
+	Roles  map[string]RateLimitRule  `json:"roles"`
+	Routes map[string]RateLimitRoute `json:"routes"`
+}
+
+type RateLimitRule struct {
+	Burst uint64  `json:"burst"`
+	Rate  float64 `json:"rate"`
+}
+
+type RateLimitRoute struct {
+	RateLimitRule
+	Roles map[string]RateLimitRule `json:"roles"`
+}
+
+func (rule RateLimitRule) isValid() bool {
+	return rule.Burst > 0 && rule.Rate > 0
+}
+
+func (rule RateLimitRule) limit() ratelimit.Limit {
+	return ratelimit.Limit{Burst: rule.Burst, Rate: rule.Rate}
+}
+
+func (rl RateLimit) validate() error {
+	if !rl.isValid() {
+		return helper.ErrInvalidRateLimitRule
+	}
+
+	for _, rule := range rl.Roles {
+		if !rule.isValid() {
+			return helper.ErrInvalidRateLimitRule
+		}
+	}
+
+	for _, route := range rl.Routes {
+		if !route.isValid() {
+			return helper.ErrInvalidRateLimitRule
+		}
+		for _, rule := range route.Roles {
+			if !rule.isValid() {
+				return helper.ErrInvalidRateLimitRule
+			}
+		}
+	}
+
+	return nil
+}
+
+func (rl RateLimit) Policy() ratelimit.Policy {
+	policy := ratelimit.Policy{
+		Limit:  rl.limit(),
+		Roles:  make(map[string]ratelimit.Limit, len(rl.Roles)),
+		Routes: make(map[string]ratelimit.Policy, len(rl.Routes)),
+	}
+
+	for role, rule := range rl.Roles {
+		policy.Roles[role] = rule.limit()
+	}
+
+	for pattern, route := range rl.Routes {
+		routePolicy := ratelimit.Policy{
+			Limit: route.limit(),
+			Roles: make(map[string]ratelimit.Limit, len(route.Roles)),
+		}
+		for role, rule := range route.Roles {
+			routePolicy.Roles[role] = rule.limit()
+		}
+		policy.Routes[pattern] = routePolicy
+	}
+
+	return policy
 }
 
 func (app App) isValidBasePath() bool {
@@ -63,6 +401,55 @@ func Load(path string) (Config, error) {
 		return conf, fmt.Errorf("config.Load: %w", err)
 	}
 
+	switch conf.Log.Format {
+	case "":
+		conf.Log.Format = "text"
+	case "text", "json":
+	default:
+		return conf, fmt.Errorf("config.Load: %w", helper.ErrInvalidLogFormat)
+	}
+
+	if conf.Log.LevelStr != "" {
+		if err := conf.Log.Level.UnmarshalText([]byte(conf.Log.LevelStr)); err != nil {
+			return conf, fmt.Errorf("config.Load: %w", helper.ErrInvalidLogLevel)
+		}
+	}
+
+	if !conf.Log.Stderr && conf.Log.File == "" {
+		conf.Log.Stderr = true
+	}
+
+	if conf.Log.MaxSizeMB <= 0 {
+		conf.Log.MaxSizeMB = 100
+	}
+
+	if conf.Log.MaxBackups < 0 {
+		conf.Log.MaxBackups = 0
+	}
+
+	switch conf.Tracing.Exporter {
+	case "":
+		conf.Tracing.Exporter = "none"
+	case "none", "stdout", "otlp":
+	default:
+		return conf, fmt.Errorf("config.Load: %w", helper.ErrInvalidTracingExporter)
+	}
+
+	if conf.Tracing.Endpoint == "" {
+		conf.Tracing.Endpoint = "localhost:4318"
+	}
+
+	if conf.Tracing.ServiceName == "" {
+		conf.Tracing.ServiceName = "mygram"
+	}
+
+	if conf.Tracing.SampleRatio < 0 || conf.Tracing.SampleRatio > 1 {
+		return conf, fmt.Errorf("config.Load: %w", helper.ErrInvalidSampleRatio)
+	}
+	if conf.Tracing.SampleRatio == 0 {
+		conf.Tracing.SampleRatio = 1
+	}
+
 	if !conf.App.isValidBasePath() {
 		return conf, fmt.Errorf("config.Load: %w", helper.ErrInvalidBasePath)
 	}
@@ -77,5 +464,251 @@ func Load(path string) (Config, error) {
 		return conf, fmt.Errorf("config.Load: %w", helper.ErrInvalidDuration)
 	}
 
+	conf.App.AccountDeletionDelay, err = parseDuration(conf.App.AccountDeletionDelayStr, 30*24*time.Hour)
+	if err != nil {
+		return conf, fmt.Errorf("config.Load: %w", err)
+	}
+
+	conf.App.AccountPurgeInterval, err = parseDuration(conf.App.AccountPurgeIntervalStr, time.Hour)
+	if err != nil || conf.App.AccountPurgeInterval <= 0 {
+		return conf, fmt.Errorf("config.Load: %w", helper.ErrInvalidDuration)
+	}
+
+	switch conf.App.JWTSigning.Algorithm {
+	case "":
+		conf.App.JWTSigning.Algorithm = "HS256"
+	case "HS256", "RS256", "EdDSA":
+	default:
+		return conf, fmt.Errorf("config.Load: %w", helper.ErrUnsupportedAlgorithm)
+	}
+
+	for _, p := range conf.App.OIDCProviders {
+		if p.Issuer == "" || p.ClientID == "" || p.RedirectURL == "" {
+			return conf, fmt.Errorf("config.Load: %w", helper.ErrInvalidOIDCProvider)
+		}
+	}
+
+	if conf.App.JWTIssuer == "" {
+		conf.App.JWTIssuer = "mygram"
+	}
+
+	if conf.App.JWTAudience == "" {
+		conf.App.JWTAudience = "mygram-api"
+	}
+
+	hashing := &conf.App.PasswordHashing
+	if hashing.Algorithm == "" {
+		hashing.Algorithm = helper.PasswordBcrypt
+	}
+
+	if hashing.BcryptCost == 0 {
+		hashing.BcryptCost = 12
+	}
+
+	if hashing.Argon2Time == 0 {
+		hashing.Argon2Time = 3
+	}
+
+	if hashing.Argon2Memory == 0 {
+		hashing.Argon2Memory = 64 * 1024
+	}
+
+	if hashing.Argon2Threads == 0 {
+		hashing.Argon2Threads = 2
+	}
+
+	switch {
+	case hashing.Algorithm != helper.PasswordBcrypt && hashing.Algorithm != helper.PasswordArgon2id,
+		hashing.BcryptCost < bcrypt.MinCost || hashing.BcryptCost > bcrypt.MaxCost,
+		hashing.Argon2Memory < 8*uint32(hashing.Argon2Threads):
+		return conf, fmt.Errorf("config.Load: %w", helper.ErrInvalidPasswordHashing)
+	}
+
+	if conf.App.PasswordPolicy.MinLength <= 0 {
+		conf.App.PasswordPolicy.MinLength = 8
+	}
+
+	cors := &conf.App.CORS
+	if cors.AllowCredentials && slices.Contains(cors.AllowedOrigins, "*") {
+		return conf, fmt.Errorf("config.Load: %w", helper.ErrInvalidCORS)
+	}
+
+	if len(cors.AllowedMethods) == 0 {
+		cors.AllowedMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+	}
+
+	if len(cors.AllowedHeaders) == 0 {
+		cors.AllowedHeaders = []string{"Authorization", "Content-Type", "If-Match", "X-Request-ID"}
+	}
+
+	if len(cors.ExposedHeaders) == 0 {
+		cors.ExposedHeaders = []string{"ETag", "Location", "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "X-Request-ID"}
+	}
+
+	cors.MaxAge, err = parseDuration(cors.MaxAgeStr, 10*time.Minute)
+	if err != nil {
+		return conf, fmt.Errorf("config.Load: %w", err)
+	}
+
+	headers := &conf.App.SecurityHeaders
+	headers.HSTSMaxAge, err = parseDuration(headers.HSTSMaxAgeStr, 0)
+	if err != nil {
+		return conf, fmt.Errorf("config.Load: %w", err)
+	}
+
+	if headers.FrameOptions == "" {
+		headers.FrameOptions = "DENY"
+	}
+
+	if headers.ContentSecurityPolicy == "" {
+		headers.ContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+	}
+
+	if headers.SwaggerContentSecurityPolicy == "" {
+		headers.SwaggerContentSecurityPolicy = "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; img-src 'self' data:; frame-ancestors 'none'"
+	}
+
+	if (conf.App.TLSCertFile == "") != (conf.App.TLSKeyFile == "") {
+		return conf, fmt.Errorf("config.Load: %w", helper.ErrInvalidTLS)
+	}
+
+	if !conf.App.TLSEnabled() && (conf.App.AdminMTLS || conf.App.HTTPRedirectAddr != "") {
+		return conf, fmt.Errorf("config.Load: %w", helper.ErrTLSRequired)
+	}
+
+	if conf.App.AdminMTLS && conf.App.TLSClientCAFile == "" {
+		return conf, fmt.Errorf("config.Load: %w", helper.ErrClientCARequired)
+	}
+
+	conf.App.TLSReloadInterval, err = parseDuration(conf.App.TLSReloadIntervalStr, time.Minute)
+	if err != nil || conf.App.TLSReloadInterval <= 0 {
+		return conf, fmt.Errorf("config.Load: %w", helper.ErrInvalidDuration)
+	}
+
+	if conf.App.TOTPIssuer == "" {
+		conf.App.TOTPIssuer = "MyGram"
+	}
+
+	lockout := &conf.App.LoginLockout
+	if lockout.MaxFailures == 0 {
+		lockout.MaxFailures = 5
+	}
+
+	if lockout.IPMaxFailures == 0 {
+		lockout.IPMaxFailures = 50
+	}
+
+	for _, d := range []struct {
+		dst      *time.Duration
+		src      string
+		fallback time.Duration
+	}{
+		{&lockout.Duration, lockout.DurationStr, 15 * time.Minute},
+		{&lockout.IPWindow, lockout.IPWindowStr, 15 * time.Minute},
+		{&lockout.DelayBase, lockout.DelayBaseStr, 250 * time.Millisecond},
+		{&lockout.DelayMax, lockout.DelayMaxStr, 5 * time.Second},
+	} {
+		*d.dst, err = parseDuration(d.src, d.fallback)
+		if err != nil {
+			return conf, fmt.Errorf("config.Load: %w", err)
+		}
+	}
+
+	if lockout.IPWindow <= 0 {
+		return conf, fmt.Errorf("config.Load: %w", helper.ErrInvalidDuration)
+	}
+
+	switch conf.RateLimit.Backend {
+	case "":
+		conf.RateLimit.Backend = "memory"
+	case "memory", "postgres":
+	default:
+		return conf, fmt.Errorf("config.Load: %w", helper.ErrInvalidRateLimitBackend)
+	}
+
+	if conf.RateLimit.Burst == 0 && conf.RateLimit.Rate == 0 {
+		conf.RateLimit.RateLimitRule = RateLimitRule{Burst: 100, Rate: 5}
+	}
+
+	if err := conf.RateLimit.validate(); err != nil {
+		return conf, fmt.Errorf("config.Load: %w", err)
+	}
+
+	conf.RateLimit.TTL, err = parseDuration(conf.RateLimit.TTLStr, time.Hour)
+	if err != nil || conf.RateLimit.TTL <= 0 {
+		return conf, fmt.Errorf("config.Load: %w", helper.ErrInvalidDuration)
+	}
+
+	if conf.App.ExportDir == "" {
+		conf.App.ExportDir = "exports"
+	}
+
+	if conf.App.Workers <= 0 {
+		conf.App.Workers = 2
+	}
+
+	if conf.App.WorkerQueue <= 0 {
+		conf.App.WorkerQueue = 100
+	}
+
+	conf.App.ExportTimeout, err = parseDuration(conf.App.ExportTimeoutStr, time.Hour)
+	if err != nil || conf.App.ExportTimeout <= 0 {
+		return conf, fmt.Errorf("config.Load: %w", helper.ErrInvalidDuration)
+	}
+
+	conf.App.ExportRetention, err = parseDuration(conf.App.ExportRetentionStr, 7*24*time.Hour)
+	if err != nil || conf.App.ExportRetention <= 0 {
+		return conf, fmt.Errorf("config.Load: %w", helper.ErrInvalidDuration)
+	}
+
+	conf.App.ExportDownloadTimeout, err = parseDuration(conf.App.ExportDownloadTimeoutStr, 30*time.Minute)
+	if err != nil || conf.App.ExportDownloadTimeout <= 0 {
+		return conf, fmt.Errorf("config.Load: %w", helper.ErrInvalidDuration)
+	}
+
+	conf.App.ReadinessTimeout, err = parseDuration(conf.App.ReadinessTimeoutStr, 2*time.Second)
+	if err != nil || conf.App.ReadinessTimeout <= 0 {
+		return conf, fmt.Errorf("config.Load: %w", helper.ErrInvalidDuration)
+	}
+
+	conf.App.ReadinessDrainDelay, err = parseDuration(conf.App.ReadinessDrainDelayStr, 5*time.Second)
+	if err != nil {
+		return conf, fmt.Errorf("config.Load: %w", err)
+	}
+
+	for _, d := range []struct {
+		dst      *time.Duration
+		src      string
+		fallback time.Duration
+	}{
+		{&conf.App.ReadTimeout, conf.App.ReadTimeoutStr, 30 * time.Second},
+		{&conf.App.ReadHeaderTimeout, conf.App.ReadHeaderTimeoutStr, 5 * time.Second},
+		{&conf.App.WriteTimeout, conf.App.WriteTimeoutStr, time.Minute},
+		{&conf.App.IdleTimeout, conf.App.IdleTimeoutStr, 2 * time.Minute},
+		{&conf.App.ShutdownTimeout, conf.App.ShutdownTimeoutStr, 30 * time.Second},
+	} {
+		*d.dst, err = parseDuration(d.src, d.fallback)
+		if err != nil {
+			return conf, fmt.Errorf("config.Load: %w", err)
+		}
+	}
+
+	if conf.App.ReadHeaderTimeout <= 0 || conf.App.ShutdownTimeout <= 0 {
+		return conf, fmt.Errorf("config.Load: %w", helper.ErrInvalidDuration)
+	}
+
 	return conf, nil
 }
+
+func parseDuration(s string, fallback time.Duration) (time.Duration, error) {
+	if s == "" {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, helper.ErrInvalidDuration
+	}
+
+	return d, nil
+}
@@ -1,6 +1,9 @@
 package config
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 type testcase struct {
 	in  string
@@ -42,3 +45,29 @@ func TestValidBasePath(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDuration(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"", "1h0m0s", false},
+		{"0s", "0s", false},
+		{"720h", "720h0m0s", false},
+		{"-1h", "", true},
+		{"soon", "", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := parseDuration(tc.in, time.Hour)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseDuration(%q) error = %v, wantErr %t", tc.in, err, tc.wantErr)
+			}
+			if err == nil && got.String() != tc.want {
+				t.Errorf("parseDuration(%q) = %s, want %s", tc.in, got, tc.want)
+			}
+		})
+	}
+}
@@ -1,17 +1,24 @@
 package database
 
 import (
+	"context"
+	"crypto/sha256"
 	"database/sql"
 	_ "embed"
+	"errors"
 	"final-project/lib/config"
+	"final-project/lib/tracing"
 	"fmt"
+
+	"github.com/lib/pq"
 )
 
 func New(conf config.DB) (*sql.DB, error) {
-	db, err := sql.Open("postgres", conf.ConnectionString())
+	connector, err := pq.NewConnector(conf.ConnectionString())
 	if err != nil {
 		return nil, fmt.Errorf("database.New: %w", err)
 	}
+	db := sql.OpenDB(tracing.WrapConnector(connector))
 
 	db.SetMaxIdleConns(conf.MaxIdleConns)
 	db.SetMaxOpenConns(conf.MaxOpenConns)
@@ -22,17 +29,42 @@ func New(conf config.DB) (*sql.DB, error) {
 		return nil, fmt.Errorf("database.New: %w", err)
 	}
 
-	if err := createTables(db); err != nil {
+	if err := Migrate(db); err != nil {
 		return nil, fmt.Errorf("database.New: %w", err)
 	}
 
 	return db, nil
 }
 
+var ErrSchemaNotApplied = errors.New("database schema of this version hasn't been applied")
+
 //go:embed db.sql
 var q string
 
-func createTables(db *sql.DB) error {
-	_, err := db.Exec(q)
+var schemaChecksum = fmt.Sprintf("%x", sha256.Sum256([]byte(q)))
+
+// Migrate applies the schema embedded in this binary to db, every statement
+// in it is idempotent.
+func Migrate(db *sql.DB) error {
+	if _, err := db.Exec(q); err != nil {
+		return err
+	}
+
+	_, err := db.Exec(`INSERT INTO schema_migration(checksum) VALUES($1) ON CONFLICT DO NOTHING`, schemaChecksum)
 	return err
 }
+
+// CheckSchema reports whether the schema embedded in this binary has been
+// applied to db.
+func CheckSchema(ctx context.Context, db *sql.DB) error {
+	var applied bool
+	err := db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM schema_migration WHERE checksum = $1)`, schemaChecksum).Scan(&applied)
+	if err != nil {
+		return fmt.Errorf("database.CheckSchema: %w", err)
+	}
+	if !applied {
+		return fmt.Errorf("database.CheckSchema: %w", ErrSchemaNotApplied)
+	}
+
+	return nil
+}
@@ -57,4 +57,186 @@ CREATE TABLE IF NOT EXISTS like_ (
     photo_id INTEGER REFERENCES photo(id) ON DELETE CASCADE,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     UNIQUE(user_id, photo_id)
-);
\ No newline at end of file
+);
+
+-- photo and comment edit history
+ALTER TABLE photo ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP;
+ALTER TABLE comment ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP;
+
+-- CREATE photo_revision TABLE
+CREATE TABLE IF NOT EXISTS photo_revision (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    photo_id INTEGER REFERENCES photo(id) ON DELETE CASCADE,
+    editor_id INTEGER REFERENCES user_(id) ON DELETE CASCADE,
+    title VARCHAR(100) NOT NULL,
+    caption TEXT,
+    url TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_photo_revision_photo_id ON photo_revision(photo_id);
+
+-- CREATE comment_revision TABLE
+CREATE TABLE IF NOT EXISTS comment_revision (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    comment_id INTEGER REFERENCES comment(id) ON DELETE CASCADE,
+    editor_id INTEGER REFERENCES user_(id) ON DELETE CASCADE,
+    message TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_revision_comment_id ON comment_revision(comment_id);
+
+-- CREATE user_export TABLE
+CREATE TABLE IF NOT EXISTS user_export (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id INTEGER REFERENCES user_(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    file_path TEXT,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_export_user_id ON user_export(user_id);
+
+-- delayed account deletion
+ALTER TABLE user_ ADD COLUMN IF NOT EXISTS delete_after TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_user_delete_after ON user_(delete_after);
+
+-- CREATE rate_limit_bucket TABLE
+CREATE TABLE IF NOT EXISTS rate_limit_bucket (
+    key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    allowed BOOLEAN NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_bucket_updated_at ON rate_limit_bucket(updated_at);
+
+-- user roles
+ALTER TABLE user_ ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
+
+-- login lockout
+ALTER TABLE user_ ADD COLUMN IF NOT EXISTS failed_logins INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_ ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP;
+
+-- CREATE login_failure TABLE
+CREATE TABLE IF NOT EXISTS login_failure (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id INTEGER REFERENCES user_(id) ON DELETE CASCADE,
+    ip TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_failure_ip_created_at ON login_failure(ip, created_at);
+
+-- two-factor authentication
+ALTER TABLE user_ ADD COLUMN IF NOT EXISTS totp_secret TEXT;
+ALTER TABLE user_ ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE user_ ADD COLUMN IF NOT EXISTS totp_last_step BIGINT;
+
+-- CREATE recovery_code TABLE
+CREATE TABLE IF NOT EXISTS recovery_code (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id INTEGER REFERENCES user_(id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, code_hash)
+);
+
+-- CREATE user_identity TABLE
+CREATE TABLE IF NOT EXISTS user_identity (
+    provider VARCHAR(50) NOT NULL,
+    subject TEXT NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES user_(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY(provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identity_user_id ON user_identity(user_id);
+
+-- CREATE api_token TABLE
+CREATE TABLE IF NOT EXISTS api_token (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES user_(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(20) NOT NULL,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    scopes TEXT[] NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_token_user_id ON api_token(user_id);
+
+-- CREATE user_session TABLE
+CREATE TABLE IF NOT EXISTS user_session (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES user_(id) ON DELETE CASCADE,
+    user_agent TEXT NOT NULL,
+    ip TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_session_user_id ON user_session(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_session_expires_at ON user_session(expires_at);
+
+-- CREATE audit_event TABLE
+-- rows outlive the accounts they mention, so there are no foreign keys
+CREATE TABLE IF NOT EXISTS audit_event (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    actor_id INTEGER,
+    user_id INTEGER,
+    action VARCHAR(50) NOT NULL,
+    target_type VARCHAR(50) NOT NULL DEFAULT '',
+    target_id TEXT NOT NULL DEFAULT '',
+    ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    metadata JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_event_user_id ON audit_event(user_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_event_actor_id ON audit_event(actor_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_event_action ON audit_event(action, id);
+
+CREATE OR REPLACE FUNCTION audit_event_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_event is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_event_append_only ON audit_event;
+CREATE TRIGGER audit_event_append_only
+    BEFORE UPDATE OR DELETE ON audit_event
+    FOR EACH ROW EXECUTE FUNCTION audit_event_append_only();
+
+-- argon2id hashes are longer than bcrypt ones
+ALTER TABLE user_ ALTER COLUMN password TYPE TEXT;
+
+-- one row per version of this file applied, checked by the readiness probe
+CREATE TABLE IF NOT EXISTS schema_migration (
+    checksum CHAR(64) PRIMARY KEY,
+    applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- lets stuck exports be told apart from running ones
+ALTER TABLE user_export ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_user_export_status ON user_export(status, updated_at);
+
+-- only verified addresses are matched to OIDC identities, changing the email drops it
+ALTER TABLE user_ ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- one export in progress per user, older duplicates are failed so the index can be built
+UPDATE user_export SET status='failed', error='superseded by a newer export', completed_at=NOW(), updated_at=NOW()
+WHERE status IN ('pending', 'processing')
+    AND id NOT IN (SELECT MAX(id) FROM user_export WHERE status IN ('pending', 'processing') GROUP BY user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_export_active ON user_export(user_id) WHERE status IN ('pending', 'processing');
+CREATE INDEX IF NOT EXISTS idx_user_export_completed_at ON user_export(completed_at) WHERE completed_at IS NOT NULL;
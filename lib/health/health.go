@@ -0,0 +1,72 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrDraining = errors.New("server is shutting down")
+
+type Check func(context.Context) error
+
+// Checker runs the readiness checks of the server's dependencies.
+type Checker struct {
+	timeout  time.Duration
+	names    []string
+	checks   []Check
+	draining atomic.Bool
+}
+
+func New(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Add registers a check, it's meant to be called before serving.
+func (c *Checker) Add(name string, check Check) {
+	c.names = append(c.names, name)
+	c.checks = append(c.checks, check)
+}
+
+// Drain makes Ready fail from now on, so load balancers stop sending
+// traffic before the server shuts down.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Ready runs the checks concurrently, each bounded by the timeout, and
+// returns their errors by name, nil for the ones that passed. While
+// draining, a failing "shutdown" check is added.
+func (c *Checker) Ready(ctx context.Context) (map[string]error, bool) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	errs := make([]error, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			errs[i] = check(ctx)
+		}(i, check)
+	}
+	wg.Wait()
+
+	results := make(map[string]error, len(c.checks)+1)
+	ok := true
+	for i, name := range c.names {
+		results[name] = errs[i]
+		if errs[i] != nil {
+			ok = false
+		}
+	}
+
+	if c.draining.Load() {
+		results["shutdown"] = ErrDraining
+		ok = false
+	}
+
+	return results, ok
+}
@@ -0,0 +1,43 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReady(t *testing.T) {
+	c := New(50 * time.Millisecond)
+	c.Add("database", func(context.Context) error { return nil })
+	c.Add("workers", func(context.Context) error { return nil })
+
+	results, ok := c.Ready(context.Background())
+	if !ok || len(results) != 2 {
+		t.Fatalf("Ready() = %v, %v, want two passing checks", results, ok)
+	}
+
+	c.Add("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	results, ok = c.Ready(context.Background())
+	if ok || !errors.Is(results["slow"], context.DeadlineExceeded) {
+		t.Errorf("Ready() = %v, %v, want the slow check to time out", results, ok)
+	}
+	if results["database"] != nil {
+		t.Errorf("database = %v, want it to pass", results["database"])
+	}
+}
+
+func TestReadyWhileDraining(t *testing.T) {
+	c := New(time.Second)
+	c.Add("database", func(context.Context) error { return nil })
+	c.Drain()
+
+	results, ok := c.Ready(context.Background())
+	if ok || !errors.Is(results["shutdown"], ErrDraining) {
+		t.Errorf("Ready() = %v, %v, want it to fail while draining", results, ok)
+	}
+}
@@ -0,0 +1,74 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type hook struct {
+	name string
+	stop func(context.Context) error
+}
+
+// Manager stops the parts of the server in the reverse order they were
+// registered in, like deferred calls, so everything is stopped before what
+// it depends on: the HTTP server before the workers, the workers before the
+// database.
+type Manager struct {
+	logger *slog.Logger
+
+	mu    sync.Mutex
+	hooks []hook
+	done  bool
+}
+
+func New(logger *slog.Logger) *Manager {
+	return &Manager{logger: logger}
+}
+
+// OnShutdown registers stop to be called by Shutdown.
+func (m *Manager) OnShutdown(name string, stop func(context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook{name, stop})
+}
+
+// Shutdown calls the registered hooks once, each getting timeout of its
+// own so a slow one doesn't use up the time of those after it. A hook that
+// fails doesn't keep the following ones from running, the errors are
+// logged and returned together.
+func (m *Manager) Shutdown(ctx context.Context, timeout time.Duration) error {
+	m.mu.Lock()
+	if m.done {
+		m.mu.Unlock()
+		return nil
+	}
+	m.done = true
+	hooks := m.hooks
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := m.stop(ctx, hooks[i], timeout); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (m *Manager) stop(ctx context.Context, h hook, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	m.logger.InfoContext(ctx, "stopping", "component", h.name)
+	if err := h.stop(ctx); err != nil {
+		m.logger.ErrorContext(ctx, err.Error(), "component", h.name)
+		return fmt.Errorf("%s: %w", h.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestShutdownRunsHooksInReverse(t *testing.T) {
+	m := New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	var stopped []string
+	errWorkers := errors.New("jobs still running")
+	for _, name := range []string{"database", "workers", "server"} {
+		m.OnShutdown(name, func(context.Context) error {
+			stopped = append(stopped, name)
+			if name == "workers" {
+				return errWorkers
+			}
+			return nil
+		})
+	}
+
+	err := m.Shutdown(context.Background(), time.Second)
+	if !errors.Is(err, errWorkers) {
+		t.Errorf("Shutdown() = %v, want %v", err, errWorkers)
+	}
+	if want := []string{"server", "workers", "database"}; !slices.Equal(stopped, want) {
+		t.Errorf("stopped %v, want %v", stopped, want)
+	}
+
+	if err := m.Shutdown(context.Background(), time.Second); err != nil || len(stopped) != 3 {
+		t.Errorf("second Shutdown() = %v and stopped %v, want nothing to run again", err, stopped)
+	}
+}
+
+func TestShutdownGivesEachHookItsOwnTimeout(t *testing.T) {
+	m := New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	var databaseErr error
+	m.OnShutdown("database", func(ctx context.Context) error {
+		databaseErr = ctx.Err()
+		return nil
+	})
+	m.OnShutdown("workers", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	err := m.Shutdown(context.Background(), 10*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() = %v, want the workers to time out", err)
+	}
+	if databaseErr != nil {
+		t.Errorf("database was stopped with %v, want time left after the workers", databaseErr)
+	}
+}
@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// File is a log file rotated once it reaches maxSize bytes. The rotated
+// files are renamed to path.1, path.2 and so on, and only maxBackups of
+// them are kept.
+type File struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+func OpenFile(path string, maxSize int64, maxBackups int) (*File, error) {
+	f := &File{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.f.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.f.Close()
+}
+
+func (f *File) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("logging.File.open: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("logging.File.open: %w", err)
+	}
+
+	f.f = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *File) rotate() error {
+	if err := f.f.Close(); err != nil {
+		return fmt.Errorf("logging.File.rotate: %w", err)
+	}
+
+	var err error
+	if f.maxBackups > 0 {
+		os.Remove(fmt.Sprintf("%s.%d", f.path, f.maxBackups))
+		for i := f.maxBackups - 1; i > 0; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+		}
+		err = os.Rename(f.path, f.path+".1")
+	} else {
+		err = os.Truncate(f.path, 0)
+	}
+
+	// the file is reopened even when it couldn't be moved, writing on to a
+	// full file is better than losing the logs
+	if openErr := f.open(); openErr != nil {
+		return openErr
+	}
+	if err != nil {
+		return fmt.Errorf("logging.File.rotate: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	f, err := OpenFile(path, 10, 2)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	defer f.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	want := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) error = %v", name, err)
+		}
+		if string(got) != content {
+			t.Errorf("%s = %q, want %q", filepath.Base(name), got, content)
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("%s.3 exists, only 2 backups should be kept", filepath.Base(path))
+	}
+}
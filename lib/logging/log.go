@@ -1,16 +1,40 @@
 package logging
 
 import (
+	"context"
+	"final-project/helper"
 	"fmt"
 	"io"
 	"log/slog"
 	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
 )
 
-func New(w io.Writer) *slog.Logger {
-	logger := slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
-		AddSource: true,
-		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+// Options configures New. Level is usually a *slog.LevelVar so the level
+// can be changed while the server runs.
+type Options struct {
+	Format    string
+	Level     slog.Leveler
+	AddSource bool
+}
+
+func New(w io.Writer, opts Options) *slog.Logger {
+	handlerOpts := &slog.HandlerOptions{
+		AddSource: opts.AddSource,
+		Level:     opts.Level,
+	}
+
+	var handler slog.Handler
+	if opts.Format == FormatJSON {
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	} else {
+		handlerOpts.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
 			if a.Key == slog.TimeKey {
 				a.Value = slog.StringValue(a.Value.Time().Format("02-Jan-2006 15:04:05 -0700"))
 			}
@@ -20,8 +44,34 @@ func New(w io.Writer) *slog.Logger {
 				a.Value = slog.StringValue(fmt.Sprintf("%s:%d", split[len(split)-1], sourceFile.Line))
 			}
 			return a
-		},
-	}))
+		}
+		handler = slog.NewTextHandler(w, handlerOpts)
+	}
+
+	return slog.New(&contextHandler{handler})
+}
+
+// contextHandler adds the request ID and trace found in the context to every
+// record, so lines logged with the *Context methods can be tied to their
+// request and its spans.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := helper.RequestIDFromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{h.Handler.WithAttrs(attrs)}
+}
 
-	return logger
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{h.Handler.WithGroup(name)}
 }
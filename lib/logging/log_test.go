@@ -0,0 +1,28 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"final-project/helper"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDIsLogged(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, Options{}).With("component", "test")
+
+	logger.InfoContext(helper.ContextWithRequestID(context.Background(), "abc123"), "inside a request")
+	logger.Info("outside a request")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	if !strings.Contains(lines[0], "request_id=abc123") {
+		t.Errorf("line %q doesn't contain the request ID", lines[0])
+	}
+	if strings.Contains(lines[1], "request_id") {
+		t.Errorf("line %q has a request ID without a request", lines[1])
+	}
+}
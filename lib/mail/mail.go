@@ -0,0 +1,76 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+type SMTP struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTP(host string, port uint, username, password, from string) *SMTP {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTP{
+		addr: net.JoinHostPort(host, strconv.FormatUint(uint64(port), 10)),
+		auth: auth,
+		from: from,
+	}
+}
+
+func (s *SMTP) Send(ctx context.Context, to, subject, body string) error {
+	msg := strings.Join([]string{
+		"From: " + s.from,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(s.addr, s.auth, s.from, []string{to}, []byte(msg))
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("mail.SMTP.Send: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("mail.SMTP.Send: %w", ctx.Err())
+	}
+}
+
+// Log writes messages to the logger instead of sending them. It's used when
+// no SMTP server is configured.
+type Log struct {
+	logger *slog.Logger
+}
+
+func NewLog(logger *slog.Logger) *Log {
+	return &Log{logger}
+}
+
+func (l *Log) Send(ctx context.Context, to, subject, body string) error {
+	l.logger.InfoContext(ctx, "mail not sent, no SMTP server configured", "to", to, "subject", subject, "body", body)
+	return nil
+}
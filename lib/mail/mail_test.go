@@ -0,0 +1,134 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpServer accepts one connection and speaks just enough SMTP for
+// smtp.SendMail. The DATA section is sent on the returned channel.
+func smtpServer(t *testing.T) (string, uint, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	data := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "DATA"):
+				reply("354 go ahead")
+				var body strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					body.WriteString(line)
+				}
+				data <- body.String()
+				reply("250 queued")
+			case strings.HasPrefix(cmd, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	p, _ := strconv.ParseUint(port, 10, 16)
+	return host, uint(p), data
+}
+
+func TestSMTPSend(t *testing.T) {
+	host, port, data := smtpServer(t)
+	m := NewSMTP(host, port, "", "", "noreply@example.com")
+
+	if err := m.Send(context.Background(), "alice@example.com", "Hello", "Hi Alice"); err != nil {
+		t.Fatalf("Send() = %v, want nil", err)
+	}
+
+	msg := <-data
+	for _, want := range []string{
+		"From: noreply@example.com\r\n",
+		"To: alice@example.com\r\n",
+		"Subject: Hello\r\n",
+		"Content-Type: text/plain; charset=UTF-8\r\n",
+		"\r\n\r\nHi Alice",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message %q doesn't contain %q", msg, want)
+		}
+	}
+}
+
+func TestSMTPSendCanceled(t *testing.T) {
+	// a server that accepts the connection but never greets
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	p, _ := strconv.ParseUint(port, 10, 16)
+	m := NewSMTP(host, uint(p), "", "", "noreply@example.com")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := m.Send(ctx, "alice@example.com", "Hello", "Hi Alice"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Send() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestLogSend(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewLog(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	if err := m.Send(context.Background(), "alice@example.com", "Hello", "Hi Alice"); err != nil {
+		t.Fatalf("Send() = %v, want nil", err)
+	}
+	for _, want := range []string{"to=alice@example.com", "subject=Hello", `body="Hi Alice"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log %q doesn't contain %q", buf.String(), want)
+		}
+	}
+}
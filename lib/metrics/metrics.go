@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	EventRegistration   = "registration"
+	EventPhotoCreated   = "photo_created"
+	EventLikeCreated    = "like_created"
+	EventCommentCreated = "comment_created"
+)
+
+// The collectors are package-level so any layer can count without having
+// them passed around, they're only exposed through a registry built by
+// Handler.
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "mygram",
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "mygram",
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method and route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "mygram",
+		Subsystem: "rate_limit",
+		Name:      "rejections_total",
+		Help:      "Requests rejected by the rate limiter by route pattern.",
+	}, []string{"route"})
+
+	events = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "mygram",
+		Name:      "events_total",
+		Help:      "Business events such as registrations and new photos.",
+	}, []string{"event"})
+)
+
+// methods are the ones labelled as is, anything else a client sends is
+// counted as OTHER so it can't grow the label set.
+var methods = map[string]struct{}{
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodPost:    {},
+	http.MethodPut:     {},
+	http.MethodPatch:   {},
+	http.MethodDelete:  {},
+	http.MethodConnect: {},
+	http.MethodOptions: {},
+	http.MethodTrace:   {},
+}
+
+// ObserveRequest records a finished request. route is the pattern the
+// request matched, empty when it didn't match any.
+func ObserveRequest(method, route string, status int, took time.Duration) {
+	if _, ok := methods[method]; !ok {
+		method = "OTHER"
+	}
+	if route == "" {
+		route = "unmatched"
+	}
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(took.Seconds())
+}
+
+func RateLimited(route string) {
+	rateLimited.WithLabelValues(route).Inc()
+}
+
+func Count(event string) {
+	events.WithLabelValues(event).Inc()
+}
+
+// NewRegistry returns a registry with the collectors above, the connection
+// pool statistics of db and the Go runtime and process metrics.
+func NewRegistry(db *sql.DB) *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		httpRequests,
+		httpDuration,
+		rateLimited,
+		events,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	if db != nil {
+		reg.MustRegister(collectors.NewDBStatsCollector(db, "mygram"))
+	}
+	return reg
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler(db *sql.DB) http.Handler {
+	return promhttp.HandlerFor(NewRegistry(db), promhttp.HandlerOpts{})
+}
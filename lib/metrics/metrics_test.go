@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"bufio"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scrape returns the samples Handler serves by series, the collectors are
+// shared by the whole package so tests compare before and after.
+func scrape(t *testing.T) map[string]float64 {
+	t.Helper()
+	w := httptest.NewRecorder()
+	Handler(nil).ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	samples := make(map[string]float64)
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		if i < 0 {
+			continue
+		}
+		v, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("parse %q: %v", line, err)
+		}
+		samples[line[:i]] = v
+	}
+	return samples
+}
+
+func TestHandler(t *testing.T) {
+	before := scrape(t)
+
+	ObserveRequest("GET", "GET /photos", 200, 20*time.Millisecond)
+	ObserveRequest("GET", "", 404, time.Millisecond)
+	ObserveRequest("BREW", "", 404, time.Millisecond)
+	ObserveRequest("brew", "", 404, time.Millisecond)
+	RateLimited("POST /users/login")
+	Count(EventPhotoCreated)
+
+	after := scrape(t)
+	for series, want := range map[string]float64{
+		`mygram_http_requests_total{method="GET",route="GET /photos",status="200"}`:    1,
+		`mygram_http_requests_total{method="GET",route="unmatched",status="404"}`:      1,
+		`mygram_http_requests_total{method="OTHER",route="unmatched",status="404"}`:    2,
+		`mygram_http_request_duration_seconds_count{method="GET",route="GET /photos"}`: 1,
+		`mygram_rate_limit_rejections_total{route="POST /users/login"}`:                1,
+		`mygram_events_total{event="photo_created"}`:                                   1,
+	} {
+		if got := after[series] - before[series]; got != want {
+			t.Errorf("%s went up by %v, want %v", series, got, want)
+		}
+	}
+
+	for series := range after {
+		if strings.Contains(series, `method="BREW"`) || strings.Contains(series, `method="brew"`) {
+			t.Errorf("unknown method got its own series %s", series)
+		}
+	}
+	if _, ok := after["go_goroutines"]; !ok {
+		t.Error("metrics output is missing go_goroutines")
+	}
+}
@@ -0,0 +1,132 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrMissingIDToken = errors.New("token response doesn't contain an id_token")
+	ErrInvalidNonce   = errors.New("id_token nonce doesn't match")
+)
+
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Identity is what a provider asserts about the user in its ID token.
+type Identity struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+}
+
+// Provider runs the authorization code flow with PKCE against an OpenID
+// Connect provider. Discovery happens on first use and is retried until it
+// succeeds, so a provider being down doesn't keep the server from starting.
+type Provider struct {
+	conf Config
+
+	mu       sync.Mutex
+	oauth    *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+func New(conf Config) *Provider {
+	if len(conf.Scopes) == 0 {
+		conf.Scopes = []string{"email", "profile"}
+	}
+	return &Provider{conf: conf}
+}
+
+func (p *Provider) discover(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.oauth != nil {
+		return p.oauth, p.verifier, nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, p.conf.Issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("oidc.Provider.discover: %w", err)
+	}
+
+	p.oauth = &oauth2.Config{
+		ClientID:     p.conf.ClientID,
+		ClientSecret: p.conf.ClientSecret,
+		RedirectURL:  p.conf.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       append([]string{oidc.ScopeOpenID}, p.conf.Scopes...),
+	}
+	p.verifier = provider.Verifier(&oidc.Config{ClientID: p.conf.ClientID})
+
+	return p.oauth, p.verifier, nil
+}
+
+// AuthCodeURL returns the URL to send the user to. verifier is the PKCE
+// code verifier that has to be handed back to Exchange.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	oauth, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return oauth.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (Identity, error) {
+	var identity Identity
+
+	oauth, idVerifier, err := p.discover(ctx)
+	if err != nil {
+		return identity, err
+	}
+
+	token, err := oauth.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return identity, fmt.Errorf("oidc.Provider.Exchange: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return identity, fmt.Errorf("oidc.Provider.Exchange: %w", ErrMissingIDToken)
+	}
+
+	idToken, err := idVerifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return identity, fmt.Errorf("oidc.Provider.Exchange: %w", err)
+	}
+
+	if idToken.Nonce != nonce {
+		return identity, fmt.Errorf("oidc.Provider.Exchange: %w", ErrInvalidNonce)
+	}
+
+	err = idToken.Claims(&identity)
+	if err != nil {
+		return identity, fmt.Errorf("oidc.Provider.Exchange: %w", err)
+	}
+
+	return identity, nil
+}
+
+// GenerateState returns a random value for the state and nonce parameters.
+func GenerateState() string {
+	return oauth2.GenerateVerifier()
+}
+
+// GenerateVerifier returns a random PKCE code verifier.
+func GenerateVerifier() string {
+	return oauth2.GenerateVerifier()
+}
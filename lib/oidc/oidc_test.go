@@ -0,0 +1,111 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"final-project/lib/oidc"
+	"final-project/lib/oidc/oidctest"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+const redirectURL = "http://mygram.test/callback"
+
+// authorize follows the provider's redirect and returns the code and state
+// it sent back.
+func authorize(t *testing.T, authURL string) (string, string) {
+	t.Helper()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	res, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d, want %d", res.StatusCode, http.StatusFound)
+	}
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func TestProviderExchange(t *testing.T) {
+	want := oidc.Identity{Subject: "1234", Email: "budi@rocketmail.com", EmailVerified: true, Name: "Budi"}
+	server := oidctest.NewServer(want)
+	defer server.Close()
+
+	ctx := context.Background()
+	provider := oidc.New(server.Config(redirectURL))
+
+	t.Run("valid", func(t *testing.T) {
+		verifier := oidc.GenerateVerifier()
+		authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", verifier)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		code, state := authorize(t, authURL)
+		if state != "state" {
+			t.Errorf("state = %q, want %q", state, "state")
+		}
+
+		got, err := provider.Exchange(ctx, code, verifier, "nonce")
+		if err != nil {
+			t.Fatalf("Exchange() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("Exchange() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("wrong verifier", func(t *testing.T) {
+		authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", oidc.GenerateVerifier())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		code, _ := authorize(t, authURL)
+		if _, err := provider.Exchange(ctx, code, oidc.GenerateVerifier(), "nonce"); err == nil {
+			t.Error("Exchange() accepted a code with the wrong PKCE verifier")
+		}
+	})
+
+	t.Run("wrong nonce", func(t *testing.T) {
+		verifier := oidc.GenerateVerifier()
+		authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", verifier)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		code, _ := authorize(t, authURL)
+		if _, err := provider.Exchange(ctx, code, verifier, "other"); !errors.Is(err, oidc.ErrInvalidNonce) {
+			t.Errorf("Exchange() error = %v, want %v", err, oidc.ErrInvalidNonce)
+		}
+	})
+
+	t.Run("code reuse", func(t *testing.T) {
+		verifier := oidc.GenerateVerifier()
+		authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", verifier)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		code, _ := authorize(t, authURL)
+		if _, err := provider.Exchange(ctx, code, verifier, "nonce"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := provider.Exchange(ctx, code, verifier, "nonce"); err == nil {
+			t.Error("Exchange() accepted a code twice")
+		}
+	})
+}
@@ -0,0 +1,202 @@
+// Package oidctest provides a minimal OpenID Connect provider for tests. It
+// approves every authorization request for Identity without showing a login
+// page, and only supports the authorization code flow with PKCE (S256).
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"final-project/lib/oidc"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "oidctest"
+
+type Server struct {
+	*httptest.Server
+	ClientID     string
+	ClientSecret string
+
+	mu       sync.Mutex
+	identity oidc.Identity
+	key      *rsa.PrivateKey
+	grants   map[string]grant
+}
+
+type grant struct {
+	redirectURI string
+	challenge   string
+	nonce       string
+	identity    oidc.Identity
+}
+
+func NewServer(identity oidc.Identity) *Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic("oidctest: " + err.Error())
+	}
+
+	s := &Server{
+		ClientID:     "mygram",
+		ClientSecret: "mygram-secret",
+		identity:     identity,
+		key:          key,
+		grants:       make(map[string]grant),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("GET /keys", s.keys)
+	mux.HandleFunc("GET /authorize", s.authorize)
+	mux.HandleFunc("POST /token", s.token)
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// SetIdentity changes the user the following logins are approved for.
+func (s *Server) SetIdentity(identity oidc.Identity) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.identity = identity
+}
+
+func (s *Server) Config(redirectURL string) oidc.Config {
+	return oidc.Config{
+		Issuer:       s.URL,
+		ClientID:     s.ClientID,
+		ClientSecret: s.ClientSecret,
+		RedirectURL:  redirectURL,
+	}
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                s.URL,
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/keys",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (s *Server) keys(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+		Key:       &s.key.PublicKey,
+		KeyID:     keyID,
+		Algorithm: "RS256",
+		Use:       "sig",
+	}}})
+}
+
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" || q.Get("client_id") != s.ClientID ||
+		q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || !redirectURI.IsAbs() {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+
+	s.mu.Lock()
+	s.grants[code] = grant{
+		redirectURI: q.Get("redirect_uri"),
+		challenge:   q.Get("code_challenge"),
+		nonce:       q.Get("nonce"),
+		identity:    s.identity,
+	}
+	s.mu.Unlock()
+
+	params := redirectURI.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirectURI.RawQuery = params.Encode()
+
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+	}
+	if clientID != s.ClientID || clientSecret != s.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	s.mu.Lock()
+	g, ok := s.grants[r.PostFormValue("code")]
+	delete(s.grants, r.PostFormValue("code"))
+	s.mu.Unlock()
+
+	challenge := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || r.PostFormValue("grant_type") != "authorization_code" ||
+		r.PostFormValue("redirect_uri") != g.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(challenge[:]) != g.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	t := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":                s.URL,
+		"sub":                g.identity.Subject,
+		"aud":                s.ClientID,
+		"iat":                now.Unix(),
+		"exp":                now.Add(time.Minute).Unix(),
+		"nonce":              g.nonce,
+		"email":              g.identity.Email,
+		"email_verified":     g.identity.EmailVerified,
+		"name":               g.identity.Name,
+		"preferred_username": g.identity.PreferredUsername,
+	})
+	t.Header["kid"] = keyID
+
+	idToken, err := t.SignedString(s.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   60,
+		"id_token":     idToken,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		panic("oidctest: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// Memory keeps buckets in process memory. Buckets that haven't been used for
+// ttl are evicted, so ttl should be at least as long as it takes an empty
+// bucket to refill.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	ttl       time.Duration
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemory(ttl time.Duration) *Memory {
+	return &Memory{
+		buckets:   make(map[string]*bucket),
+		ttl:       ttl,
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (m *Memory) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t := m.now()
+	if t.Sub(m.lastSweep) >= m.ttl {
+		m.sweep(t)
+	}
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), lastSeen: t}
+		m.buckets[key] = b
+	}
+
+	b.tokens = min(float64(limit.Burst), b.tokens+t.Sub(b.lastSeen).Seconds()*limit.Rate)
+	b.lastSeen = t
+
+	if b.tokens < 1 {
+		return newResult(false, b.tokens, limit), nil
+	}
+
+	b.tokens--
+	return newResult(true, b.tokens, limit), nil
+}
+
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.buckets)
+}
+
+func (m *Memory) sweep(t time.Time) {
+	for key, b := range m.buckets {
+		if t.Sub(b.lastSeen) >= m.ttl {
+			delete(m.buckets, key)
+		}
+	}
+	m.lastSweep = t
+}
@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+func newTestMemory(ttl time.Duration) (*Memory, *fakeClock) {
+	clock := &fakeClock{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	m := NewMemory(ttl)
+	m.now = clock.now
+	m.lastSweep = clock.t
+	return m, clock
+}
+
+func TestMemoryAllow(t *testing.T) {
+	m, clock := newTestMemory(time.Hour)
+	limit := Limit{Burst: 3, Rate: 1}
+
+	for i := range 3 {
+		res, _ := m.Allow(context.Background(), "user:1", limit)
+		if !res.Allowed {
+			t.Fatalf("request %d was denied, want allowed", i+1)
+		}
+		if want := uint64(2 - i); res.Remaining != want {
+			t.Errorf("request %d: Remaining = %d, want %d", i+1, res.Remaining, want)
+		}
+	}
+
+	res, _ := m.Allow(context.Background(), "user:1", limit)
+	if res.Allowed {
+		t.Fatal("request over the burst was allowed")
+	}
+	if res.RetryAfter != time.Second {
+		t.Errorf("RetryAfter = %s, want 1s", res.RetryAfter)
+	}
+
+	if res, _ := m.Allow(context.Background(), "user:2", limit); !res.Allowed {
+		t.Error("other key was denied, want its own bucket")
+	}
+
+	clock.advance(time.Second)
+	if res, _ := m.Allow(context.Background(), "user:1", limit); !res.Allowed {
+		t.Error("request after refill was denied")
+	}
+}
+
+func TestMemoryEvictsIdleBuckets(t *testing.T) {
+	m, clock := newTestMemory(time.Minute)
+	limit := Limit{Burst: 1, Rate: 1}
+
+	m.Allow(context.Background(), "user:1", limit)
+	m.Allow(context.Background(), "user:2", limit)
+	if got := m.Len(); got != 2 {
+		t.Fatalf("Len() = %d, want 2", got)
+	}
+
+	clock.advance(time.Minute)
+	m.Allow(context.Background(), "user:3", limit)
+	if got := m.Len(); got != 1 {
+		t.Errorf("Len() after ttl = %d, want 1", got)
+	}
+}
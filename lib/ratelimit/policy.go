@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"final-project/helper"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Policy picks the limit for a request. A route entry takes precedence over
+// the top level, and within either level a role entry overrides the limit.
+// Routes are matched by their exact ServeMux pattern.
+type Policy struct {
+	Limit  Limit
+	Roles  map[string]Limit
+	Routes map[string]Policy
+}
+
+// Resolve returns the limit for route and role. scoped is true when the
+// route has its own entry, in which case it gets a bucket separate from the
+// one shared by the other routes.
+func (p Policy) Resolve(route, role string) (limit Limit, scoped bool) {
+	if rp, ok := p.Routes[route]; ok {
+		if l, ok := rp.Roles[role]; ok {
+			return l, true
+		}
+		return rp.Limit, true
+	}
+
+	if l, ok := p.Roles[role]; ok {
+		return l, false
+	}
+
+	return p.Limit, false
+}
+
+var wildcard = regexp.MustCompile(`\{[^}]*\}`)
+
+// Check makes sure every route entry names a pattern registered on mux, a
+// typo in the config would otherwise leave the route on the shared limit
+// without anyone noticing.
+func (p Policy) Check(mux *http.ServeMux) error {
+	for pattern := range p.Routes {
+		method, path, ok := strings.Cut(pattern, " ")
+		if !ok {
+			method, path = http.MethodGet, pattern
+		}
+
+		r, err := http.NewRequest(method, wildcard.ReplaceAllString(path, "x"), nil)
+		if err != nil {
+			return fmt.Errorf("ratelimit.Policy.Check: %w: %q", helper.ErrUnknownRateLimitRoute, pattern)
+		}
+		if _, registered := mux.Handler(r); registered != pattern {
+			return fmt.Errorf("ratelimit.Policy.Check: %w: %q", helper.ErrUnknownRateLimitRoute, pattern)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"errors"
+	"final-project/helper"
+	"net/http"
+	"testing"
+)
+
+func TestPolicyResolve(t *testing.T) {
+	var (
+		base     = Limit{Burst: 100, Rate: 5}
+		admin    = Limit{Burst: 1000, Rate: 50}
+		login    = Limit{Burst: 5, Rate: 0.1}
+		comment  = Limit{Burst: 10, Rate: 0.5}
+		modComms = Limit{Burst: 50, Rate: 5}
+	)
+
+	p := Policy{
+		Limit: base,
+		Roles: map[string]Limit{"admin": admin},
+		Routes: map[string]Policy{
+			"POST /users/login": {Limit: login},
+			"POST /photos/{photoID}/comments": {
+				Limit: comment,
+				Roles: map[string]Limit{"moderator": modComms},
+			},
+		},
+	}
+
+	cases := []struct {
+		route, role string
+		want        Limit
+		wantScoped  bool
+	}{
+		{"GET /photos", "user", base, false},
+		{"GET /photos", "admin", admin, false},
+		{"POST /users/login", "", login, true},
+		{"POST /users/login", "admin", login, true},
+		{"POST /photos/{photoID}/comments", "user", comment, true},
+		{"POST /photos/{photoID}/comments", "moderator", modComms, true},
+	}
+
+	for _, tc := range cases {
+		got, scoped := p.Resolve(tc.route, tc.role)
+		if got != tc.want || scoped != tc.wantScoped {
+			t.Errorf("Resolve(%q, %q) = %+v, %t, want %+v, %t", tc.route, tc.role, got, scoped, tc.want, tc.wantScoped)
+		}
+	}
+}
+
+func TestPolicyCheck(t *testing.T) {
+	mux := http.NewServeMux()
+	noop := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	mux.Handle("POST /users/login", noop)
+	mux.Handle("POST /photos/{photoID}/comments", noop)
+	mux.Handle("GET /exports/{path...}", noop)
+
+	p := Policy{Routes: map[string]Policy{
+		"POST /users/login":               {},
+		"POST /photos/{photoID}/comments": {},
+		"GET /exports/{path...}":          {},
+	}}
+	if err := p.Check(mux); err != nil {
+		t.Errorf("Check() = %v, want nil", err)
+	}
+
+	for _, pattern := range []string{"POST /user/login", "GET /users/login", "POST /photos/{id}/comments"} {
+		p := Policy{Routes: map[string]Policy{pattern: {}}}
+		if err := p.Check(mux); !errors.Is(err, helper.ErrUnknownRateLimitRoute) {
+			t.Errorf("Check() with %q = %v, want %v", pattern, err, helper.ErrUnknownRateLimitRoute)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Postgres keeps buckets in the rate_limit_bucket table so every instance of
+// the API shares the same limits. Each call is a single upsert, which makes
+// it safe under concurrent requests for the same key.
+type Postgres struct {
+	db  *sql.DB
+	ttl time.Duration
+}
+
+func NewPostgres(db *sql.DB, ttl time.Duration) *Postgres {
+	return &Postgres{db, ttl}
+}
+
+func (p *Postgres) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	var (
+		tokens  float64
+		allowed bool
+		stmt    = `
+		INSERT INTO
+			rate_limit_bucket AS b(key, tokens, allowed, updated_at)
+			VALUES($1, $2::DOUBLE PRECISION - 1, $2::DOUBLE PRECISION >= 1, clock_timestamp())
+		ON CONFLICT (key) DO UPDATE
+		SET
+			tokens=CASE
+				WHEN LEAST($2::DOUBLE PRECISION, b.tokens + EXTRACT(EPOCH FROM clock_timestamp() - b.updated_at)::DOUBLE PRECISION * $3::DOUBLE PRECISION) >= 1
+				THEN LEAST($2::DOUBLE PRECISION, b.tokens + EXTRACT(EPOCH FROM clock_timestamp() - b.updated_at)::DOUBLE PRECISION * $3::DOUBLE PRECISION) - 1
+				ELSE LEAST($2::DOUBLE PRECISION, b.tokens + EXTRACT(EPOCH FROM clock_timestamp() - b.updated_at)::DOUBLE PRECISION * $3::DOUBLE PRECISION)
+			END,
+			allowed=LEAST($2::DOUBLE PRECISION, b.tokens + EXTRACT(EPOCH FROM clock_timestamp() - b.updated_at)::DOUBLE PRECISION * $3::DOUBLE PRECISION) >= 1,
+			updated_at=clock_timestamp()
+		RETURNING
+			tokens,
+			allowed
+		`
+	)
+
+	row := p.db.QueryRowContext(ctx, stmt, key, float64(limit.Burst), limit.Rate)
+	if err := row.Err(); err != nil {
+		return Result{}, fmt.Errorf("ratelimit.Postgres.Allow: %w", err)
+	}
+
+	if err := row.Scan(&tokens, &allowed); err != nil {
+		return Result{}, fmt.Errorf("ratelimit.Postgres.Allow: %w", err)
+	}
+
+	return newResult(allowed, tokens, limit), nil
+}
+
+// Cleanup removes buckets that haven't been used for the configured ttl.
+func (p *Postgres) Cleanup(ctx context.Context) error {
+	var (
+		stmt = `
+		DELETE FROM
+			rate_limit_bucket
+		WHERE updated_at < clock_timestamp() - $1::DOUBLE PRECISION * INTERVAL '1 second'
+		`
+	)
+
+	_, err := p.db.ExecContext(ctx, stmt, p.ttl.Seconds())
+	if err != nil {
+		return fmt.Errorf("ratelimit.Postgres.Cleanup: %w", err)
+	}
+
+	return nil
+}
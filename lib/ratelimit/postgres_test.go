@@ -0,0 +1,141 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// bucketStore stands in for PostgreSQL: it answers the upsert and delete
+// Postgres sends with the same arithmetic as the SQL, on a fake clock. The
+// SQL itself is covered by the integration test.
+type bucketStore struct {
+	clock   *fakeClock
+	buckets map[string]*bucketRow
+}
+
+type bucketRow struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+func (s *bucketStore) Connect(context.Context) (driver.Conn, error) { return storeConn{s}, nil }
+func (s *bucketStore) Driver() driver.Driver                        { return nil }
+
+type storeConn struct {
+	store *bucketStore
+}
+
+func (storeConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (storeConn) Close() error                        { return nil }
+func (storeConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+func (c storeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if !strings.Contains(query, "INSERT INTO") {
+		return nil, driver.ErrSkip
+	}
+
+	key, burst, rate := args[0].Value.(string), args[1].Value.(float64), args[2].Value.(float64)
+	now := c.store.clock.now()
+
+	b, ok := c.store.buckets[key]
+	if !ok {
+		c.store.buckets[key] = &bucketRow{burst - 1, now}
+		return &storeRows{values: []driver.Value{burst - 1, burst >= 1}}, nil
+	}
+
+	tokens := math.Min(burst, b.tokens+now.Sub(b.updatedAt).Seconds()*rate)
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+	b.tokens, b.updatedAt = tokens, now
+
+	return &storeRows{values: []driver.Value{tokens, allowed}}, nil
+}
+
+func (c storeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if !strings.Contains(query, "DELETE FROM") {
+		return nil, driver.ErrSkip
+	}
+
+	ttl := time.Duration(args[0].Value.(float64) * float64(time.Second))
+	var n int64
+	for key, b := range c.store.buckets {
+		if b.updatedAt.Before(c.store.clock.now().Add(-ttl)) {
+			delete(c.store.buckets, key)
+			n++
+		}
+	}
+
+	return driver.RowsAffected(n), nil
+}
+
+type storeRows struct {
+	values []driver.Value
+	done   bool
+}
+
+func (r *storeRows) Columns() []string { return []string{"tokens", "allowed"} }
+func (r *storeRows) Close() error      { return nil }
+
+func (r *storeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	copy(dest, r.values)
+	return nil
+}
+
+func TestPostgresAllow(t *testing.T) {
+	store := &bucketStore{
+		clock:   &fakeClock{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		buckets: make(map[string]*bucketRow),
+	}
+	db := sql.OpenDB(store)
+	defer db.Close()
+
+	p := NewPostgres(db, time.Hour)
+	limit := Limit{Burst: 2, Rate: 0.5}
+
+	for i := range 2 {
+		res, err := p.Allow(context.Background(), "user:1", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Allowed || res.Remaining != uint64(1-i) {
+			t.Fatalf("request %d = %+v, want allowed with %d remaining", i+1, res, 1-i)
+		}
+	}
+
+	res, err := p.Allow(context.Background(), "user:1", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed || res.RetryAfter != 2*time.Second {
+		t.Errorf("Allow() over the burst = %+v, want denied with a 2s retry delay", res)
+	}
+
+	store.clock.advance(2 * time.Second)
+	if res, _ := p.Allow(context.Background(), "user:1", limit); !res.Allowed {
+		t.Errorf("Allow() after refilling = %+v, want allowed", res)
+	}
+
+	if res, _ := p.Allow(context.Background(), "user:2", limit); !res.Allowed {
+		t.Errorf("Allow() for another key = %+v, want allowed", res)
+	}
+
+	store.clock.advance(time.Hour + time.Second)
+	if err := p.Cleanup(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.buckets) != 0 {
+		t.Errorf("%d buckets left after Cleanup, want 0", len(store.buckets))
+	}
+}
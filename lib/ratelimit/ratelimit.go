@@ -0,0 +1,39 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit describes a token bucket: Burst requests can be made at once and
+// the bucket refills at Rate tokens per second.
+type Limit struct {
+	Burst uint64
+	Rate  float64
+}
+
+type Result struct {
+	Allowed    bool
+	Limit      uint64
+	Remaining  uint64
+	RetryAfter time.Duration
+}
+
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+func newResult(allowed bool, tokens float64, limit Limit) Result {
+	res := Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: uint64(math.Max(0, math.Floor(tokens))),
+	}
+
+	if !allowed && limit.Rate > 0 {
+		res.RetryAfter = time.Duration((1 - tokens) / limit.Rate * float64(time.Second))
+	}
+
+	return res
+}
@@ -0,0 +1,187 @@
+package tracing
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// module is the import path prefix of this project's packages, used to
+// find the function that ran a statement.
+var module = strings.TrimSuffix(reflect.TypeOf(connector{}).PkgPath(), "lib/tracing")
+
+// WrapConnector returns a connector whose connections record a span for
+// every query and exec. Spans are named after the function of this project
+// that ran the statement, such as photoRepository.FindAll, and carry the
+// number of rows returned or affected.
+func WrapConnector(c driver.Connector) driver.Connector {
+	return connector{c}
+}
+
+type connector struct {
+	driver.Connector
+}
+
+func (c connector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{conn}, nil
+}
+
+type tracedConn struct {
+	driver.Conn
+}
+
+func (c *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, span := startStatement(ctx, query)
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != nil {
+		endStatement(span, err)
+		return nil, err
+	}
+
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, span := startStatement(ctx, query)
+	res, err := execer.ExecContext(ctx, query, args)
+	if err == nil {
+		if n, err := res.RowsAffected(); err == nil {
+			span.SetAttributes(attribute.Int64("db.rows_affected", n))
+		}
+	}
+	endStatement(span, err)
+
+	return res, err
+}
+
+func (c *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *tracedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// tracedRows ends the span of its query once closed, which database/sql
+// does after the last row or when a single row has been scanned.
+type tracedRows struct {
+	driver.Rows
+	span trace.Span
+	n    int64
+	err  error
+}
+
+func (r *tracedRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err == nil {
+		r.n++
+	} else if !errors.Is(err, io.EOF) {
+		r.err = err
+	}
+	return err
+}
+
+func (r *tracedRows) Close() error {
+	err := r.Rows.Close()
+	r.span.SetAttributes(attribute.Int64("db.rows_returned", r.n))
+	endStatement(r.span, errors.Join(r.err, err))
+	return err
+}
+
+// startStatement only walks the stack for the span's name and copies the
+// query when the span is sampled, every statement goes through here.
+func startStatement(ctx context.Context, query string) (context.Context, trace.Span) {
+	ctx, span := Start(ctx, "sql", trace.WithSpanKind(trace.SpanKindClient))
+	if !span.IsRecording() {
+		return ctx, span
+	}
+
+	span.SetName(statementName())
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.statement", strings.Join(strings.Fields(query), " ")),
+	)
+	return ctx, span
+}
+
+func endStatement(span trace.Span, err error) {
+	End(span, &err)
+}
+
+// statementName walks up the stack to the first function of this project
+// outside of this package and turns it into a short name, e.g.
+// final-project/repository/photo.(*photoRepository).FindAll becomes
+// photoRepository.FindAll.
+func statementName() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, module) && !strings.HasPrefix(frame.Function, module+"lib/tracing.") {
+			return shortName(frame.Function)
+		}
+		if !more {
+			return "sql"
+		}
+	}
+}
+
+func shortName(function string) string {
+	name := function[strings.LastIndex(function, "/")+1:]
+	if _, rest, ok := strings.Cut(name, "."); ok && strings.HasPrefix(rest, "(") {
+		name = rest
+	}
+	name = strings.NewReplacer("(*", "", "(", "", ")", "").Replace(name)
+	if i := strings.Index(name, ".func"); i > 0 {
+		name = name[:i]
+	}
+	return name
+}
@@ -0,0 +1,18 @@
+package tracing
+
+import "testing"
+
+func TestShortName(t *testing.T) {
+	cases := map[string]string{
+		"final-project/repository/photo.(*photoRepository).FindAll":    "photoRepository.FindAll",
+		"final-project/repository/user.(*userRepository).Delete.func1": "userRepository.Delete",
+		"final-project/lib/ratelimit.(*Postgres).Allow":                "Postgres.Allow",
+		"final-project/lib/database.createTables":                      "database.createTables",
+	}
+
+	for function, want := range cases {
+		if got := shortName(function); got != want {
+			t.Errorf("shortName(%q) = %q, want %q", function, got, want)
+		}
+	}
+}
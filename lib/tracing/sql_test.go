@@ -0,0 +1,114 @@
+package tracing_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"final-project/lib/tracing"
+	"io"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+func (fakeConnector) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+func (fakeConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{left: 3}, nil
+}
+
+type fakeRows struct {
+	left int
+}
+
+func (r *fakeRows) Columns() []string { return []string{"id"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.left == 0 {
+		return io.EOF
+	}
+	r.left--
+	dest[0] = int64(r.left)
+	return nil
+}
+
+// useRecorder installs a tracer provider recording every span until the
+// test ends.
+func useRecorder(t *testing.T, opts ...sdktrace.TracerProviderOption) *tracetest.SpanRecorder {
+	t.Helper()
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(append(opts, sdktrace.WithSpanProcessor(recorder))...))
+	return recorder
+}
+
+func TestWrapConnector(t *testing.T) {
+	recorder := useRecorder(t)
+
+	db := sql.OpenDB(tracing.WrapConnector(fakeConnector{}))
+	defer db.Close()
+
+	rows, err := db.QueryContext(context.Background(), "SELECT id\n\t\tFROM photo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+	}
+	rows.Close()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if got := spans[0].Name(); got != "tracing_test.TestWrapConnector" {
+		t.Errorf("span name = %q, want the calling function", got)
+	}
+
+	want := map[attribute.Key]attribute.Value{
+		"db.statement":     attribute.StringValue("SELECT id FROM photo"),
+		"db.rows_returned": attribute.Int64Value(3),
+	}
+	for _, attr := range spans[0].Attributes() {
+		if v, ok := want[attr.Key]; ok {
+			if attr.Value != v {
+				t.Errorf("%s = %v, want %v", attr.Key, attr.Value.Emit(), v.Emit())
+			}
+			delete(want, attr.Key)
+		}
+	}
+	for key := range want {
+		t.Errorf("span is missing %s", key)
+	}
+}
+
+func TestWrapConnectorNotSampled(t *testing.T) {
+	recorder := useRecorder(t, sdktrace.WithSampler(sdktrace.NeverSample()))
+
+	db := sql.OpenDB(tracing.WrapConnector(fakeConnector{}))
+	defer db.Close()
+
+	rows, err := db.QueryContext(context.Background(), "SELECT id FROM photo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+
+	if spans := recorder.Ended(); len(spans) != 0 {
+		t.Errorf("got %d spans, want none", len(spans))
+	}
+}
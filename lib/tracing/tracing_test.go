@@ -0,0 +1,39 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"final-project/lib/tracing"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestEnd(t *testing.T) {
+	recorder := useRecorder(t)
+
+	func() (err error) {
+		_, span := tracing.Start(context.Background(), "ok")
+		defer tracing.End(span, &err)
+		return nil
+	}()
+	func() (err error) {
+		_, span := tracing.Start(context.Background(), "failed")
+		defer tracing.End(span, &err)
+		return errors.New("user not found")
+	}()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	if got := spans[0].Status().Code; got != codes.Unset || len(spans[0].Events()) != 0 {
+		t.Errorf("span without an error has status %s and %d events", got, len(spans[0].Events()))
+	}
+	if got := spans[1].Status(); got.Code != codes.Error || got.Description != "user not found" {
+		t.Errorf("status = %+v, want the returned error", got)
+	}
+	if events := spans[1].Events(); len(events) != 1 || events[0].Name != "exception" {
+		t.Errorf("events = %+v, want the recorded error", events)
+	}
+}
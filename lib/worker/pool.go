@@ -0,0 +1,150 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var (
+	ErrPoolClosed = errors.New("worker pool is closed")
+	ErrQueueFull  = errors.New("worker queue is full")
+)
+
+type Job func(context.Context)
+
+// Pool runs jobs in the background on a fixed number of goroutines. Jobs
+// receive a context that is cancelled when the pool is shut down.
+type Pool struct {
+	jobs   chan Job
+	done   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	closed bool
+	logger *slog.Logger
+}
+
+func New(size, queueSize int, logger *slog.Logger) *Pool {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		jobs:   make(chan Job, queueSize),
+		done:   make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+		logger: logger,
+	}
+
+	for range size {
+		p.wg.Add(1)
+		go p.work()
+	}
+
+	return p
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for job := range p.jobs {
+		p.run(job)
+	}
+}
+
+func (p *Pool) run(job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.logger.Error("worker panic recovered", "cause", r)
+		}
+	}()
+	job(p.ctx)
+}
+
+// Submit queues job without blocking.
+func (p *Pool) Submit(job Job) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return fmt.Errorf("worker.Submit: %w", ErrPoolClosed)
+	}
+
+	select {
+	case p.jobs <- job:
+		return nil
+	default:
+		return fmt.Errorf("worker.Submit: %w", ErrQueueFull)
+	}
+}
+
+// Every runs job on its own goroutine once per interval until the pool is
+// shut down. A run that takes longer than interval delays the next one.
+func (p *Pool) Every(interval time.Duration, job Job) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return fmt.Errorf("worker.Every: %w", ErrPoolClosed)
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.done:
+				return
+			case <-ticker.C:
+				p.run(job)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Check fails once the pool is shut down or while its queue is full, for
+// readiness probes.
+func (p *Pool) Check(context.Context) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return fmt.Errorf("worker.Check: %w", ErrPoolClosed)
+	}
+	if cap(p.jobs) > 0 && len(p.jobs) == cap(p.jobs) {
+		return fmt.Errorf("worker.Check: %w", ErrQueueFull)
+	}
+
+	return nil
+}
+
+// Shutdown stops accepting jobs and waits for the queued ones to finish. If
+// ctx expires first, running jobs are cancelled and ctx.Err() is returned.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+		close(p.done)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		<-done
+		return fmt.Errorf("worker.Shutdown: %w", ctx.Err())
+	}
+}
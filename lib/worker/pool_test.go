@@ -0,0 +1,122 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoolRunsQueuedJobsBeforeShutdown(t *testing.T) {
+	p := New(2, 10, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	var n atomic.Int32
+	for range 10 {
+		if err := p.Submit(func(context.Context) { n.Add(1) }); err != nil {
+			t.Fatalf("Submit() = %v, want nil", err)
+		}
+	}
+
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() = %v, want nil", err)
+	}
+
+	if got := n.Load(); got != 10 {
+		t.Errorf("ran %d jobs, want 10", got)
+	}
+
+	if err := p.Submit(func(context.Context) {}); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Submit() after Shutdown = %v, want %v", err, ErrPoolClosed)
+	}
+}
+
+func TestPoolCancelsJobsOnShutdownTimeout(t *testing.T) {
+	p := New(1, 1, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	started := make(chan struct{})
+	p.Submit(func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestPoolRecoversPanics(t *testing.T) {
+	p := New(1, 2, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	done := make(chan struct{})
+	p.Submit(func(context.Context) { panic("boom") })
+	p.Submit(func(context.Context) { close(done) })
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("job after panic didn't run")
+	}
+	p.Shutdown(context.Background())
+}
+
+func TestPoolEveryStopsOnShutdown(t *testing.T) {
+	p := New(1, 1, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ran := make(chan struct{}, 1)
+	err := p.Every(time.Millisecond, func(context.Context) {
+		select {
+		case ran <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		t.Fatalf("Every() = %v, want nil", err)
+	}
+
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("periodic job didn't run")
+	}
+
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() = %v, want nil", err)
+	}
+
+	if err := p.Every(time.Millisecond, func(context.Context) {}); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Every() after Shutdown = %v, want %v", err, ErrPoolClosed)
+	}
+}
+
+func TestPoolCheck(t *testing.T) {
+	p := New(1, 1, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if err := p.Check(context.Background()); err != nil {
+		t.Fatalf("Check() = %v, want nil", err)
+	}
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p.Submit(func(context.Context) {
+		close(started)
+		<-release
+	})
+	<-started
+	p.Submit(func(context.Context) {})
+
+	if err := p.Check(context.Background()); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Check() with a full queue = %v, want %v", err, ErrQueueFull)
+	}
+
+	close(release)
+	p.Shutdown(context.Background())
+	if err := p.Check(context.Background()); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Check() after Shutdown = %v, want %v", err, ErrPoolClosed)
+	}
+}
@@ -2,14 +2,25 @@ package main
 
 import (
 	"context"
+	"crypto/x509"
 	"final-project/helper"
+	"final-project/lib/certs"
 	"final-project/lib/config"
 	"final-project/lib/database"
+	"final-project/lib/health"
+	"final-project/lib/lifecycle"
 	"final-project/lib/logging"
+	"final-project/lib/mail"
+	"final-project/lib/metrics"
+	"final-project/lib/ratelimit"
+	"final-project/lib/tracing"
+	"final-project/lib/worker"
 	"final-project/middleware"
 	"final-project/routes"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -34,61 +45,292 @@ func main() {
 	flag.StringVar(&configFilePath, "json-config", "config.json", "path to json config file")
 	flag.Parse()
 
-	logger := logging.New(os.Stderr)
+	logger := logging.New(os.Stderr, logging.Options{})
 	conf, err := config.Load(configFilePath)
 	if err != nil {
 		logger.Error(err.Error())
 		os.Exit(1)
 	}
 
-	helper.JWTSecret = []byte(conf.App.JWTSecret)
+	var (
+		sinks   []io.Writer
+		logFile *logging.File
+	)
+	if conf.Log.Stderr {
+		sinks = append(sinks, os.Stderr)
+	}
+	if conf.Log.File != "" {
+		logFile, err = logging.OpenFile(conf.Log.File, int64(conf.Log.MaxSizeMB)<<20, conf.Log.MaxBackups)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+		sinks = append(sinks, logFile)
+	}
+
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(conf.Log.Level)
+	logger = logging.New(io.MultiWriter(sinks...), logging.Options{
+		Format:    conf.Log.Format,
+		Level:     logLevel,
+		AddSource: conf.Log.AddSource,
+	})
+	slog.SetDefault(logger)
+
+	lc := lifecycle.New(logger)
+	if logFile != nil {
+		lc.OnShutdown("log file", func(context.Context) error {
+			return logFile.Close()
+		})
+	}
+
+	helper.JWTKeys, err = conf.App.KeySet()
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
 	helper.JWTExpiresIn = helper.GetJWTExpiresIn(conf.App.JWTExpiresIn, time.Hour)
+	helper.JWTIssuer = conf.App.JWTIssuer
+	helper.JWTAudience = conf.App.JWTAudience
+	helper.PasswordHashing = conf.App.PasswordHashing.Params()
+	helper.Passwords, err = conf.App.PasswordPolicy.Policy()
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	middleware.Preconditions = middleware.NewPreconditions(conf.App.RequireIfMatch)
+	middleware.ClientIP = middleware.NewClientIP(conf.App.TrustProxy)
+	middleware.AdminClientCert = middleware.NewClientCert(conf.App.AdminMTLS)
+	middleware.CORS = middleware.NewCORS(middleware.CORSOptions{
+		AllowedOrigins:   conf.App.CORS.AllowedOrigins,
+		AllowedMethods:   conf.App.CORS.AllowedMethods,
+		AllowedHeaders:   conf.App.CORS.AllowedHeaders,
+		ExposedHeaders:   conf.App.CORS.ExposedHeaders,
+		AllowCredentials: conf.App.CORS.AllowCredentials,
+		MaxAge:           conf.App.CORS.MaxAge,
+	})
+	middleware.SecurityHeaders = middleware.NewSecurityHeaders(middleware.SecurityHeaderOptions{
+		HSTSMaxAge:            conf.App.SecurityHeaders.HSTSMaxAge,
+		HSTSIncludeSubdomains: conf.App.SecurityHeaders.HSTSIncludeSubdomains,
+		FrameOptions:          conf.App.SecurityHeaders.FrameOptions,
+		ContentSecurityPolicy: conf.App.SecurityHeaders.ContentSecurityPolicy,
+	})
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    conf.Tracing.Exporter,
+		Endpoint:    conf.Tracing.Endpoint,
+		Insecure:    conf.Tracing.Insecure,
+		ServiceName: conf.Tracing.ServiceName,
+		SampleRatio: conf.Tracing.SampleRatio,
+	})
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	lc.OnShutdown("tracing", shutdownTracing)
+
+	var mailer mail.Mailer = mail.NewLog(logger)
+	if conf.Mail.Host != "" {
+		mailer = mail.NewSMTP(conf.Mail.Host, conf.Mail.Port, conf.Mail.Username, conf.Mail.Password, conf.Mail.From)
+	}
 
 	db, err := database.New(conf.DB)
 	if err != nil {
 		logger.Error(err.Error())
 		os.Exit(1)
 	}
-	defer db.Close()
+	lc.OnShutdown("database", func(context.Context) error {
+		return db.Close()
+	})
+
+	err = os.MkdirAll(conf.App.ExportDir, 0o750)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	pool := worker.New(conf.App.Workers, conf.App.WorkerQueue, logger)
+	lc.OnShutdown("workers", pool.Shutdown)
+	userService := routes.NewUserService(db, logger, conf.App, mailer, pool)
+	exportService := routes.NewExportService(db, logger, pool, conf.App)
+	err = routes.InitJobs(pool, db, logger, conf.App, userService, exportService)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	policy := conf.RateLimit.Policy()
+	mw := routes.Middlewares{Auth: routes.NewAuth(db, logger)}
+	switch conf.RateLimit.Backend {
+	case "postgres":
+		limiter := ratelimit.NewPostgres(db, conf.RateLimit.TTL)
+		mw.RateLimit = middleware.NewRateLimit(limiter, policy, logger)
+		err = pool.Every(conf.RateLimit.TTL, func(ctx context.Context) {
+			if err := limiter.Cleanup(ctx); err != nil {
+				logger.ErrorContext(ctx, err.Error())
+			}
+		})
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+	default:
+		mw.RateLimit = middleware.NewRateLimit(ratelimit.NewMemory(conf.RateLimit.TTL), policy, logger)
+	}
 
 	api := http.NewServeMux()
 
 	{
-		routes.InitUserRoutes(api, db, logger)
-		routes.InitPhotoRoutes(api, db, logger)
-		routes.InitLikeRoutes(api, db, logger)
-		routes.InitCommentRoutes(api, db, logger)
-		routes.InitSocialMediaRoutes(api, db, logger)
+		routes.InitUserRoutes(api, mw, userService)
+		routes.InitPhotoRoutes(api, mw, db, logger)
+		routes.InitLikeRoutes(api, mw, db, logger)
+		routes.InitCommentRoutes(api, mw, db, logger)
+		routes.InitSocialMediaRoutes(api, mw, db, logger)
+		routes.InitExportRoutes(api, mw, exportService, conf.App)
+		routes.InitAPITokenRoutes(api, mw, db, logger)
+		routes.InitSessionRoutes(api, mw, db, logger)
+		routes.InitAuditRoutes(api, mw, db, logger)
+		routes.InitLogRoutes(api, mw, db, logLevel, logger)
 	}
 
+	if err := policy.Check(api); err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	checker := health.New(conf.App.ReadinessTimeout)
+	checker.Add("database", db.PingContext)
+	checker.Add("schema", func(ctx context.Context) error {
+		return database.CheckSchema(ctx, db)
+	})
+	checker.Add("workers", pool.Check)
+
 	r := http.NewServeMux()
 	docs.SwaggerInfo.BasePath = conf.App.BasePath
 	{
-		r.Handle(conf.App.BasePath, middleware.Logging(http.StripPrefix(strings.TrimSuffix(conf.App.BasePath, "/"), api)))
-		r.HandleFunc("GET /swagger/", httpSwagger.Handler(
+		r.Handle(conf.App.BasePath, middleware.ClientIP(middleware.Tracing(middleware.NewLogging(logger)(http.StripPrefix(strings.TrimSuffix(conf.App.BasePath, "/"), middleware.Route(api))))))
+		routes.InitJWKSRoutes(r, helper.JWTKeys, logger)
+		if conf.App.MetricsAddr == "" {
+			r.Handle("GET /metrics", middleware.AdminClientCert(mw.Auth(middleware.RequireScope(helper.ScopeAdmin)(middleware.RequireRole(helper.RoleAdmin)(metrics.Handler(db))))))
+		}
+		routes.InitHealthRoutes(r, checker, logger)
+		r.Handle("GET /swagger/", middleware.ContentSecurityPolicy(conf.App.SecurityHeaders.SwaggerContentSecurityPolicy)(httpSwagger.Handler(
 			httpSwagger.URL("/swagger/doc.json"),
-		))
+		)))
 	}
 
-	server := new(http.Server)
-	server.Addr = fmt.Sprintf("%s:%d", conf.App.Host, conf.App.Port)
-	server.Handler = middleware.Recover(r)
+	server := &http.Server{
+		Addr:              fmt.Sprintf("%s:%d", conf.App.Host, conf.App.Port),
+		Handler:           middleware.RequestID(middleware.NewRecover(logger)(middleware.SecurityHeaders(middleware.CORS(r)))),
+		ReadTimeout:       conf.App.ReadTimeout,
+		ReadHeaderTimeout: conf.App.ReadHeaderTimeout,
+		WriteTimeout:      conf.App.WriteTimeout,
+		IdleTimeout:       conf.App.IdleTimeout,
+	}
+
+	if conf.App.TLSEnabled() {
+		reloader, err := certs.New(conf.App.TLSCertFile, conf.App.TLSKeyFile, logger)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
 
-	logger.Info("Starting server...", "addr", server.Addr)
+		var clientCAs *x509.CertPool
+		if conf.App.TLSClientCAFile != "" {
+			clientCAs, err = certs.LoadCertPool(conf.App.TLSClientCAFile)
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+		}
+		server.TLSConfig = reloader.ServerConfig(clientCAs)
+
+		err = pool.Every(conf.App.TLSReloadInterval, reloader.ReloadIfChanged)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			for range hup {
+				if err := reloader.Reload(); err != nil {
+					logger.Error(err.Error())
+					continue
+				}
+				logger.Info("certificate reloaded", "cert_file", conf.App.TLSCertFile)
+			}
+		}()
+	}
+
+	logger.Info("Starting server...", "addr", server.Addr, "tls", server.TLSConfig != nil)
 	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if server.TLSConfig != nil {
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			logger.Error(err.Error())
 			os.Exit(1)
 		}
 	}()
+	lc.OnShutdown("server", server.Shutdown)
+
+	if conf.App.HTTPRedirectAddr != "" {
+		redirect := &http.Server{
+			Addr:              conf.App.HTTPRedirectAddr,
+			Handler:           certs.RedirectHandler(conf.App.Port),
+			ReadTimeout:       conf.App.ReadTimeout,
+			ReadHeaderTimeout: conf.App.ReadHeaderTimeout,
+			WriteTimeout:      conf.App.WriteTimeout,
+			IdleTimeout:       conf.App.IdleTimeout,
+		}
+
+		logger.Info("Starting HTTPS redirect...", "addr", redirect.Addr)
+		go func() {
+			if err := redirect.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+		}()
+		lc.OnShutdown("https redirect", redirect.Shutdown)
+	}
+
+	if conf.App.MetricsAddr != "" {
+		m := http.NewServeMux()
+		m.Handle("GET /metrics", metrics.Handler(db))
+		metricsServer := &http.Server{
+			Addr:              conf.App.MetricsAddr,
+			Handler:           middleware.NewRecover(logger)(m),
+			ReadTimeout:       conf.App.ReadTimeout,
+			ReadHeaderTimeout: conf.App.ReadHeaderTimeout,
+			WriteTimeout:      conf.App.WriteTimeout,
+			IdleTimeout:       conf.App.IdleTimeout,
+		}
+
+		logger.Info("Starting metrics server...", "addr", metricsServer.Addr)
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+		}()
+		lc.OnShutdown("metrics server", metricsServer.Shutdown)
+	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill, syscall.SIGTERM, syscall.SIGINT)
 	defer cancel()
 	<-ctx.Done()
 
-	logger.Info("Shutting down server...", "addr", server.Addr)
-	err = server.Shutdown(context.Background())
-	if err != nil {
-		logger.Error(err.Error())
+	logger.Info("Draining server...", "delay", conf.App.ReadinessDrainDelay)
+	checker.Drain()
+	time.Sleep(conf.App.ReadinessDrainDelay)
+
+	logger.Info("Shutting down server...", "addr", server.Addr, "timeout", conf.App.ShutdownTimeout)
+	if err := lc.Shutdown(context.Background(), conf.App.ShutdownTimeout); err != nil {
+		os.Exit(1)
 	}
 }
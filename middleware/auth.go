@@ -2,40 +2,101 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"final-project/helper"
 	"final-project/helper/response"
+	"log/slog"
 	"net/http"
 	"strings"
 )
 
-func Auth(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var resp = response.New[any](response.Authentication)
+// APITokenAuthenticator resolves API tokens, see NewAuth.
+type APITokenAuthenticator interface {
+	Authenticate(context.Context, string) (helper.Principal, error)
+}
+
+// SessionChecker fails for JWTs whose login session was revoked.
+type SessionChecker interface {
+	Check(context.Context, helper.Principal) error
+}
+
+// NewAuth accepts a JWT or, when apiTokens isn't nil, an API token as the
+// Bearer token and puts the caller into the request context. JWTs are
+// checked against sessions when it isn't nil.
+func NewAuth(apiTokens APITokenAuthenticator, sessions SessionChecker, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var resp = response.New[any](response.Authentication)
+
+			token := r.Header.Get("Authorization")
+			if token == "" {
+				logger.WarnContext(r.Context(), "no token provided")
+				resp.Error(helper.ErrNotLoggedIn).Code(http.StatusUnauthorized).Send(w)
+				return
+			}
+
+			if !strings.HasPrefix(token, "Bearer ") {
+				logger.WarnContext(r.Context(), "invalid token format")
+				resp.Error(helper.ErrNotLoggedIn).Code(http.StatusUnauthorized).Send(w)
+				return
+			}
+
+			token = strings.TrimPrefix(token, "Bearer ")
+
+			if apiTokens != nil && helper.IsAPIToken(token) {
+				principal, err := apiTokens.Authenticate(r.Context(), token)
+				if err != nil {
+					logger.ErrorContext(r.Context(), "failed to verify API token", "error", err.Error())
+					respErr := new(helper.ResponseError)
+					if errors.As(err, &respErr) {
+						resp.Error(respErr).Code(respErr.Code()).Send(w)
+						return
+					}
+					resp.Error(helper.ErrNotLoggedIn).Code(http.StatusUnauthorized).Send(w)
+					return
+				}
 
-		token := r.Header.Get("Authorization")
-		if token == "" {
-			logger.Warn("no token provided")
-			resp.Error(helper.ErrNotLoggedIn).Code(http.StatusUnauthorized).Send(w)
-			return
-		}
+				next.ServeHTTP(w, r.WithContext(helper.ContextWithUser(r.Context(), principal)))
+				return
+			}
 
-		if !strings.HasPrefix(token, "Bearer ") {
-			logger.Warn("invalid token format")
-			resp.Error(helper.ErrNotLoggedIn).Code(http.StatusUnauthorized).Send(w)
-			return
-		}
+			claims, err := helper.VerifyJWT(token)
+			if err != nil {
+				logger.ErrorContext(r.Context(), "failed to verify token", "error", err.Error())
+				resp.Error(helper.ErrNotLoggedIn).Code(http.StatusUnauthorized).Send(w)
+				return
+			}
 
-		token = strings.TrimPrefix(token, "Bearer ")
+			userID, _ := claims.UserID()
+			sessionID, _ := claims.Session()
+			role := claims.Role
+			if role == "" {
+				role = helper.RoleUser
+			}
 
-		claims, err := helper.VerifyJWT(token)
-		if err != nil {
-			logger.Error("failed to verify token", "error", err.Error())
-			resp.Error(helper.ErrNotLoggedIn).Code(http.StatusUnauthorized).Send(w)
-			return
-		}
+			principal := helper.Principal{
+				UserID:    userID,
+				Role:      role,
+				TokenID:   claims.ID,
+				SessionID: sessionID,
+				Scopes:    claims.Scopes(),
+			}
 
-		r = r.WithContext(context.WithValue(r.Context(), helper.UserIDKey, claims["sub"]))
+			if sessions != nil {
+				err := sessions.Check(r.Context(), principal)
+				if err != nil {
+					logger.WarnContext(r.Context(), "session check failed", "error", err.Error(), "session_id", sessionID)
+					respErr := new(helper.ResponseError)
+					if errors.As(err, &respErr) {
+						resp.Error(respErr).Code(respErr.Code()).Send(w)
+						return
+					}
+					resp.Error(helper.ErrNotLoggedIn).Code(http.StatusUnauthorized).Send(w)
+					return
+				}
+			}
 
-		next.ServeHTTP(w, r)
-	})
+			next.ServeHTTP(w, r.WithContext(helper.ContextWithUser(r.Context(), principal)))
+		})
+	}
 }
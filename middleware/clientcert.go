@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"final-project/helper"
+	"final-project/helper/response"
+	"net/http"
+)
+
+var AdminClientCert = NewClientCert(false)
+
+// NewClientCert rejects requests that didn't present a client certificate
+// verified against the server's client CA pool, when required is true.
+func NewClientCert(required bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !required {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+				var resp = response.New[any](response.Default)
+				resp.Error(helper.ErrClientCertRequired).Code(http.StatusForbidden).Send(w)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"context"
+	"final-project/helper"
+	"net"
+	"net/http"
+	"strings"
+)
+
+var ClientIP = NewClientIP(false)
+
+// NewClientIP stores the address and user agent of the client in the request
+// context. With trustProxy set, the last X-Forwarded-For entry is used, which
+// is the one added by the proxy in front of the API.
+func NewClientIP(trustProxy bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				ip = r.RemoteAddr
+			}
+
+			if trustProxy {
+				if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+					hops := strings.Split(forwarded[len(forwarded)-1], ",")
+					if hop := strings.TrimSpace(hops[len(hops)-1]); hop != "" {
+						ip = hop
+					}
+				}
+			}
+
+			ctx := context.WithValue(r.Context(), helper.ClientIPKey, ip)
+			ctx = context.WithValue(ctx, helper.UserAgentKey, r.UserAgent())
+			r = r.WithContext(ctx)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
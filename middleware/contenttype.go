@@ -10,6 +10,11 @@ var AllowedContentType = NewContentTypeMiddleware(map[string]struct{}{
 	"application/json": {},
 })
 
+var AllowedPatchContentType = NewContentTypeMiddleware(map[string]struct{}{
+	"application/json":             {},
+	"application/merge-patch+json": {},
+})
+
 func NewContentTypeMiddleware(allowedContentTypes map[string]struct{}) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var CORS = NewCORS(CORSOptions{})
+
+// CORSOptions lists who may call the API from a browser. An origin is
+// either matched exactly, by "*" or by a wildcard subdomain such as
+// "https://*.example.com". Without any allowed origin no CORS header is
+// sent and browsers keep blocking cross-origin calls.
+type CORSOptions struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+func (o CORSOptions) allowsOrigin(origin string) bool {
+	for _, allowed := range o.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+		if prefix, suffix, ok := strings.Cut(allowed, "*"); ok {
+			if len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) &&
+				!strings.ContainsAny(origin[len(prefix):len(origin)-len(suffix)], "/:") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NewCORS answers preflight requests itself, before they reach the router,
+// and adds the CORS headers to the responses of allowed origins.
+func NewCORS(opts CORSOptions) func(http.Handler) http.Handler {
+	var (
+		methods = strings.Join(opts.AllowedMethods, ", ")
+		headers = strings.Join(opts.AllowedHeaders, ", ")
+		exposed = strings.Join(opts.ExposedHeaders, ", ")
+		maxAge  = strconv.Itoa(int(opts.MaxAge.Seconds()))
+		anyone  = slices.Contains(opts.AllowedOrigins, "*") && !opts.AllowCredentials
+	)
+
+	return func(next http.Handler) http.Handler {
+		if len(opts.AllowedOrigins) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			w.Header().Add("Vary", "Origin")
+			if preflight {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			if origin == "" || !opts.allowsOrigin(origin) {
+				if preflight {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if anyone {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			if opts.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				if exposed != "" {
+					w.Header().Set("Access-Control-Expose-Headers", exposed)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !slices.Contains(opts.AllowedMethods, r.Header.Get("Access-Control-Request-Method")) {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Methods", methods)
+			if headers != "" {
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+			}
+			if opts.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", maxAge)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	handler := NewCORS(CORSOptions{
+		AllowedOrigins: []string{"https://app.example.com", "https://*.preview.example.com"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+		ExposedHeaders: []string{"ETag"},
+		MaxAge:         10 * time.Minute,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	cases := []struct {
+		name       string
+		method     string
+		origin     string
+		preflight  string
+		wantCode   int
+		wantOrigin string
+	}{
+		{"no origin", "GET", "", "", http.StatusTeapot, ""},
+		{"allowed origin", "GET", "https://app.example.com", "", http.StatusTeapot, "https://app.example.com"},
+		{"wildcard subdomain", "GET", "https://pr-1.preview.example.com", "", http.StatusTeapot, "https://pr-1.preview.example.com"},
+		{"wildcard doesn't match a path", "GET", "https://evil.com/.preview.example.com", "", http.StatusTeapot, ""},
+		{"other origin", "GET", "https://evil.com", "", http.StatusTeapot, ""},
+		{"preflight", "OPTIONS", "https://app.example.com", "POST", http.StatusNoContent, "https://app.example.com"},
+		{"preflight from other origin", "OPTIONS", "https://evil.com", "POST", http.StatusNoContent, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, "/photos", nil)
+			if tc.origin != "" {
+				r.Header.Set("Origin", tc.origin)
+			}
+			if tc.preflight != "" {
+				r.Header.Set("Access-Control-Request-Method", tc.preflight)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tc.wantCode {
+				t.Errorf("status = %d, want %d", w.Code, tc.wantCode)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tc.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tc.wantOrigin)
+			}
+			if tc.preflight != "" && tc.wantOrigin != "" && w.Header().Get("Access-Control-Max-Age") != "600" {
+				t.Errorf("Access-Control-Max-Age = %q, want 600", w.Header().Get("Access-Control-Max-Age"))
+			}
+		})
+	}
+}
@@ -1,45 +1,78 @@
 package middleware
 
 import (
-	"fmt"
+	"final-project/helper"
+	"final-project/lib/metrics"
 	"log/slog"
 	"net/http"
-	"os"
 	"time"
 )
 
 type wrappedRW struct {
-	code int
+	code  int
+	bytes int
 	http.ResponseWriter
 }
 
 func (wRW *wrappedRW) WriteHeader(code int) {
-	wRW.code = code
+	if wRW.code == 0 {
+		wRW.code = code
+	}
 	wRW.ResponseWriter.WriteHeader(code)
 }
 
-var logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
-	ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
-		if a.Key == slog.TimeKey {
-			a.Value = slog.StringValue(a.Value.Time().Format("02-Jan-2006 15:04:05 -0700"))
-		}
-		return a
-	},
-}))
-
-func Logging(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		wRW := &wrappedRW{
-			ResponseWriter: w,
-		}
-		t0 := time.Now()
-		next.ServeHTTP(wRW, r)
-		if wRW.code < 400 {
-			logger.Info(fmt.Sprintf("%s %s %s", r.Method, r.RequestURI, r.Proto), "remote_addr", r.RemoteAddr, "code", wRW.code, "took", time.Since(t0))
-		} else if wRW.code < 500 {
-			logger.Warn(fmt.Sprintf("%s %s %s", r.Method, r.RequestURI, r.Proto), "remote_addr", r.RemoteAddr, "code", wRW.code, "took", time.Since(t0))
-		} else {
-			logger.Error(fmt.Sprintf("%s %s %s", r.Method, r.RequestURI, r.Proto), "remote_addr", r.RemoteAddr, "code", wRW.code, "took", time.Since(t0))
-		}
-	})
+func (wRW *wrappedRW) Write(b []byte) (int, error) {
+	if wRW.code == 0 {
+		wRW.code = http.StatusOK
+	}
+	n, err := wRW.ResponseWriter.Write(b)
+	wRW.bytes += n
+	return n, err
+}
+
+func (wRW *wrappedRW) Unwrap() http.ResponseWriter {
+	return wRW.ResponseWriter
+}
+
+// NewLogging writes an access log line for every request and records it in
+// the HTTP metrics. It should run inside RequestID and ClientIP so their
+// values end up in the line, and around Route for the route pattern.
+func NewLogging(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			wRW := &wrappedRW{
+				ResponseWriter: w,
+			}
+			ctx, route := contextWithRoute(r.Context())
+			t0 := time.Now()
+			next.ServeHTTP(wRW, r.WithContext(ctx))
+			took := time.Since(t0)
+
+			if wRW.code == 0 {
+				wRW.code = http.StatusOK
+			}
+
+			metrics.ObserveRequest(r.Method, *route, wRW.code, took)
+
+			level := slog.LevelInfo
+			if wRW.code >= 500 {
+				level = slog.LevelError
+			} else if wRW.code >= 400 {
+				level = slog.LevelWarn
+			}
+
+			ip, _ := r.Context().Value(helper.ClientIPKey).(string)
+			logger.LogAttrs(r.Context(), level, "request",
+				slog.String("method", r.Method),
+				slog.String("uri", r.RequestURI),
+				slog.String("route", *route),
+				slog.String("proto", r.Proto),
+				slog.Int("status", wRW.code),
+				slog.Int("bytes", wRW.bytes),
+				slog.Duration("took", took),
+				slog.String("ip", ip),
+				slog.String("user_agent", r.UserAgent()),
+			)
+		})
+	}
 }
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"context"
+	"final-project/helper"
+	"final-project/helper/response"
+	"net/http"
+)
+
+var Preconditions = NewPreconditions(false)
+
+// NewPreconditions stores the If-Match header of the request in its context
+// so services can compare it with the current version of the resource. When
+// required is true, requests without If-Match are rejected.
+func NewPreconditions(required bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ifMatch, ok := r.Header["If-Match"]
+			if !ok || len(ifMatch) == 0 {
+				if required {
+					var resp = response.New[any](response.Default)
+					resp.Error(helper.ErrPreconditionRequired).Code(http.StatusPreconditionRequired).Send(w)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			r = r.WithContext(context.WithValue(r.Context(), helper.IfMatchKey, r.Header.Get("If-Match")))
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
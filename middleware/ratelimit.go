@@ -4,68 +4,54 @@ import (
 	"errors"
 	"final-project/helper"
 	"final-project/helper/response"
+	"final-project/lib/metrics"
+	"final-project/lib/ratelimit"
+	"log/slog"
+	"math"
 	"net/http"
-	"sync"
-	"time"
+	"strconv"
 )
 
-type tokenBucket struct {
-	maxTokens          uint64
-	tokens, refillRate float64
-	lastRefill         time.Time
-	mu                 sync.Mutex
-}
-
-func newBucket(b uint64, r float64) *tokenBucket {
-	return &tokenBucket{
-		maxTokens:  b,
-		tokens:     float64(b),
-		refillRate: r,
-		lastRefill: time.Now(),
-	}
-}
+// NewRateLimit limits requests by the route pattern Route matched them
+// with, so the policy can single out routes. Authenticated requests are
+// counted per user, anonymous ones per client IP. When the limiter itself
+// fails the request is let through, an unavailable backend shouldn't take
+// the API down with it.
+func NewRateLimit(limiter ratelimit.Limiter, policy ratelimit.Policy, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pattern := routeFromContext(r.Context())
+			ip, _ := r.Context().Value(helper.ClientIPKey).(string)
+			key := "ip:" + ip
+			user, ok := helper.UserFromContext(r.Context())
+			if ok {
+				key = "user:" + strconv.FormatUint(user.UserID, 10)
+			}
 
-func (b *tokenBucket) refill() {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-	t := time.Now()
-	tokensToAdd := t.Sub(b.lastRefill).Seconds() * b.refillRate
-	b.tokens = min(float64(b.maxTokens), b.tokens+tokensToAdd)
-	b.lastRefill = t
-}
+			limit, scoped := policy.Resolve(pattern, user.Role)
+			if scoped {
+				key = pattern + "|" + key
+			}
 
-func (b *tokenBucket) allow() bool {
-	b.refill()
-	b.mu.Lock()
-	defer b.mu.Unlock()
-	if b.tokens < 1 {
-		return false
-	}
-	b.tokens--
-	return true
-}
+			res, err := limiter.Allow(r.Context(), key, limit)
+			if err != nil {
+				logger.ErrorContext(r.Context(), "rate limiter failed", "error", err.Error())
+				next.ServeHTTP(w, r)
+				return
+			}
 
-var userMap = new(sync.Map)
+			w.Header().Set("RateLimit-Limit", strconv.FormatUint(res.Limit, 10))
+			w.Header().Set("RateLimit-Remaining", strconv.FormatUint(res.Remaining, 10))
 
-func NewRateLimit(bucketSize uint64, refillRate float64) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			userID := r.Context().Value(helper.UserIDKey)
-			bucket, _ := userMap.LoadOrStore(userID, newBucket(bucketSize, refillRate))
-			if bucket, ok := bucket.(*tokenBucket); !ok {
+			if !res.Allowed {
+				metrics.RateLimited(pattern)
+				w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(res.RetryAfter.Seconds())), 10))
 				var resp = response.New[any](response.Default)
-				resp.Error(helper.ErrInternal).Code(http.StatusInternalServerError).Send(w)
+				resp.Error(errors.New("too many requests")).Code(http.StatusTooManyRequests).Send(w)
 				return
-			} else {
-				if !bucket.allow() {
-					var resp = response.New[any](response.Default)
-					resp.Error(errors.New("too many requests")).Code(http.StatusTooManyRequests).Send(w)
-					return
-				}
-				next.ServeHTTP(w, r)
 			}
+
+			next.ServeHTTP(w, r)
 		})
 	}
 }
-
-var RateLimit = NewRateLimit(100, 5)
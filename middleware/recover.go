@@ -3,19 +3,22 @@ package middleware
 import (
 	"final-project/helper"
 	"final-project/helper/response"
+	"log/slog"
 	"net/http"
 )
 
-func Recover(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var resp = response.New[any](response.Default)
-		defer func() {
-			if r := recover(); r != nil {
-				logger.Error("panic recovered", "cause", r)
-				resp.Error(helper.ErrInternal).Code(http.StatusInternalServerError).Send(w)
-			}
-		}()
+func NewRecover(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var resp = response.New[any](response.Default)
+			defer func() {
+				if rec := recover(); rec != nil {
+					logger.ErrorContext(r.Context(), "panic recovered", "cause", rec)
+					resp.Error(helper.ErrInternal).Code(http.StatusInternalServerError).Send(w)
+				}
+			}()
 
-		next.ServeHTTP(w, r)
-	})
+			next.ServeHTTP(w, r)
+		})
+	}
 }
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"final-project/helper"
+	"net/http"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID keeps the X-Request-ID sent by the client or a proxy in front of
+// the API, or generates one, stores it in the request context for logging
+// and echoes it in the response.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !isValidRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		r = r.WithContext(helper.ContextWithRequestID(r.Context(), id))
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isValidRequestID only accepts IDs that are safe to log and echo.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"final-project/helper"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	cases := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{"generated", "", false},
+		{"from client", "req-42.a:b_c", true},
+		{"unsafe", "evil\nid", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var fromContext string
+			handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fromContext = helper.RequestIDFromContext(r.Context())
+			}))
+
+			r := httptest.NewRequest("GET", "/", nil)
+			if tc.header != "" {
+				r.Header.Set(RequestIDHeader, tc.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			got := w.Header().Get(RequestIDHeader)
+			if got == "" || got != fromContext {
+				t.Fatalf("response ID = %q, context ID = %q, want the same non-empty ID", got, fromContext)
+			}
+			if (got == tc.header) != tc.keep {
+				t.Errorf("ID = %q, kept the client's %q: %t, want %t", got, tc.header, got == tc.header, tc.keep)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"final-project/helper"
+	"final-project/helper/response"
+	"net/http"
+	"slices"
+)
+
+// RequireRole must run after Auth.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, _ := helper.UserFromContext(r.Context())
+			if !slices.Contains(roles, user.Role) {
+				var resp = response.New[any](response.Default)
+				resp.Error(helper.ErrNotAllowed).Code(http.StatusForbidden).Send(w)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
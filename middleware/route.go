@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+)
+
+type routeKey struct{}
+
+// Route looks up the pattern mux matches the request with and hands it to
+// Logging and Tracing, which run outside of StripPrefix and can't see it
+// otherwise, and to RateLimit.
+func Route(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, route := contextWithRoute(r.Context())
+		_, *route = mux.Handler(r)
+		mux.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// contextWithRoute returns the holder already in ctx, so Tracing and Logging
+// share it whichever runs first.
+func contextWithRoute(ctx context.Context) (context.Context, *string) {
+	if route, ok := ctx.Value(routeKey{}).(*string); ok {
+		return ctx, route
+	}
+	route := new(string)
+	return context.WithValue(ctx, routeKey{}, route), route
+}
+
+func routeFromContext(ctx context.Context) string {
+	if route, ok := ctx.Value(routeKey{}).(*string); ok {
+		return *route
+	}
+	return ""
+}
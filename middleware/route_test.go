@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRoute(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /photos/{photoId}", func(w http.ResponseWriter, r *http.Request) {})
+
+	cases := []struct {
+		target string
+		want   string
+	}{
+		{"/api/v1/photos/42", `route="GET /photos/{photoId}"`},
+		{"/api/v1/nothing", `route=""`},
+	}
+
+	for _, tc := range cases {
+		buf := new(bytes.Buffer)
+		handler := NewLogging(slog.New(slog.NewTextHandler(buf, nil)))(http.StripPrefix("/api/v1", Route(mux)))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tc.target, nil))
+
+		if !strings.Contains(buf.String(), tc.want) {
+			t.Errorf("access log for %s = %q, want it to contain %s", tc.target, buf.String(), tc.want)
+		}
+	}
+}
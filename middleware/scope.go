@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"final-project/helper"
+	"final-project/helper/response"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// RequireScope must run after Auth. The 403 names the missing scope, also
+// in WWW-Authenticate as described in RFC 6750.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, _ := helper.UserFromContext(r.Context())
+			if !slices.Contains(user.Scopes, scope) {
+				var resp = response.New[any](response.Default)
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope=%q`, scope))
+				resp.Error(fmt.Errorf("%w: %s", helper.ErrMissingScope, scope)).Code(http.StatusForbidden).Send(w)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"final-project/helper"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequireScope(t *testing.T) {
+	handler := RequireScope(helper.ScopeAccountWrite)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	cases := []struct {
+		name   string
+		scopes []string
+		want   int
+	}{
+		{"has scope", []string{helper.ScopeAccountRead, helper.ScopeAccountWrite}, http.StatusNoContent},
+		{"missing scope", []string{helper.ScopeAccountRead}, http.StatusForbidden},
+		{"no scopes", nil, http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/users/export", nil)
+			r = r.WithContext(helper.ContextWithUser(r.Context(), helper.Principal{UserID: 1, Scopes: tc.scopes}))
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tc.want {
+				t.Fatalf("status = %d, want %d", w.Code, tc.want)
+			}
+			if tc.want != http.StatusForbidden {
+				return
+			}
+
+			want := `Bearer error="insufficient_scope", scope="account:write"`
+			if got := w.Header().Get("WWW-Authenticate"); got != want {
+				t.Errorf("WWW-Authenticate = %q, want %q", got, want)
+			}
+			if !strings.Contains(w.Body.String(), helper.ErrMissingScope.Error()+": account:write") {
+				t.Errorf("body = %s, want it to name the missing scope", w.Body.String())
+			}
+		})
+	}
+}
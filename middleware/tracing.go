@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"final-project/lib/tracing"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts a server span for every request, continuing the trace of
+// the caller when it sends a traceparent header. The span is named after
+// the route pattern once Route has found it.
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, route := contextWithRoute(ctx)
+		ctx, span := tracing.Start(ctx, r.Method, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+			attribute.String("http.request.method", r.Method),
+			attribute.String("url.path", r.URL.Path),
+		))
+		defer span.End()
+
+		wRW := &wrappedRW{
+			ResponseWriter: w,
+		}
+		next.ServeHTTP(wRW, r.WithContext(ctx))
+
+		if wRW.code == 0 {
+			wRW.code = http.StatusOK
+		}
+
+		if *route != "" {
+			span.SetName(*route)
+			span.SetAttributes(attribute.String("http.route", *route))
+		}
+		span.SetAttributes(attribute.Int("http.response.status_code", wRW.code))
+		if wRW.code >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(wRW.code))
+		}
+	})
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+type APIToken struct {
+	ID, UserID uint64
+	Name       string
+	Prefix     string
+	TokenHash  string
+	Scopes     []string
+	CreatedAt  time.Time
+	ExpiresAt  sql.NullTime
+	LastUsedAt sql.NullTime
+	User       User
+}
@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+const (
+	AuditLogin                    = "login"
+	AuditLoginFailed              = "login_failed"
+	AuditAccountLocked            = "account_locked"
+	AuditAccountUnlocked          = "account_unlocked"
+	AuditAccountCreated           = "account_created"
+	AuditEmailChanged             = "email_changed"
+	AuditUsernameChanged          = "username_changed"
+	AuditAccountDeletionScheduled = "account_deletion_scheduled"
+	AuditAccountDeletionCancelled = "account_deletion_cancelled"
+	AuditAccountPurged            = "account_purged"
+	AuditTOTPEnabled              = "totp_enabled"
+	AuditTOTPDisabled             = "totp_disabled"
+	AuditRecoveryCodesRegenerated = "recovery_codes_regenerated"
+	AuditIdentityLinked           = "identity_linked"
+	AuditSessionRevoked           = "session_revoked"
+	AuditAPITokenCreated          = "api_token_created"
+	AuditAPITokenDeleted          = "api_token_deleted"
+	AuditLogLevelChanged          = "log_level_changed"
+)
+
+// AuditEvent records who did what to which account. ActorID is 0 when
+// nobody was logged in or the system acted on its own, UserID is the
+// account the event is about.
+type AuditEvent struct {
+	ID         uint64
+	ActorID    uint64
+	UserID     uint64
+	Action     string
+	TargetType string
+	TargetID   string
+	IP         string
+	UserAgent  string
+	Metadata   map[string]string
+	CreatedAt  time.Time
+}
+
+// AuditFilter narrows down a search of the audit log, zero values match
+// everything. Results are returned newest first, starting below BeforeID
+// when it is set.
+type AuditFilter struct {
+	ActorID    uint64
+	UserID     uint64
+	Action     string
+	TargetType string
+	TargetID   string
+	IP         string
+	Since      time.Time
+	Until      time.Time
+	BeforeID   uint64
+	Limit      uint64
+}
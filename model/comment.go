@@ -1,12 +1,24 @@
 package model
 
-import "time"
+import (
+	"database/sql"
+	"time"
+)
 
 type Comment struct {
 	ID, UserID, PhotoID  uint64
 	Message              string
 	CreatedAt, UpdatedAt time.Time
+	EditedAt             sql.NullTime
 
 	User  User
 	Photo Photo
 }
+
+type CommentRevision struct {
+	ID, CommentID, EditorID uint64
+	Message                 string
+	CreatedAt               time.Time
+
+	Editor User
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	ExportPending    = "pending"
+	ExportProcessing = "processing"
+	ExportReady      = "ready"
+	ExportFailed     = "failed"
+)
+
+type Export struct {
+	ID, UserID  uint64
+	Status      string
+	FilePath    sql.NullString
+	Error       sql.NullString
+	CreatedAt   time.Time
+	CompletedAt sql.NullTime
+}
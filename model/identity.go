@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// UserIdentity links a user to an account at an OIDC provider.
+type UserIdentity struct {
+	Provider, Subject string
+	UserID            uint64
+	Email             string
+	CreatedAt         time.Time
+}
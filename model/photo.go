@@ -10,7 +10,17 @@ type Photo struct {
 	Title, URL           string
 	Caption              sql.NullString
 	CreatedAt, UpdatedAt time.Time
+	EditedAt             sql.NullTime
 
 	User     User
 	Comments []Comment
 }
+
+type PhotoRevision struct {
+	ID, PhotoID, EditorID uint64
+	Title, URL            string
+	Caption               sql.NullString
+	CreatedAt             time.Time
+
+	Editor User
+}
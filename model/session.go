@@ -0,0 +1,16 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Session struct {
+	ID, UserID uint64
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time
+	RevokedAt  sql.NullTime
+}
@@ -1,10 +1,20 @@
 package model
 
-import "time"
+import (
+	"database/sql"
+	"time"
+)
 
 type User struct {
 	ID, Age              uint64
 	Username, Email      string
+	Role                 string
 	Password             []byte
 	CreatedAt, UpdatedAt time.Time
+	DeleteAfter          sql.NullTime
+	FailedLogins         uint64
+	LockedUntil          sql.NullTime
+	TOTPSecret           sql.NullString
+	TOTPEnabled          bool
+	EmailVerified        bool
 }
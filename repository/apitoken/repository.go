@@ -0,0 +1,164 @@
+package apitokenrepository
+
+import (
+	"context"
+	"database/sql"
+	"final-project/model"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type apiTokenRepository struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *apiTokenRepository {
+	return &apiTokenRepository{db}
+}
+
+func (r *apiTokenRepository) Save(ctx context.Context, data model.APIToken) (model.APIToken, error) {
+	var (
+		token model.APIToken
+		stmt  = `
+		INSERT INTO
+			api_token(user_id, name, prefix, token_hash, scopes, expires_at)
+			VALUES($1, $2, $3, $4, $5, $6)
+		RETURNING
+			id,
+			user_id,
+			name,
+			prefix,
+			scopes,
+			created_at,
+			expires_at
+		`
+	)
+
+	row := r.db.QueryRowContext(ctx, stmt, data.UserID, data.Name, data.Prefix, data.TokenHash, pq.Array(data.Scopes), data.ExpiresAt)
+	if err := row.Err(); err != nil {
+		return token, fmt.Errorf("apiTokenRepository.Save: %w", err)
+	}
+
+	err := row.Scan(&token.ID, &token.UserID, &token.Name, &token.Prefix, pq.Array(&token.Scopes), &token.CreatedAt, &token.ExpiresAt)
+	if err != nil {
+		return token, fmt.Errorf("apiTokenRepository.Save: %w", err)
+	}
+
+	return token, nil
+}
+
+func (r *apiTokenRepository) FindByUserID(ctx context.Context, userID uint64) ([]model.APIToken, error) {
+	var (
+		tokens []model.APIToken
+		stmt   = `
+		SELECT
+			id,
+			user_id,
+			name,
+			prefix,
+			scopes,
+			created_at,
+			expires_at,
+			last_used_at
+		FROM api_token
+		WHERE user_id=$1
+		ORDER BY created_at DESC, id DESC
+		`
+	)
+
+	rows, err := r.db.QueryContext(ctx, stmt, userID)
+	if err != nil {
+		return nil, fmt.Errorf("apiTokenRepository.FindByUserID: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var token model.APIToken
+
+		err := rows.Scan(&token.ID, &token.UserID, &token.Name, &token.Prefix, pq.Array(&token.Scopes), &token.CreatedAt, &token.ExpiresAt, &token.LastUsedAt)
+		if err != nil {
+			return nil, fmt.Errorf("apiTokenRepository.FindByUserID: %w", err)
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+func (r *apiTokenRepository) Delete(ctx context.Context, id, userID uint64) error {
+	var (
+		stmt = `
+		DELETE FROM
+			api_token
+		WHERE id=$1 AND user_id=$2
+		`
+	)
+
+	res, err := r.db.ExecContext(ctx, stmt, id, userID)
+	if err != nil {
+		return fmt.Errorf("apiTokenRepository.Delete: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("apiTokenRepository.Delete: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("apiTokenRepository.Delete: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// Use looks up an unexpired token by its hash and records that it was used.
+// Use finds an unexpired token whose owner is neither locked nor scheduled
+// for deletion. last_used_at is only written when it's older than
+// touchBefore, so busy tokens don't turn every request into a write.
+func (r *apiTokenRepository) Use(ctx context.Context, tokenHash string, touchBefore time.Time) (model.APIToken, error) {
+	var (
+		token model.APIToken
+		stmt  = `
+		WITH token AS (
+			SELECT
+				t.id,
+				t.user_id,
+				t.name,
+				t.scopes,
+				u.role
+			FROM api_token t
+			JOIN user_ u ON u.id=t.user_id
+			WHERE t.token_hash=$1
+				AND (t.expires_at IS NULL OR t.expires_at > NOW())
+				AND u.delete_after IS NULL
+				AND (u.locked_until IS NULL OR u.locked_until <= NOW())
+		), touched AS (
+			UPDATE
+				api_token t
+			SET
+				last_used_at=NOW()
+			FROM token
+			WHERE t.id=token.id AND (t.last_used_at IS NULL OR t.last_used_at < $2)
+		)
+		SELECT
+			id,
+			user_id,
+			name,
+			scopes,
+			role
+		FROM token
+		`
+	)
+
+	row := r.db.QueryRowContext(ctx, stmt, tokenHash, touchBefore)
+	if err := row.Err(); err != nil {
+		return token, fmt.Errorf("apiTokenRepository.Use: %w", err)
+	}
+
+	err := row.Scan(&token.ID, &token.UserID, &token.Name, pq.Array(&token.Scopes), &token.User.Role)
+	if err != nil {
+		return token, fmt.Errorf("apiTokenRepository.Use: %w", err)
+	}
+
+	return token, nil
+}
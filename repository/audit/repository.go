@@ -0,0 +1,106 @@
+package auditrepository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"final-project/model"
+	"fmt"
+)
+
+type auditRepository struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *auditRepository {
+	return &auditRepository{db}
+}
+
+func (r *auditRepository) Save(ctx context.Context, data model.AuditEvent) error {
+	var (
+		stmt = `
+		INSERT INTO
+			audit_event(actor_id, user_id, action, target_type, target_id, ip, user_agent, metadata)
+			VALUES(NULLIF($1, 0), NULLIF($2, 0), $3, $4, $5, $6, $7, $8)
+		`
+	)
+
+	metadata := []byte("{}")
+	if len(data.Metadata) > 0 {
+		var err error
+		metadata, err = json.Marshal(data.Metadata)
+		if err != nil {
+			return fmt.Errorf("auditRepository.Save: %w", err)
+		}
+	}
+
+	_, err := r.db.ExecContext(ctx, stmt, int64(data.ActorID), int64(data.UserID), data.Action, data.TargetType, data.TargetID, data.IP, data.UserAgent, string(metadata))
+	if err != nil {
+		return fmt.Errorf("auditRepository.Save: %w", err)
+	}
+
+	return nil
+}
+
+func (r *auditRepository) Find(ctx context.Context, filter model.AuditFilter) ([]model.AuditEvent, error) {
+	var (
+		events []model.AuditEvent
+		stmt   = `
+		SELECT
+			id,
+			COALESCE(actor_id, 0),
+			COALESCE(user_id, 0),
+			action,
+			target_type,
+			target_id,
+			ip,
+			user_agent,
+			metadata,
+			created_at
+		FROM audit_event
+		WHERE ($1::BIGINT = 0 OR actor_id = $1)
+			AND ($2::BIGINT = 0 OR user_id = $2)
+			AND ($3::TEXT = '' OR action = $3)
+			AND ($4::TEXT = '' OR target_type = $4)
+			AND ($5::TEXT = '' OR target_id = $5)
+			AND ($6::TEXT = '' OR ip = $6)
+			AND ($7::TIMESTAMP IS NULL OR created_at >= $7)
+			AND ($8::TIMESTAMP IS NULL OR created_at < $8)
+			AND ($9::BIGINT = 0 OR id < $9)
+		ORDER BY id DESC
+		LIMIT $10
+		`
+	)
+
+	since := sql.NullTime{Time: filter.Since, Valid: !filter.Since.IsZero()}
+	until := sql.NullTime{Time: filter.Until, Valid: !filter.Until.IsZero()}
+
+	rows, err := r.db.QueryContext(ctx, stmt,
+		int64(filter.ActorID), int64(filter.UserID), filter.Action, filter.TargetType, filter.TargetID, filter.IP,
+		since, until, int64(filter.BeforeID), int64(filter.Limit),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("auditRepository.Find: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			event    model.AuditEvent
+			metadata []byte
+		)
+
+		err := rows.Scan(&event.ID, &event.ActorID, &event.UserID, &event.Action, &event.TargetType, &event.TargetID, &event.IP, &event.UserAgent, &metadata, &event.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("auditRepository.Find: %w", err)
+		}
+
+		if err := json.Unmarshal(metadata, &event.Metadata); err != nil {
+			return nil, fmt.Errorf("auditRepository.Find: %w", err)
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
@@ -54,17 +54,19 @@ func (r *commentRepository) FindAll(ctx context.Context) ([]model.Comment, error
 			c.id,
 			c.message,
 			c.photo_id,
-			c.user_id,
+			COALESCE(c.user_id, 0),
 			c.created_at,
 			c.updated_at,
-			u.username,
-			u.email,
+			c.edited_at,
+			COALESCE(u.username, ''),
+			COALESCE(u.email, ''),
 			p.title,
 			p.caption,
 			p.url,
-			p.user_id
+			p.user_id,
+			p.edited_at
 		FROM comment c
-		INNER JOIN user_ u ON c.user_id=u.id
+		LEFT JOIN user_ u ON c.user_id=u.id
 		INNER JOIN photo p ON c.photo_id=p.id
 		ORDER BY c.created_at DESC
 		`
@@ -79,7 +81,7 @@ func (r *commentRepository) FindAll(ctx context.Context) ([]model.Comment, error
 	for rows.Next() {
 		var comment model.Comment
 
-		err := rows.Scan(&comment.ID, &comment.Message, &comment.PhotoID, &comment.UserID, &comment.CreatedAt, &comment.UpdatedAt, &comment.User.Username, &comment.User.Email, &comment.Photo.Title, &comment.Photo.Caption, &comment.Photo.URL, &comment.Photo.UserID)
+		err := rows.Scan(&comment.ID, &comment.Message, &comment.PhotoID, &comment.UserID, &comment.CreatedAt, &comment.UpdatedAt, &comment.EditedAt, &comment.User.Username, &comment.User.Email, &comment.Photo.Title, &comment.Photo.Caption, &comment.Photo.URL, &comment.Photo.UserID, &comment.Photo.EditedAt)
 		if err != nil {
 			return comments, fmt.Errorf("commentRepository.FindAll: %w", err)
 		}
@@ -90,31 +92,45 @@ func (r *commentRepository) FindAll(ctx context.Context) ([]model.Comment, error
 	return comments, nil
 }
 
-func (r *commentRepository) Update(ctx context.Context, data model.Comment) (model.Comment, error) {
+func (r *commentRepository) Update(ctx context.Context, data model.Comment, editorID uint64) (model.Comment, error) {
 	var (
 		comment model.Comment
 		stmt    = `
+		WITH old AS (
+			SELECT
+				id,
+				message
+			FROM comment
+			WHERE id=$2 AND updated_at=$3
+			FOR UPDATE
+		), revision AS (
+			INSERT INTO
+				comment_revision(comment_id, editor_id, message)
+				SELECT id, $4, message FROM old
+		)
 		UPDATE 
 			comment
 		SET 
 			message=$1,
-			updated_at=NOW()
-		WHERE id=$2 AND updated_at=$3
+			updated_at=NOW(),
+			edited_at=NOW()
+		WHERE id=(SELECT id FROM old)
 		RETURNING 
 			id, 
 			message, 
 			photo_id, 
 			user_id, 
-			updated_at
+			updated_at,
+			edited_at
 		`
 	)
 
-	row := r.db.QueryRowContext(ctx, stmt, data.Message, data.ID, data.UpdatedAt)
+	row := r.db.QueryRowContext(ctx, stmt, data.Message, data.ID, data.UpdatedAt, editorID)
 	if err := row.Err(); err != nil {
 		return comment, fmt.Errorf("commentRepository.Update: %w", err)
 	}
 
-	err := row.Scan(&comment.ID, &comment.Message, &comment.PhotoID, &comment.UserID, &comment.UpdatedAt)
+	err := row.Scan(&comment.ID, &comment.Message, &comment.PhotoID, &comment.UserID, &comment.UpdatedAt, &comment.EditedAt)
 	if err != nil {
 		return comment, fmt.Errorf("commentRepository.Update: %w", err)
 	}
@@ -127,11 +143,12 @@ func (r *commentRepository) Delete(ctx context.Context, data model.Comment) erro
 		stmt = `
 		DELETE FROM
 			comment
-		WHERE id=$1 AND user_id=$2
+		WHERE id=$1 AND user_id=$2 AND ($3::timestamp IS NULL OR updated_at=$3)
 		`
 	)
 
-	res, err := r.db.ExecContext(ctx, stmt, data.ID, data.UserID)
+	version := sql.NullTime{Time: data.UpdatedAt, Valid: !data.UpdatedAt.IsZero()}
+	res, err := r.db.ExecContext(ctx, stmt, data.ID, data.UserID, version)
 	if err != nil {
 		return fmt.Errorf("commentRepository.Delete: %w", err)
 	}
@@ -153,17 +170,19 @@ func (r *commentRepository) FindByID(ctx context.Context, id uint64) (model.Comm
 			c.id,
 			c.message,
 			c.photo_id,
-			c.user_id,
+			COALESCE(c.user_id, 0),
 			c.created_at,
 			c.updated_at,
-			u.username,
-			u.email,
+			c.edited_at,
+			COALESCE(u.username, ''),
+			COALESCE(u.email, ''),
 			p.title,
 			p.caption,
 			p.url,
-			p.user_id
+			p.user_id,
+			p.edited_at
 		FROM comment c
-		INNER JOIN user_ u ON c.user_id=u.id
+		LEFT JOIN user_ u ON c.user_id=u.id
 		INNER JOIN photo p ON c.photo_id=p.id
 		WHERE c.id=$1
 		`
@@ -174,7 +193,7 @@ func (r *commentRepository) FindByID(ctx context.Context, id uint64) (model.Comm
 		return comment, fmt.Errorf("commentRepository.FindByID: %w", err)
 	}
 
-	err := row.Scan(&comment.ID, &comment.Message, &comment.PhotoID, &comment.UserID, &comment.CreatedAt, &comment.UpdatedAt, &comment.User.Username, &comment.User.Email, &comment.Photo.Title, &comment.Photo.Caption, &comment.Photo.URL, &comment.Photo.UserID)
+	err := row.Scan(&comment.ID, &comment.Message, &comment.PhotoID, &comment.UserID, &comment.CreatedAt, &comment.UpdatedAt, &comment.EditedAt, &comment.User.Username, &comment.User.Email, &comment.Photo.Title, &comment.Photo.Caption, &comment.Photo.URL, &comment.Photo.UserID, &comment.Photo.EditedAt)
 	if err != nil {
 		return comment, fmt.Errorf("commentRepository.FindByID: %w", err)
 	}
@@ -190,17 +209,19 @@ func (r *commentRepository) FindByPhotoID(ctx context.Context, data model.Photo)
 			c.id,
 			c.message,
 			c.photo_id,
-			c.user_id,
+			COALESCE(c.user_id, 0),
 			c.created_at,
 			c.updated_at,
-			u.username,
-			u.email,
+			c.edited_at,
+			COALESCE(u.username, ''),
+			COALESCE(u.email, ''),
 			p.title,
 			p.caption,
 			p.url,
-			p.user_id
+			p.user_id,
+			p.edited_at
 		FROM comment c
-		INNER JOIN user_ u ON c.user_id=u.id
+		LEFT JOIN user_ u ON c.user_id=u.id
 		INNER JOIN photo p ON c.photo_id=p.id
 		WHERE c.photo_id=$1
 		ORDER BY c.created_at DESC
@@ -215,7 +236,7 @@ func (r *commentRepository) FindByPhotoID(ctx context.Context, data model.Photo)
 	for rows.Next() {
 		var comment model.Comment
 
-		err := rows.Scan(&comment.ID, &comment.Message, &comment.PhotoID, &comment.UserID, &comment.CreatedAt, &comment.UpdatedAt, &comment.User.Username, &comment.User.Email, &comment.Photo.Title, &comment.Photo.Caption, &comment.Photo.URL, &comment.Photo.UserID)
+		err := rows.Scan(&comment.ID, &comment.Message, &comment.PhotoID, &comment.UserID, &comment.CreatedAt, &comment.UpdatedAt, &comment.EditedAt, &comment.User.Username, &comment.User.Email, &comment.Photo.Title, &comment.Photo.Caption, &comment.Photo.URL, &comment.Photo.UserID, &comment.Photo.EditedAt)
 		if err != nil {
 			return comments, fmt.Errorf("commentRepository.FindByPhotoID: %w", err)
 		}
@@ -234,13 +255,15 @@ func (r *commentRepository) FindByUserID(ctx context.Context, id uint64) ([]mode
 			c.id,
 			c.message,
 			c.photo_id,
-			c.user_id,
+			COALESCE(c.user_id, 0),
 			c.created_at,
 			c.updated_at,
+			c.edited_at,
 			p.title,
 			p.caption,
 			p.url,
-			p.user_id
+			p.user_id,
+			p.edited_at
 		FROM comment c
 		INNER JOIN photo p ON c.photo_id=p.id
 		WHERE c.user_id=$1
@@ -256,7 +279,7 @@ func (r *commentRepository) FindByUserID(ctx context.Context, id uint64) ([]mode
 	for rows.Next() {
 		var comment model.Comment
 
-		err := rows.Scan(&comment.ID, &comment.Message, &comment.PhotoID, &comment.UserID, &comment.CreatedAt, &comment.UpdatedAt, &comment.Photo.Title, &comment.Photo.Caption, &comment.Photo.URL, &comment.Photo.UserID)
+		err := rows.Scan(&comment.ID, &comment.Message, &comment.PhotoID, &comment.UserID, &comment.CreatedAt, &comment.UpdatedAt, &comment.EditedAt, &comment.Photo.Title, &comment.Photo.Caption, &comment.Photo.URL, &comment.Photo.UserID, &comment.Photo.EditedAt)
 		if err != nil {
 			return comments, fmt.Errorf("commentRepository.FindByUserID: %w", err)
 		}
@@ -266,3 +289,42 @@ func (r *commentRepository) FindByUserID(ctx context.Context, id uint64) ([]mode
 
 	return comments, nil
 }
+
+func (r *commentRepository) FindRevisions(ctx context.Context, commentID uint64) ([]model.CommentRevision, error) {
+	var (
+		revisions []model.CommentRevision
+		stmt      = `
+		SELECT
+			r.id,
+			r.comment_id,
+			COALESCE(r.editor_id, 0),
+			r.message,
+			r.created_at,
+			COALESCE(u.email, ''),
+			COALESCE(u.username, '')
+		FROM comment_revision r
+		LEFT JOIN user_ u ON r.editor_id=u.id
+		WHERE r.comment_id=$1
+		ORDER BY r.created_at DESC, r.id DESC
+		`
+	)
+
+	rows, err := r.db.QueryContext(ctx, stmt, commentID)
+	if err != nil {
+		return nil, fmt.Errorf("commentRepository.FindRevisions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var revision model.CommentRevision
+
+		err := rows.Scan(&revision.ID, &revision.CommentID, &revision.EditorID, &revision.Message, &revision.CreatedAt, &revision.Editor.Email, &revision.Editor.Username)
+		if err != nil {
+			return nil, fmt.Errorf("commentRepository.FindRevisions: %w", err)
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, nil
+}
@@ -0,0 +1,238 @@
+package exportrepository
+
+import (
+	"context"
+	"database/sql"
+	"final-project/model"
+	"fmt"
+	"time"
+)
+
+type exportRepository struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *exportRepository {
+	return &exportRepository{db}
+}
+
+func (r *exportRepository) Save(ctx context.Context, data model.Export) (model.Export, error) {
+	var (
+		export model.Export
+		stmt   = `
+		INSERT INTO
+			user_export(user_id, status)
+			VALUES($1, $2)
+		RETURNING
+			id,
+			user_id,
+			status,
+			created_at
+		`
+	)
+
+	row := r.db.QueryRowContext(ctx, stmt, data.UserID, data.Status)
+	if err := row.Err(); err != nil {
+		return export, fmt.Errorf("exportRepository.Save: %w", err)
+	}
+
+	err := row.Scan(&export.ID, &export.UserID, &export.Status, &export.CreatedAt)
+	if err != nil {
+		return export, fmt.Errorf("exportRepository.Save: %w", err)
+	}
+
+	return export, nil
+}
+
+func (r *exportRepository) FindByID(ctx context.Context, id uint64) (model.Export, error) {
+	var (
+		export model.Export
+		stmt   = `
+		SELECT
+			id,
+			user_id,
+			status,
+			file_path,
+			error,
+			created_at,
+			completed_at
+		FROM user_export
+		WHERE id=$1
+		`
+	)
+
+	row := r.db.QueryRowContext(ctx, stmt, id)
+	if err := row.Err(); err != nil {
+		return export, fmt.Errorf("exportRepository.FindByID: %w", err)
+	}
+
+	err := row.Scan(&export.ID, &export.UserID, &export.Status, &export.FilePath, &export.Error, &export.CreatedAt, &export.CompletedAt)
+	if err != nil {
+		return export, fmt.Errorf("exportRepository.FindByID: %w", err)
+	}
+
+	return export, nil
+}
+
+// UpdateStatus moves an export on from the status from, it fails with
+// sql.ErrNoRows when the export has been moved on by someone else, like
+// FailStale, in the meantime.
+func (r *exportRepository) UpdateStatus(ctx context.Context, data model.Export, from string) error {
+	var (
+		stmt = `
+		UPDATE
+			user_export
+		SET
+			status=$1,
+			file_path=$2,
+			error=$3,
+			completed_at=$4,
+			updated_at=NOW()
+		WHERE id=$5 AND status=$6
+		`
+	)
+
+	res, err := r.db.ExecContext(ctx, stmt, data.Status, data.FilePath, data.Error, data.CompletedAt, data.ID, from)
+	if err != nil {
+		return fmt.Errorf("exportRepository.UpdateStatus: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("exportRepository.UpdateStatus: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("exportRepository.UpdateStatus: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// Claim moves a pending export to processing, so it's only built once even
+// when it has been queued twice.
+func (r *exportRepository) Claim(ctx context.Context, id uint64) error {
+	var (
+		stmt = `
+		UPDATE
+			user_export
+		SET
+			status='processing',
+			updated_at=NOW()
+		WHERE id=$1 AND status='pending'
+		`
+	)
+
+	res, err := r.db.ExecContext(ctx, stmt, id)
+	if err != nil {
+		return fmt.Errorf("exportRepository.Claim: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("exportRepository.Claim: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("exportRepository.Claim: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+func (r *exportRepository) FindPending(ctx context.Context) ([]model.Export, error) {
+	var (
+		exports []model.Export
+		stmt    = `
+		SELECT
+			id,
+			user_id,
+			status,
+			created_at
+		FROM user_export
+		WHERE status='pending'
+		ORDER BY id
+		`
+	)
+
+	rows, err := r.db.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, fmt.Errorf("exportRepository.FindPending: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var export model.Export
+		err := rows.Scan(&export.ID, &export.UserID, &export.Status, &export.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("exportRepository.FindPending: %w", err)
+		}
+		exports = append(exports, export)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("exportRepository.FindPending: %w", err)
+	}
+
+	return exports, nil
+}
+
+// FailStale fails the exports that are still pending or processing but
+// haven't changed since before, and returns how many there were.
+func (r *exportRepository) FailStale(ctx context.Context, before time.Time, reason string) (int64, error) {
+	var (
+		stmt = `
+		UPDATE
+			user_export
+		SET
+			status='failed',
+			error=$2,
+			completed_at=NOW(),
+			updated_at=NOW()
+		WHERE status IN ('pending', 'processing') AND updated_at < $1
+		`
+	)
+
+	res, err := r.db.ExecContext(ctx, stmt, before, reason)
+	if err != nil {
+		return 0, fmt.Errorf("exportRepository.FailStale: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("exportRepository.FailStale: %w", err)
+	}
+
+	return n, nil
+}
+
+// DeleteExpired deletes the finished exports completed before before and
+// returns the paths of their archives.
+func (r *exportRepository) DeleteExpired(ctx context.Context, before time.Time) ([]string, error) {
+	var (
+		files []string
+		stmt  = `
+		DELETE FROM
+			user_export
+		WHERE status IN ('ready', 'failed') AND completed_at < $1
+		RETURNING
+			file_path
+		`
+	)
+
+	rows, err := r.db.QueryContext(ctx, stmt, before)
+	if err != nil {
+		return nil, fmt.Errorf("exportRepository.DeleteExpired: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var file sql.NullString
+		if err := rows.Scan(&file); err != nil {
+			return nil, fmt.Errorf("exportRepository.DeleteExpired: %w", err)
+		}
+		if file.Valid {
+			files = append(files, file.String)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("exportRepository.DeleteExpired: %w", err)
+	}
+
+	return files, nil
+}
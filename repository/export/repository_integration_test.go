@@ -0,0 +1,113 @@
+//go:build integration
+
+package exportrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"final-project/lib/database"
+	"final-project/model"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// TestExportsIntegration runs against a real database, see the API token
+// repository's integration test for how to start one.
+func TestExportsIntegration(t *testing.T) {
+	dsn := os.Getenv("DATABASE_TEST_DSN")
+	if dsn == "" {
+		t.Fatal("DATABASE_TEST_DSN is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	r := New(db)
+	suffix := time.Now().Format("150405.000000")
+
+	var userID uint64
+	err = db.QueryRow(`INSERT INTO user_(username, email, password, age) VALUES($1, $2, 'x', 20) RETURNING id`,
+		"exports"+suffix, "exports"+suffix+"@example.com").Scan(&userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Exec(`DELETE FROM user_ WHERE id=$1`, userID)
+
+	t.Run("one in progress", func(t *testing.T) {
+		export, err := r.Save(ctx, model.Export{UserID: userID, Status: model.ExportPending})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = r.Save(ctx, model.Export{UserID: userID, Status: model.ExportPending})
+		var pqErr *pq.Error
+		if !errors.As(err, &pqErr) || pqErr.Constraint != "idx_user_export_active" {
+			t.Fatalf("second Save() = %v, want a violation of idx_user_export_active", err)
+		}
+
+		if err := r.Claim(ctx, export.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.FailStale(ctx, time.Now().Add(time.Minute), "timed out"); err != nil {
+			t.Fatal(err)
+		}
+
+		export.Status = model.ExportReady
+		export.FilePath = sql.NullString{String: "/tmp/export.zip", Valid: true}
+		if err := r.UpdateStatus(ctx, export, model.ExportProcessing); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("UpdateStatus() of a failed export = %v, want %v", err, sql.ErrNoRows)
+		}
+		if got, _ := r.FindByID(ctx, export.ID); got.Status != model.ExportFailed {
+			t.Errorf("status = %s, want it to stay %s", got.Status, model.ExportFailed)
+		}
+	})
+
+	t.Run("delete expired", func(t *testing.T) {
+		export, err := r.Save(ctx, model.Export{UserID: userID, Status: model.ExportPending})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Claim(ctx, export.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		export.Status = model.ExportReady
+		export.FilePath = sql.NullString{String: "/tmp/export-" + suffix + ".zip", Valid: true}
+		export.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		if err := r.UpdateStatus(ctx, export, model.ExportProcessing); err != nil {
+			t.Fatal(err)
+		}
+
+		files, err := r.DeleteExpired(ctx, time.Now().Add(-time.Hour))
+		if err != nil || len(files) != 0 {
+			t.Fatalf("DeleteExpired() before the retention = %v, %v, want nothing", files, err)
+		}
+
+		files, err = r.DeleteExpired(ctx, time.Now().Add(time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := false
+		for _, file := range files {
+			found = found || file == export.FilePath.String
+		}
+		if !found {
+			t.Errorf("DeleteExpired() = %v, want it to include %s", files, export.FilePath.String)
+		}
+		if _, err := r.FindByID(ctx, export.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("FindByID() after DeleteExpired() = %v, want %v", err, sql.ErrNoRows)
+		}
+	})
+}
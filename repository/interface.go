@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"final-project/model"
+	"time"
 )
 
 type UserRepository interface {
@@ -12,26 +13,46 @@ type UserRepository interface {
 	Delete(context.Context, uint64) error
 	FindByID(context.Context, uint64) (model.User, error)
 	FindByUsername(context.Context, string) (model.User, error)
+	ScheduleDeletion(context.Context, uint64, time.Time, time.Time) (model.User, error)
+	CancelDeletion(context.Context, uint64) error
+	FindDueForDeletion(context.Context) ([]uint64, error)
+	Purge(context.Context, uint64, bool) ([]string, error)
+	RecordLoginFailure(context.Context, uint64, string, uint64, time.Duration) (model.User, error)
+	CountLoginFailuresByIP(context.Context, string, time.Time) (uint64, error)
+	DeleteLoginFailuresBefore(context.Context, time.Time) error
+	Unlock(context.Context, uint64) error
+	RehashPassword(context.Context, uint64, []byte, []byte) error
+	SetTOTPSecret(context.Context, uint64, string) error
+	EnableTOTP(context.Context, uint64, int64, []string) error
+	DisableTOTP(context.Context, uint64) error
+	ReplaceRecoveryCodes(context.Context, uint64, []string) error
+	UseTOTPStep(context.Context, uint64, int64) error
+	UseRecoveryCode(context.Context, uint64, string) error
+	FindIDByIdentity(context.Context, string, string) (uint64, error)
+	LinkIdentity(context.Context, model.UserIdentity) error
+	SaveWithIdentity(context.Context, model.User, model.UserIdentity) (model.User, error)
 }
 
 type PhotoRepository interface {
 	Save(context.Context, model.Photo) (model.Photo, error)
 	FindAll(context.Context) ([]model.Photo, error)
-	Update(context.Context, model.Photo) (model.Photo, error)
+	Update(context.Context, model.Photo, uint64) (model.Photo, error)
 	Delete(context.Context, model.Photo) error
 	FindByID(context.Context, uint64) (model.Photo, error)
 	FindByUserID(context.Context, uint64) ([]model.Photo, error)
 	FindByUsername(context.Context, string) ([]model.Photo, error)
+	FindRevisions(context.Context, uint64) ([]model.PhotoRevision, error)
 }
 
 type CommentRepository interface {
 	Save(context.Context, model.Comment) (model.Comment, error)
 	FindAll(context.Context) ([]model.Comment, error)
 	FindByPhotoID(context.Context, model.Photo) ([]model.Comment, error)
-	Update(context.Context, model.Comment) (model.Comment, error)
+	Update(context.Context, model.Comment, uint64) (model.Comment, error)
 	Delete(context.Context, model.Comment) error
 	FindByID(context.Context, uint64) (model.Comment, error)
 	FindByUserID(context.Context, uint64) ([]model.Comment, error)
+	FindRevisions(context.Context, uint64) ([]model.CommentRevision, error)
 }
 
 type LikeRepository interface {
@@ -49,3 +70,34 @@ type SocialMediaRepository interface {
 	FindByID(context.Context, uint64) (model.SocialMedia, error)
 	FindByUserID(context.Context, uint64) ([]model.SocialMedia, error)
 }
+
+type ExportRepository interface {
+	Save(context.Context, model.Export) (model.Export, error)
+	FindByID(context.Context, uint64) (model.Export, error)
+	UpdateStatus(context.Context, model.Export, string) error
+	Claim(context.Context, uint64) error
+	FindPending(context.Context) ([]model.Export, error)
+	FailStale(context.Context, time.Time, string) (int64, error)
+	DeleteExpired(context.Context, time.Time) ([]string, error)
+}
+
+type APITokenRepository interface {
+	Save(context.Context, model.APIToken) (model.APIToken, error)
+	FindByUserID(context.Context, uint64) ([]model.APIToken, error)
+	Delete(context.Context, uint64, uint64) error
+	Use(context.Context, string, time.Time) (model.APIToken, error)
+}
+
+type SessionRepository interface {
+	Save(context.Context, model.Session) (model.Session, error)
+	FindActiveByUserID(context.Context, uint64) ([]model.Session, error)
+	Revoke(context.Context, uint64, uint64) error
+	RevokeAllByUserID(context.Context, uint64, uint64) (int64, error)
+	Touch(context.Context, uint64, uint64, time.Time) error
+	DeleteExpiredBefore(context.Context, time.Time) error
+}
+
+type AuditRepository interface {
+	Save(context.Context, model.AuditEvent) error
+	Find(context.Context, model.AuditFilter) ([]model.AuditEvent, error)
+}
@@ -116,7 +116,8 @@ func (r *likeRepository) FindByUserID(ctx context.Context, userID uint64) ([]mod
 			p.title,
 			p.caption,
 			p.url,
-			p.user_id
+			p.user_id,
+			p.edited_at
 		FROM like_ l
 		LEFT JOIN photo p ON l.photo_id=p.id
 		WHERE l.user_id = $1
@@ -132,7 +133,7 @@ func (r *likeRepository) FindByUserID(ctx context.Context, userID uint64) ([]mod
 	for rows.Next() {
 		var like model.Like
 
-		err := rows.Scan(&like.ID, &like.PhotoID, &like.UserID, &like.CreatedAt, &like.Photo.ID, &like.Photo.Title, &like.Photo.Caption, &like.Photo.URL, &like.Photo.UserID)
+		err := rows.Scan(&like.ID, &like.PhotoID, &like.UserID, &like.CreatedAt, &like.Photo.ID, &like.Photo.Title, &like.Photo.Caption, &like.Photo.URL, &like.Photo.UserID, &like.Photo.EditedAt)
 		if err != nil {
 			return nil, fmt.Errorf("likeRepository.FindByUserID: %w", err)
 		}
@@ -57,6 +57,7 @@ func (r *photoRepository) FindAll(ctx context.Context) ([]model.Photo, error) {
 			p.user_id,
 			p.created_at,
 			p.updated_at,
+			p.edited_at,
 			u.email,
 			u.username
 		FROM photo p
@@ -75,7 +76,7 @@ func (r *photoRepository) FindAll(ctx context.Context) ([]model.Photo, error) {
 	for rows.Next() {
 		var photo model.Photo
 
-		err := rows.Scan(&photo.ID, &photo.Title, &photo.Caption, &photo.URL, &photo.UserID, &photo.CreatedAt, &photo.UpdatedAt, &photo.User.Email, &photo.User.Username)
+		err := rows.Scan(&photo.ID, &photo.Title, &photo.Caption, &photo.URL, &photo.UserID, &photo.CreatedAt, &photo.UpdatedAt, &photo.EditedAt, &photo.User.Email, &photo.User.Username)
 		if err != nil {
 			return nil, fmt.Errorf("photoRepository.FindAll: %w", err)
 		}
@@ -86,34 +87,50 @@ func (r *photoRepository) FindAll(ctx context.Context) ([]model.Photo, error) {
 	return photos, nil
 }
 
-func (r *photoRepository) Update(ctx context.Context, data model.Photo) (model.Photo, error) {
+func (r *photoRepository) Update(ctx context.Context, data model.Photo, editorID uint64) (model.Photo, error) {
 	var (
 		photo model.Photo
 		stmt  = `
+		WITH old AS (
+			SELECT
+				id,
+				title,
+				caption,
+				url
+			FROM photo
+			WHERE id=$4 AND updated_at=$5
+			FOR UPDATE
+		), revision AS (
+			INSERT INTO
+				photo_revision(photo_id, editor_id, title, caption, url)
+				SELECT id, $6, title, caption, url FROM old
+		)
 		UPDATE 
 			photo
 		SET 
 			title=$1,
 			caption=$2,
 			url=$3,
-			updated_at=NOW()
-		WHERE id=$4 AND updated_at=$5
+			updated_at=NOW(),
+			edited_at=NOW()
+		WHERE id=(SELECT id FROM old)
 		RETURNING 
 			id, 
 			title, 
 			caption, 
 			url, 
 			user_id, 
-			updated_at
+			updated_at,
+			edited_at
 		`
 	)
 
-	row := r.db.QueryRowContext(ctx, stmt, data.Title, data.Caption, data.URL, data.ID, data.UpdatedAt)
+	row := r.db.QueryRowContext(ctx, stmt, data.Title, data.Caption, data.URL, data.ID, data.UpdatedAt, editorID)
 	if err := row.Err(); err != nil {
 		return photo, fmt.Errorf("photoRepository.Update: %w", err)
 	}
 
-	err := row.Scan(&photo.ID, &photo.Title, &photo.Caption, &photo.URL, &photo.UserID, &photo.UpdatedAt)
+	err := row.Scan(&photo.ID, &photo.Title, &photo.Caption, &photo.URL, &photo.UserID, &photo.UpdatedAt, &photo.EditedAt)
 	if err != nil {
 		return photo, fmt.Errorf("photoRepository.Update: %w", err)
 	}
@@ -126,11 +143,12 @@ func (r *photoRepository) Delete(ctx context.Context, data model.Photo) error {
 		stmt = `
 		DELETE FROM
 			photo
-		WHERE id=$1 AND user_id=$2
+		WHERE id=$1 AND user_id=$2 AND ($3::timestamp IS NULL OR updated_at=$3)
 		`
 	)
 
-	res, err := r.db.ExecContext(ctx, stmt, data.ID, data.UserID)
+	version := sql.NullTime{Time: data.UpdatedAt, Valid: !data.UpdatedAt.IsZero()}
+	res, err := r.db.ExecContext(ctx, stmt, data.ID, data.UserID, version)
 	if err != nil {
 		return fmt.Errorf("photoRepository.Delete: %w", err)
 	}
@@ -156,6 +174,7 @@ func (r *photoRepository) FindByID(ctx context.Context, id uint64) (model.Photo,
 			p.user_id,
 			p.created_at,
 			p.updated_at,
+			p.edited_at,
 			u.email,
 			u.username
 		FROM photo p
@@ -168,7 +187,7 @@ func (r *photoRepository) FindByID(ctx context.Context, id uint64) (model.Photo,
 		return photo, fmt.Errorf("photoRepository.FindByID: %w", err)
 	}
 
-	err := row.Scan(&photo.ID, &photo.Title, &photo.Caption, &photo.URL, &photo.UserID, &photo.CreatedAt, &photo.UpdatedAt, &photo.User.Email, &photo.User.Username)
+	err := row.Scan(&photo.ID, &photo.Title, &photo.Caption, &photo.URL, &photo.UserID, &photo.CreatedAt, &photo.UpdatedAt, &photo.EditedAt, &photo.User.Email, &photo.User.Username)
 	if err != nil {
 		return photo, fmt.Errorf("photoRepository.FindByID: %w", err)
 	}
@@ -188,6 +207,7 @@ func (r *photoRepository) FindByUserID(ctx context.Context, userID uint64) ([]mo
 			p.user_id,
 			p.created_at,
 			p.updated_at,
+			p.edited_at,
 			u.email,
 			u.username
 		FROM photo p
@@ -206,7 +226,7 @@ func (r *photoRepository) FindByUserID(ctx context.Context, userID uint64) ([]mo
 	for rows.Next() {
 		var photo model.Photo
 
-		err := rows.Scan(&photo.ID, &photo.Title, &photo.Caption, &photo.URL, &photo.UserID, &photo.CreatedAt, &photo.UpdatedAt, &photo.User.Email, &photo.User.Username)
+		err := rows.Scan(&photo.ID, &photo.Title, &photo.Caption, &photo.URL, &photo.UserID, &photo.CreatedAt, &photo.UpdatedAt, &photo.EditedAt, &photo.User.Email, &photo.User.Username)
 		if err != nil {
 			return nil, fmt.Errorf("photoRepository.FindByUserID: %w", err)
 		}
@@ -229,6 +249,7 @@ func (r *photoRepository) FindByUsername(ctx context.Context, username string) (
 			p.user_id,
 			p.created_at,
 			p.updated_at,
+			p.edited_at,
 			u.email,
 			u.username
 		FROM photo p
@@ -247,7 +268,7 @@ func (r *photoRepository) FindByUsername(ctx context.Context, username string) (
 	for rows.Next() {
 		var photo model.Photo
 
-		err := rows.Scan(&photo.ID, &photo.Title, &photo.Caption, &photo.URL, &photo.UserID, &photo.CreatedAt, &photo.UpdatedAt, &photo.User.Email, &photo.User.Username)
+		err := rows.Scan(&photo.ID, &photo.Title, &photo.Caption, &photo.URL, &photo.UserID, &photo.CreatedAt, &photo.UpdatedAt, &photo.EditedAt, &photo.User.Email, &photo.User.Username)
 		if err != nil {
 			return nil, fmt.Errorf("photoRepository.FindByUsername: %w", err)
 		}
@@ -257,3 +278,44 @@ func (r *photoRepository) FindByUsername(ctx context.Context, username string) (
 
 	return photos, nil
 }
+
+func (r *photoRepository) FindRevisions(ctx context.Context, photoID uint64) ([]model.PhotoRevision, error) {
+	var (
+		revisions []model.PhotoRevision
+		stmt      = `
+		SELECT
+			r.id,
+			r.photo_id,
+			r.editor_id,
+			r.title,
+			r.caption,
+			r.url,
+			r.created_at,
+			u.email,
+			u.username
+		FROM photo_revision r
+		INNER JOIN user_ u ON r.editor_id=u.id
+		WHERE r.photo_id=$1
+		ORDER BY r.created_at DESC, r.id DESC
+		`
+	)
+
+	rows, err := r.db.QueryContext(ctx, stmt, photoID)
+	if err != nil {
+		return nil, fmt.Errorf("photoRepository.FindRevisions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var revision model.PhotoRevision
+
+		err := rows.Scan(&revision.ID, &revision.PhotoID, &revision.EditorID, &revision.Title, &revision.Caption, &revision.URL, &revision.CreatedAt, &revision.Editor.Email, &revision.Editor.Username)
+		if err != nil {
+			return nil, fmt.Errorf("photoRepository.FindRevisions: %w", err)
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, nil
+}
@@ -0,0 +1,189 @@
+package sessionrepository
+
+import (
+	"context"
+	"database/sql"
+	"final-project/model"
+	"fmt"
+	"time"
+)
+
+type sessionRepository struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *sessionRepository {
+	return &sessionRepository{db}
+}
+
+func (r *sessionRepository) Save(ctx context.Context, data model.Session) (model.Session, error) {
+	var (
+		session model.Session
+		stmt    = `
+		INSERT INTO
+			user_session(user_id, user_agent, ip, expires_at)
+			VALUES($1, $2, $3, $4)
+		RETURNING
+			id,
+			user_id,
+			user_agent,
+			ip,
+			created_at,
+			last_seen_at,
+			expires_at
+		`
+	)
+
+	row := r.db.QueryRowContext(ctx, stmt, data.UserID, data.UserAgent, data.IP, data.ExpiresAt)
+	if err := row.Err(); err != nil {
+		return session, fmt.Errorf("sessionRepository.Save: %w", err)
+	}
+
+	err := row.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IP, &session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt)
+	if err != nil {
+		return session, fmt.Errorf("sessionRepository.Save: %w", err)
+	}
+
+	return session, nil
+}
+
+// FindActiveByUserID returns the sessions that are neither revoked nor
+// expired.
+func (r *sessionRepository) FindActiveByUserID(ctx context.Context, userID uint64) ([]model.Session, error) {
+	var (
+		sessions []model.Session
+		stmt     = `
+		SELECT
+			id,
+			user_id,
+			user_agent,
+			ip,
+			created_at,
+			last_seen_at,
+			expires_at
+		FROM user_session
+		WHERE user_id=$1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_seen_at DESC, id DESC
+		`
+	)
+
+	rows, err := r.db.QueryContext(ctx, stmt, userID)
+	if err != nil {
+		return nil, fmt.Errorf("sessionRepository.FindActiveByUserID: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var session model.Session
+
+		err := rows.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IP, &session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("sessionRepository.FindActiveByUserID: %w", err)
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, id, userID uint64) error {
+	var (
+		stmt = `
+		UPDATE
+			user_session
+		SET
+			revoked_at=NOW()
+		WHERE id=$1 AND user_id=$2 AND revoked_at IS NULL AND expires_at > NOW()
+		`
+	)
+
+	res, err := r.db.ExecContext(ctx, stmt, id, userID)
+	if err != nil {
+		return fmt.Errorf("sessionRepository.Revoke: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("sessionRepository.Revoke: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("sessionRepository.Revoke: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// RevokeAllByUserID revokes every active session of a user but exceptID,
+// which is 0 to revoke all of them.
+func (r *sessionRepository) RevokeAllByUserID(ctx context.Context, userID, exceptID uint64) (int64, error) {
+	var (
+		stmt = `
+		UPDATE
+			user_session
+		SET
+			revoked_at=NOW()
+		WHERE user_id=$1 AND id<>$2 AND revoked_at IS NULL AND expires_at > NOW()
+		`
+	)
+
+	res, err := r.db.ExecContext(ctx, stmt, userID, exceptID)
+	if err != nil {
+		return 0, fmt.Errorf("sessionRepository.RevokeAllByUserID: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("sessionRepository.RevokeAllByUserID: %w", err)
+	}
+
+	return n, nil
+}
+
+// Touch checks that a session is still active, it returns sql.ErrNoRows
+// when it was revoked or has expired. last_seen_at is only written when
+// it's older than touchBefore, so most requests only read.
+func (r *sessionRepository) Touch(ctx context.Context, id, userID uint64, touchBefore time.Time) error {
+	var (
+		stmt = `
+		WITH session AS (
+			SELECT
+				id
+			FROM user_session
+			WHERE id=$1 AND user_id=$2 AND revoked_at IS NULL AND expires_at > NOW()
+		), touched AS (
+			UPDATE
+				user_session s
+			SET
+				last_seen_at=NOW()
+			FROM session
+			WHERE s.id=session.id AND s.last_seen_at < $3
+		)
+		SELECT
+			id
+		FROM session
+		`
+	)
+
+	err := r.db.QueryRowContext(ctx, stmt, id, userID, touchBefore).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("sessionRepository.Touch: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) DeleteExpiredBefore(ctx context.Context, before time.Time) error {
+	var (
+		stmt = `
+		DELETE FROM
+			user_session
+		WHERE expires_at < $1
+		`
+	)
+
+	_, err := r.db.ExecContext(ctx, stmt, before)
+	if err != nil {
+		return fmt.Errorf("sessionRepository.DeleteExpiredBefore: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+//go:build integration
+
+package sessionrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"final-project/lib/database"
+	"final-project/model"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// TestSessionsIntegration runs against a real database, see the API token
+// repository's integration test for how to start one.
+func TestSessionsIntegration(t *testing.T) {
+	dsn := os.Getenv("DATABASE_TEST_DSN")
+	if dsn == "" {
+		t.Fatal("DATABASE_TEST_DSN is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	r := New(db)
+	suffix := time.Now().Format("150405.000000")
+
+	var userID uint64
+	err = db.QueryRow(`INSERT INTO user_(username, email, password, age) VALUES($1, $2, 'x', 20) RETURNING id`,
+		"sessions"+suffix, "sessions"+suffix+"@example.com").Scan(&userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Exec(`DELETE FROM user_ WHERE id=$1`, userID)
+
+	newSession := func(t *testing.T) model.Session {
+		t.Helper()
+		session, err := r.Save(ctx, model.Session{UserID: userID, ExpiresAt: time.Now().Add(time.Hour)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return session
+	}
+
+	lastSeen := func(t *testing.T, id uint64) time.Time {
+		t.Helper()
+		var at time.Time
+		if err := db.QueryRow(`SELECT last_seen_at FROM user_session WHERE id=$1`, id).Scan(&at); err != nil {
+			t.Fatal(err)
+		}
+		return at
+	}
+
+	t.Run("touch", func(t *testing.T) {
+		session := newSession(t)
+		first := lastSeen(t, session.ID)
+
+		// seen within the resolution, no write
+		if err := r.Touch(ctx, session.ID, userID, time.Now().Add(-time.Minute)); err != nil {
+			t.Fatalf("Touch() = %v", err)
+		}
+		if got := lastSeen(t, session.ID); !got.Equal(first) {
+			t.Errorf("last_seen_at = %v, want it unchanged at %v", got, first)
+		}
+
+		if err := r.Touch(ctx, session.ID, userID, time.Now().Add(time.Minute)); err != nil {
+			t.Fatalf("Touch() = %v", err)
+		}
+		if got := lastSeen(t, session.ID); !got.After(first) {
+			t.Errorf("last_seen_at = %v, want it after %v", got, first)
+		}
+
+		if err := r.Touch(ctx, session.ID, userID+1, time.Now()); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Touch() of another user's session = %v, want %v", err, sql.ErrNoRows)
+		}
+	})
+
+	t.Run("revoke all", func(t *testing.T) {
+		kept, other := newSession(t), newSession(t)
+
+		n, err := r.RevokeAllByUserID(ctx, userID, kept.ID)
+		if err != nil {
+			t.Fatalf("RevokeAllByUserID() = %v", err)
+		}
+		if n < 1 {
+			t.Errorf("RevokeAllByUserID() revoked %d sessions, want at least 1", n)
+		}
+
+		if err := r.Touch(ctx, kept.ID, userID, time.Now()); err != nil {
+			t.Errorf("Touch() of the kept session = %v, want nil", err)
+		}
+		if err := r.Touch(ctx, other.ID, userID, time.Now()); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Touch() of a revoked session = %v, want %v", err, sql.ErrNoRows)
+		}
+
+		if _, err := r.RevokeAllByUserID(ctx, userID, 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Touch(ctx, kept.ID, userID, time.Now()); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Touch() after revoking all = %v, want %v", err, sql.ErrNoRows)
+		}
+	})
+}
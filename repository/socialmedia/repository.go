@@ -122,11 +122,12 @@ func (r *socialMediaRepository) Delete(ctx context.Context, data model.SocialMed
 		stmt = `
 		DELETE FROM
 			social_media
-		WHERE id=$1 AND user_id=$2
+		WHERE id=$1 AND user_id=$2 AND ($3::timestamp IS NULL OR updated_at=$3)
 		`
 	)
 
-	res, err := r.db.ExecContext(ctx, stmt, data.ID, data.UserID)
+	version := sql.NullTime{Time: data.UpdatedAt, Valid: !data.UpdatedAt.IsZero()}
+	res, err := r.db.ExecContext(ctx, stmt, data.ID, data.UserID, version)
 	if err != nil {
 		return fmt.Errorf("socialMediaRepository.Delete: %w", err)
 	}
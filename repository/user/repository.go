@@ -5,6 +5,9 @@ import (
 	"database/sql"
 	"final-project/model"
 	"fmt"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 type userRepository struct {
@@ -51,7 +54,16 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (model.U
 		stmt = `
 		SELECT
 			id,
-			password
+			username,
+			email,
+			password,
+			role,
+			delete_after,
+			failed_logins,
+			locked_until,
+			totp_secret,
+			totp_enabled,
+			email_verified
 		FROM user_
 		WHERE email=$1
 		`
@@ -62,7 +74,7 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (model.U
 		return user, fmt.Errorf("userRepository.FindByEmail: %w", err)
 	}
 
-	err := row.Scan(&user.ID, &user.Password)
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role, &user.DeleteAfter, &user.FailedLogins, &user.LockedUntil, &user.TOTPSecret, &user.TOTPEnabled, &user.EmailVerified)
 	if err != nil {
 		return user, fmt.Errorf("userRepository.FindByEmail: %w", err)
 	}
@@ -77,6 +89,7 @@ func (r *userRepository) Update(ctx context.Context, data model.User) (model.Use
 		UPDATE
 			user_
 		SET
+			email_verified=(email_verified AND email=$1),
 			email=$1,
 			username=$2,
 			updated_at=NOW()
@@ -135,7 +148,14 @@ func (r *userRepository) FindByID(ctx context.Context, userID uint64) (model.Use
 			email,
 			age,
 			created_at,
-			updated_at
+			updated_at,
+			role,
+			delete_after,
+			failed_logins,
+			locked_until,
+			totp_secret,
+			totp_enabled,
+			email_verified
 		FROM user_
 		WHERE id=$1
 		`
@@ -146,7 +166,7 @@ func (r *userRepository) FindByID(ctx context.Context, userID uint64) (model.Use
 		return user, fmt.Errorf("userRepository.FindByID: %w", err)
 	}
 
-	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Age, &user.CreatedAt, &user.UpdatedAt)
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Age, &user.CreatedAt, &user.UpdatedAt, &user.Role, &user.DeleteAfter, &user.FailedLogins, &user.LockedUntil, &user.TOTPSecret, &user.TOTPEnabled, &user.EmailVerified)
 	if err != nil {
 		return user, fmt.Errorf("userRepository.FindByID: %w", err)
 	}
@@ -179,3 +199,587 @@ func (r *userRepository) FindByUsername(ctx context.Context, username string) (m
 
 	return user, nil
 }
+
+// ScheduleDeletion only schedules the deletion while the user is still at
+// version updatedAt, unless it's zero.
+func (r *userRepository) ScheduleDeletion(ctx context.Context, userID uint64, updatedAt, deleteAfter time.Time) (model.User, error) {
+	var (
+		user model.User
+		stmt = `
+		UPDATE
+			user_
+		SET
+			delete_after=COALESCE(delete_after, $1)
+		WHERE id=$2 AND ($3::timestamp IS NULL OR updated_at=$3)
+		RETURNING
+			id,
+			delete_after
+		`
+	)
+
+	version := sql.NullTime{Time: updatedAt, Valid: !updatedAt.IsZero()}
+	row := r.db.QueryRowContext(ctx, stmt, deleteAfter, userID, version)
+	if err := row.Err(); err != nil {
+		return user, fmt.Errorf("userRepository.ScheduleDeletion: %w", err)
+	}
+
+	err := row.Scan(&user.ID, &user.DeleteAfter)
+	if err != nil {
+		return user, fmt.Errorf("userRepository.ScheduleDeletion: %w", err)
+	}
+
+	return user, nil
+}
+
+func (r *userRepository) CancelDeletion(ctx context.Context, userID uint64) error {
+	var (
+		stmt = `
+		UPDATE
+			user_
+		SET
+			delete_after=NULL
+		WHERE id=$1
+		`
+	)
+
+	_, err := r.db.ExecContext(ctx, stmt, userID)
+	if err != nil {
+		return fmt.Errorf("userRepository.CancelDeletion: %w", err)
+	}
+
+	return nil
+}
+
+func (r *userRepository) FindDueForDeletion(ctx context.Context) ([]uint64, error) {
+	var (
+		ids  []uint64
+		stmt = `
+		SELECT
+			id
+		FROM user_
+		WHERE delete_after <= NOW()
+		ORDER BY delete_after
+		`
+	)
+
+	rows, err := r.db.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, fmt.Errorf("userRepository.FindDueForDeletion: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("userRepository.FindDueForDeletion: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("userRepository.FindDueForDeletion: %w", err)
+	}
+
+	return ids, nil
+}
+
+// Purge removes a user whose deletion is due, together with everything that
+// cascades from it. With anonymize set, the user's comments and comment
+// revisions are kept and detached from the account instead. It returns the
+// paths of the export archives that belonged to the user.
+func (r *userRepository) Purge(ctx context.Context, userID uint64, anonymize bool) ([]string, error) {
+	var (
+		files         []string
+		anonymizeStmt = `
+		WITH revision AS (
+			UPDATE
+				comment_revision
+			SET
+				editor_id=NULL
+			WHERE editor_id=$1
+		)
+		UPDATE
+			comment
+		SET
+			user_id=NULL
+		WHERE user_id=$1
+		`
+		exportStmt = `
+		DELETE FROM
+			user_export
+		WHERE user_id=$1 AND file_path IS NOT NULL
+		RETURNING
+			file_path
+		`
+		deleteStmt = `
+		DELETE FROM
+			user_
+		WHERE id=$1 AND delete_after <= NOW()
+		`
+	)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("userRepository.Purge: %w", err)
+	}
+	defer tx.Rollback()
+
+	if anonymize {
+		if _, err := tx.ExecContext(ctx, anonymizeStmt, userID); err != nil {
+			return nil, fmt.Errorf("userRepository.Purge: %w", err)
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, exportStmt, userID)
+	if err != nil {
+		return nil, fmt.Errorf("userRepository.Purge: %w", err)
+	}
+	for rows.Next() {
+		var file string
+		if err := rows.Scan(&file); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("userRepository.Purge: %w", err)
+		}
+		files = append(files, file)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("userRepository.Purge: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, deleteStmt, userID)
+	if err != nil {
+		return nil, fmt.Errorf("userRepository.Purge: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("userRepository.Purge: %w", err)
+	} else if n == 0 {
+		return nil, fmt.Errorf("userRepository.Purge: %w", sql.ErrNoRows)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("userRepository.Purge: %w", err)
+	}
+
+	return files, nil
+}
+
+// RecordLoginFailure counts a failed login for the user, if known, and for
+// the client IP. Once the user reaches maxFailures, the account is locked
+// for lockout and the counter starts over.
+func (r *userRepository) RecordLoginFailure(ctx context.Context, userID uint64, ip string, maxFailures uint64, lockout time.Duration) (model.User, error) {
+	var (
+		user        model.User
+		failureStmt = `
+		INSERT INTO
+			login_failure(user_id, ip)
+			VALUES($1, $2)
+		`
+		userStmt = `
+		UPDATE
+			user_
+		SET
+			failed_logins=CASE WHEN failed_logins + 1 >= $1 THEN 0 ELSE failed_logins + 1 END,
+			locked_until=CASE WHEN failed_logins + 1 >= $1 THEN NOW() + $2::DOUBLE PRECISION * INTERVAL '1 second' ELSE locked_until END
+		WHERE id=$3
+		RETURNING
+			id,
+			failed_logins,
+			locked_until
+		`
+	)
+
+	nullUserID := sql.NullInt64{Int64: int64(userID), Valid: userID != 0}
+	_, err := r.db.ExecContext(ctx, failureStmt, nullUserID, ip)
+	if err != nil {
+		return user, fmt.Errorf("userRepository.RecordLoginFailure: %w", err)
+	}
+
+	if userID == 0 {
+		return user, nil
+	}
+
+	row := r.db.QueryRowContext(ctx, userStmt, maxFailures, lockout.Seconds(), userID)
+	if err := row.Err(); err != nil {
+		return user, fmt.Errorf("userRepository.RecordLoginFailure: %w", err)
+	}
+
+	err = row.Scan(&user.ID, &user.FailedLogins, &user.LockedUntil)
+	if err != nil {
+		return user, fmt.Errorf("userRepository.RecordLoginFailure: %w", err)
+	}
+
+	return user, nil
+}
+
+func (r *userRepository) CountLoginFailuresByIP(ctx context.Context, ip string, since time.Time) (uint64, error) {
+	var (
+		count uint64
+		stmt  = `
+		SELECT
+			COUNT(*)
+		FROM login_failure
+		WHERE ip=$1 AND created_at >= $2
+		`
+	)
+
+	err := r.db.QueryRowContext(ctx, stmt, ip, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("userRepository.CountLoginFailuresByIP: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *userRepository) DeleteLoginFailuresBefore(ctx context.Context, before time.Time) error {
+	var (
+		stmt = `
+		DELETE FROM
+			login_failure
+		WHERE created_at < $1
+		`
+	)
+
+	_, err := r.db.ExecContext(ctx, stmt, before)
+	if err != nil {
+		return fmt.Errorf("userRepository.DeleteLoginFailuresBefore: %w", err)
+	}
+
+	return nil
+}
+
+// Unlock clears the lockout and the failed login counter of a user.
+func (r *userRepository) Unlock(ctx context.Context, userID uint64) error {
+	var (
+		stmt = `
+		UPDATE
+			user_
+		SET
+			failed_logins=0,
+			locked_until=NULL
+		WHERE id=$1
+		`
+	)
+
+	res, err := r.db.ExecContext(ctx, stmt, userID)
+	if err != nil {
+		return fmt.Errorf("userRepository.Unlock: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("userRepository.Unlock: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("userRepository.Unlock: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// RehashPassword replaces a password hash with an equivalent one made with
+// new parameters. updated_at is left alone since the password itself didn't
+// change, and nothing is replaced if the hash changed in the meantime.
+func (r *userRepository) RehashPassword(ctx context.Context, userID uint64, oldHash, newHash []byte) error {
+	var (
+		stmt = `
+		UPDATE
+			user_
+		SET
+			password=$3
+		WHERE id=$1 AND password=$2
+		`
+	)
+
+	res, err := r.db.ExecContext(ctx, stmt, userID, oldHash, newHash)
+	if err != nil {
+		return fmt.Errorf("userRepository.RehashPassword: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("userRepository.RehashPassword: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("userRepository.RehashPassword: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// SetTOTPSecret stores a new secret for a user who hasn't enabled two-factor
+// authentication yet. The secret only becomes active through EnableTOTP.
+func (r *userRepository) SetTOTPSecret(ctx context.Context, userID uint64, secret string) error {
+	var (
+		stmt = `
+		UPDATE
+			user_
+		SET
+			totp_secret=$1
+		WHERE id=$2 AND NOT totp_enabled
+		`
+	)
+
+	res, err := r.db.ExecContext(ctx, stmt, secret, userID)
+	if err != nil {
+		return fmt.Errorf("userRepository.SetTOTPSecret: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("userRepository.SetTOTPSecret: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("userRepository.SetTOTPSecret: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+func (r *userRepository) EnableTOTP(ctx context.Context, userID uint64, step int64, codeHashes []string) error {
+	var (
+		stmt = `
+		UPDATE
+			user_
+		SET
+			totp_enabled=TRUE,
+			totp_last_step=$1
+		WHERE id=$2 AND totp_secret IS NOT NULL AND NOT totp_enabled
+		`
+	)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("userRepository.EnableTOTP: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, stmt, step, userID)
+	if err != nil {
+		return fmt.Errorf("userRepository.EnableTOTP: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("userRepository.EnableTOTP: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("userRepository.EnableTOTP: %w", sql.ErrNoRows)
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return fmt.Errorf("userRepository.EnableTOTP: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("userRepository.EnableTOTP: %w", err)
+	}
+
+	return nil
+}
+
+func (r *userRepository) DisableTOTP(ctx context.Context, userID uint64) error {
+	var (
+		stmt = `
+		UPDATE
+			user_
+		SET
+			totp_enabled=FALSE,
+			totp_secret=NULL,
+			totp_last_step=NULL
+		WHERE id=$1
+		`
+	)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("userRepository.DisableTOTP: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, stmt, userID); err != nil {
+		return fmt.Errorf("userRepository.DisableTOTP: %w", err)
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, nil); err != nil {
+		return fmt.Errorf("userRepository.DisableTOTP: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("userRepository.DisableTOTP: %w", err)
+	}
+
+	return nil
+}
+
+func (r *userRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint64, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("userRepository.ReplaceRecoveryCodes: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return fmt.Errorf("userRepository.ReplaceRecoveryCodes: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("userRepository.ReplaceRecoveryCodes: %w", err)
+	}
+
+	return nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID uint64, codeHashes []string) error {
+	var (
+		deleteStmt = `
+		DELETE FROM
+			recovery_code
+		WHERE user_id=$1
+		`
+		insertStmt = `
+		INSERT INTO
+			recovery_code(user_id, code_hash)
+			SELECT $1, UNNEST($2::TEXT[])
+		`
+	)
+
+	if _, err := tx.ExecContext(ctx, deleteStmt, userID); err != nil {
+		return err
+	}
+
+	if len(codeHashes) == 0 {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, insertStmt, userID, pq.Array(codeHashes))
+	return err
+}
+
+// UseTOTPStep marks a time step as used so the same code can't be replayed.
+// It fails with sql.ErrNoRows when the step isn't newer than the last one.
+func (r *userRepository) UseTOTPStep(ctx context.Context, userID uint64, step int64) error {
+	var (
+		stmt = `
+		UPDATE
+			user_
+		SET
+			totp_last_step=$1
+		WHERE id=$2 AND (totp_last_step IS NULL OR totp_last_step < $1)
+		`
+	)
+
+	res, err := r.db.ExecContext(ctx, stmt, step, userID)
+	if err != nil {
+		return fmt.Errorf("userRepository.UseTOTPStep: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("userRepository.UseTOTPStep: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("userRepository.UseTOTPStep: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+func (r *userRepository) UseRecoveryCode(ctx context.Context, userID uint64, codeHash string) error {
+	var (
+		stmt = `
+		UPDATE
+			recovery_code
+		SET
+			used_at=NOW()
+		WHERE user_id=$1 AND code_hash=$2 AND used_at IS NULL
+		`
+	)
+
+	res, err := r.db.ExecContext(ctx, stmt, userID, codeHash)
+	if err != nil {
+		return fmt.Errorf("userRepository.UseRecoveryCode: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("userRepository.UseRecoveryCode: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("userRepository.UseRecoveryCode: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+func (r *userRepository) FindIDByIdentity(ctx context.Context, provider, subject string) (uint64, error) {
+	var (
+		userID uint64
+		stmt   = `
+		SELECT
+			user_id
+		FROM user_identity
+		WHERE provider=$1 AND subject=$2
+		`
+	)
+
+	err := r.db.QueryRowContext(ctx, stmt, provider, subject).Scan(&userID)
+	if err != nil {
+		return userID, fmt.Errorf("userRepository.FindIDByIdentity: %w", err)
+	}
+
+	return userID, nil
+}
+
+func (r *userRepository) LinkIdentity(ctx context.Context, identity model.UserIdentity) error {
+	var (
+		stmt = `
+		INSERT INTO
+			user_identity(provider, subject, user_id, email)
+			VALUES($1, $2, $3, $4)
+		`
+	)
+
+	_, err := r.db.ExecContext(ctx, stmt, identity.Provider, identity.Subject, identity.UserID, identity.Email)
+	if err != nil {
+		return fmt.Errorf("userRepository.LinkIdentity: %w", err)
+	}
+
+	return nil
+}
+
+// SaveWithIdentity creates a user signing up through an OIDC provider and
+// links the identity to it in the same transaction.
+func (r *userRepository) SaveWithIdentity(ctx context.Context, data model.User, identity model.UserIdentity) (model.User, error) {
+	var (
+		user       model.User
+		insertUser = `
+		INSERT INTO
+			user_(username, email, password, age, email_verified)
+			VALUES($1, $2, $3, $4, $5)
+		RETURNING
+			id,
+			username,
+			email,
+			age,
+			role,
+			email_verified
+		`
+		insertIdentity = `
+		INSERT INTO
+			user_identity(provider, subject, user_id, email)
+			VALUES($1, $2, $3, $4)
+		`
+	)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return user, fmt.Errorf("userRepository.SaveWithIdentity: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, insertUser, data.Username, data.Email, data.Password, data.Age, data.EmailVerified).Scan(&user.ID, &user.Username, &user.Email, &user.Age, &user.Role, &user.EmailVerified)
+	if err != nil {
+		return user, fmt.Errorf("userRepository.SaveWithIdentity: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, insertIdentity, identity.Provider, identity.Subject, user.ID, identity.Email)
+	if err != nil {
+		return user, fmt.Errorf("userRepository.SaveWithIdentity: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return user, fmt.Errorf("userRepository.SaveWithIdentity: %w", err)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"database/sql"
+	"final-project/controller"
+	"final-project/helper"
+	"final-project/middleware"
+	apitokenrepository "final-project/repository/apitoken"
+	auditrepository "final-project/repository/audit"
+	apitokenservice "final-project/service/apitoken"
+	auditservice "final-project/service/audit"
+	"log/slog"
+	"net/http"
+)
+
+func InitAPITokenRoutes(r *http.ServeMux, mw Middlewares, db *sql.DB, logger *slog.Logger) {
+	apiTokenRepo := apitokenrepository.New(db)
+	auditService := auditservice.New(auditrepository.New(db), logger)
+	apiTokenService := apitokenservice.New(apiTokenRepo, auditService, logger)
+	apiTokenController := controller.NewAPITokenController(apiTokenService)
+
+	r.Handle("POST /users/tokens", middleware.AllowedContentType(mw.Auth(middleware.RequireScope(helper.ScopeAccountWrite)(mw.RateLimit(http.HandlerFunc(apiTokenController.Create))))))
+	r.Handle("GET /users/tokens", mw.Auth(middleware.RequireScope(helper.ScopeAccountRead)(mw.RateLimit(http.HandlerFunc(apiTokenController.GetAll)))))
+	r.Handle("DELETE /users/tokens/{tokenID}", mw.Auth(middleware.RequireScope(helper.ScopeAccountWrite)(mw.RateLimit(http.HandlerFunc(apiTokenController.Delete)))))
+}
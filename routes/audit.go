@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"database/sql"
+	"final-project/controller"
+	"final-project/helper"
+	"final-project/middleware"
+	auditrepository "final-project/repository/audit"
+	auditservice "final-project/service/audit"
+	"log/slog"
+	"net/http"
+)
+
+func InitAuditRoutes(r *http.ServeMux, mw Middlewares, db *sql.DB, logger *slog.Logger) {
+	auditRepo := auditrepository.New(db)
+	auditService := auditservice.New(auditRepo, logger)
+	auditController := controller.NewAuditController(auditService)
+
+	r.Handle("GET /users/security-events", mw.Auth(middleware.RequireScope(helper.ScopeAccountRead)(mw.RateLimit(http.HandlerFunc(auditController.GetMine)))))
+	r.Handle("GET /admin/audit-events", middleware.AdminClientCert(mw.Auth(middleware.RequireScope(helper.ScopeAdmin)(middleware.RequireRole(helper.RoleAdmin)(mw.RateLimit(http.HandlerFunc(auditController.Search)))))))
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"database/sql"
 	"final-project/controller"
+	"final-project/helper"
 	"final-project/middleware"
 	commentrepository "final-project/repository/comment"
 	photorepository "final-project/repository/photo"
@@ -11,17 +12,19 @@ import (
 	"net/http"
 )
 
-func InitCommentRoutes(r *http.ServeMux, db *sql.DB, logger *slog.Logger) {
+func InitCommentRoutes(r *http.ServeMux, mw Middlewares, db *sql.DB, logger *slog.Logger) {
 	commentRepo := commentrepository.New(db)
 	photoRepo := photorepository.New(db)
 	service := commentservice.New(commentRepo, photoRepo, logger)
 	controller := controller.NewCommentController(service)
 
-	r.Handle("POST /photos/{photoID}/comments", middleware.AllowedContentType(middleware.Auth(middleware.RateLimit(http.HandlerFunc(controller.Create)))))
-	r.Handle("GET /comments", middleware.Auth(middleware.RateLimit(http.HandlerFunc(controller.GetAll))))
-	r.Handle("PUT /comments/{commentID}", middleware.AllowedContentType(middleware.Auth(middleware.RateLimit(http.HandlerFunc(controller.Update)))))
-	r.Handle("DELETE /comments/{commentID}", middleware.Auth(middleware.RateLimit(http.HandlerFunc(controller.Delete))))
-	r.Handle("GET /comments/{commentID}", middleware.Auth(middleware.RateLimit(http.HandlerFunc(controller.GetByID))))
-	r.Handle("GET /photos/{photoID}/comments", middleware.Auth(middleware.RateLimit(http.HandlerFunc(controller.GetByPhotoID))))
-	r.Handle("GET /comments/my", middleware.Auth(middleware.RateLimit(http.HandlerFunc(controller.GetMine))))
+	r.Handle("POST /photos/{photoID}/comments", middleware.AllowedContentType(mw.Auth(middleware.RequireScope(helper.ScopeCommentsWrite)(mw.RateLimit(http.HandlerFunc(controller.Create))))))
+	r.Handle("GET /comments", mw.Auth(middleware.RequireScope(helper.ScopeCommentsRead)(mw.RateLimit(http.HandlerFunc(controller.GetAll)))))
+	r.Handle("PUT /comments/{commentID}", middleware.AllowedContentType(mw.Auth(middleware.RequireScope(helper.ScopeCommentsWrite)(mw.RateLimit(middleware.Preconditions(http.HandlerFunc(controller.Update)))))))
+	r.Handle("PATCH /comments/{commentID}", middleware.AllowedPatchContentType(mw.Auth(middleware.RequireScope(helper.ScopeCommentsWrite)(mw.RateLimit(middleware.Preconditions(http.HandlerFunc(controller.Patch)))))))
+	r.Handle("DELETE /comments/{commentID}", mw.Auth(middleware.RequireScope(helper.ScopeCommentsWrite)(mw.RateLimit(middleware.Preconditions(http.HandlerFunc(controller.Delete))))))
+	r.Handle("GET /comments/{commentID}", mw.Auth(middleware.RequireScope(helper.ScopeCommentsRead)(mw.RateLimit(http.HandlerFunc(controller.GetByID)))))
+	r.Handle("GET /photos/{photoID}/comments", mw.Auth(middleware.RequireScope(helper.ScopeCommentsRead)(mw.RateLimit(http.HandlerFunc(controller.GetByPhotoID)))))
+	r.Handle("GET /comments/{commentID}/revisions", mw.Auth(middleware.RequireScope(helper.ScopeCommentsRead)(mw.RateLimit(http.HandlerFunc(controller.GetRevisions)))))
+	r.Handle("GET /comments/my", mw.Auth(middleware.RequireScope(helper.ScopeCommentsRead)(mw.RateLimit(http.HandlerFunc(controller.GetMine)))))
 }
@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"database/sql"
+	"final-project/controller"
+	"final-project/helper"
+	"final-project/lib/config"
+	"final-project/lib/worker"
+	"final-project/middleware"
+	commentrepository "final-project/repository/comment"
+	exportrepository "final-project/repository/export"
+	likerepository "final-project/repository/like"
+	photorepository "final-project/repository/photo"
+	socialmediarepository "final-project/repository/socialmedia"
+	userrepository "final-project/repository/user"
+	"final-project/service"
+	exportservice "final-project/service/export"
+	"log/slog"
+	"net/http"
+)
+
+// NewExportService is shared by the export routes and InitJobs.
+func NewExportService(db *sql.DB, logger *slog.Logger, pool *worker.Pool, conf config.App) service.ExportService {
+	exportRepo := exportrepository.New(db)
+	userRepo := userrepository.New(db)
+	photoRepo := photorepository.New(db)
+	commentRepo := commentrepository.New(db)
+	likeRepo := likerepository.New(db)
+	socialMediaRepo := socialmediarepository.New(db)
+	return exportservice.New(exportRepo, userRepo, photoRepo, commentRepo, likeRepo, socialMediaRepo, pool, conf.ExportDir, conf.ExportTimeout, conf.ExportRetention, logger)
+}
+
+func InitExportRoutes(r *http.ServeMux, mw Middlewares, exportService service.ExportService, conf config.App) {
+	exportController := controller.NewExportController(exportService, conf.ExportDownloadTimeout)
+
+	r.Handle("POST /users/export", mw.Auth(middleware.RequireScope(helper.ScopeAccountWrite)(mw.RateLimit(http.HandlerFunc(exportController.Create)))))
+	r.Handle("GET /users/export/{exportID}/status", mw.Auth(middleware.RequireScope(helper.ScopeAccountRead)(mw.RateLimit(http.HandlerFunc(exportController.GetByID)))))
+	r.Handle("GET /users/export/{exportID}/download", mw.Auth(middleware.RequireScope(helper.ScopeAccountRead)(mw.RateLimit(http.HandlerFunc(exportController.Download)))))
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"final-project/controller"
+	"final-project/lib/health"
+	"log/slog"
+	"net/http"
+)
+
+func InitHealthRoutes(r *http.ServeMux, checker *health.Checker, logger *slog.Logger) {
+	healthController := controller.NewHealthController(checker, logger)
+
+	r.HandleFunc("GET /healthz", healthController.Live)
+	r.HandleFunc("GET /readyz", healthController.Ready)
+}
@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"context"
+	"database/sql"
+	"final-project/lib/config"
+	"final-project/lib/worker"
+	auditrepository "final-project/repository/audit"
+	sessionrepository "final-project/repository/session"
+	"final-project/service"
+	auditservice "final-project/service/audit"
+	sessionservice "final-project/service/session"
+	"log/slog"
+)
+
+func InitJobs(pool *worker.Pool, db *sql.DB, logger *slog.Logger, conf config.App, userService service.UserService, exportService service.ExportService) error {
+	err := pool.Every(conf.AccountPurgeInterval, userService.PurgeScheduled)
+	if err != nil {
+		return err
+	}
+
+	err = pool.Every(conf.LoginLockout.IPWindow, userService.PurgeLoginFailures)
+	if err != nil {
+		return err
+	}
+
+	err = pool.Every(conf.ExportTimeout/4, exportService.FailStale)
+	if err != nil {
+		return err
+	}
+	exportService.Resume(context.Background())
+
+	err = pool.Every(conf.AccountPurgeInterval, exportService.PurgeExpired)
+	if err != nil {
+		return err
+	}
+
+	auditService := auditservice.New(auditrepository.New(db), logger)
+	sessionService := sessionservice.New(sessionrepository.New(db), auditService, logger)
+	return pool.Every(conf.AccountPurgeInterval, sessionService.PurgeExpired)
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"final-project/controller"
+	"final-project/helper"
+	"log/slog"
+	"net/http"
+)
+
+func InitJWKSRoutes(r *http.ServeMux, keys *helper.KeySet, logger *slog.Logger) {
+	jwksController := controller.NewJWKSController(keys, logger)
+
+	r.HandleFunc("GET /.well-known/jwks.json", jwksController.JWKS)
+}
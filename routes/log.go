@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"database/sql"
+	"final-project/controller"
+	"final-project/helper"
+	"final-project/middleware"
+	auditrepository "final-project/repository/audit"
+	auditservice "final-project/service/audit"
+	"log/slog"
+	"net/http"
+)
+
+func InitLogRoutes(r *http.ServeMux, mw Middlewares, db *sql.DB, level *slog.LevelVar, logger *slog.Logger) {
+	auditService := auditservice.New(auditrepository.New(db), logger)
+	logController := controller.NewLogController(level, auditService, logger)
+
+	r.Handle("GET /admin/log-level", middleware.AdminClientCert(mw.Auth(middleware.RequireScope(helper.ScopeAdmin)(middleware.RequireRole(helper.RoleAdmin)(mw.RateLimit(http.HandlerFunc(logController.Get)))))))
+	r.Handle("PUT /admin/log-level", middleware.AllowedContentType(middleware.AdminClientCert(mw.Auth(middleware.RequireScope(helper.ScopeAdmin)(middleware.RequireRole(helper.RoleAdmin)(mw.RateLimit(http.HandlerFunc(logController.Update))))))))
+}
@@ -0,0 +1,10 @@
+package routes
+
+import "net/http"
+
+// Middlewares are the route middlewares main builds from the config, see
+// NewAuth and middleware.NewRateLimit.
+type Middlewares struct {
+	Auth      func(http.Handler) http.Handler
+	RateLimit func(http.Handler) http.Handler
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"database/sql"
 	"final-project/controller"
+	"final-project/helper"
 	"final-project/middleware"
 	photorepository "final-project/repository/photo"
 	userrepository "final-project/repository/user"
@@ -11,17 +12,19 @@ import (
 	"net/http"
 )
 
-func InitPhotoRoutes(r *http.ServeMux, db *sql.DB, logger *slog.Logger) {
+func InitPhotoRoutes(r *http.ServeMux, mw Middlewares, db *sql.DB, logger *slog.Logger) {
 	userRepo := userrepository.New(db)
 	photoRepo := photorepository.New(db)
 	service := photoservice.New(userRepo, photoRepo, logger)
 	controller := controller.NewPhotoController(service)
 
-	r.Handle("POST /photos", middleware.AllowedContentType(middleware.Auth(middleware.RateLimit(http.HandlerFunc(controller.Create)))))
-	r.Handle("GET /photos", middleware.Auth(middleware.RateLimit(http.HandlerFunc(controller.GetAll))))
-	r.Handle("PUT /photos/{photoID}", middleware.AllowedContentType(middleware.Auth(middleware.RateLimit(http.HandlerFunc(controller.Update)))))
-	r.Handle("DELETE /photos/{photoID}", middleware.Auth(middleware.RateLimit(http.HandlerFunc(controller.Delete))))
-	r.Handle("GET /photos/{photoID}", middleware.Auth(middleware.RateLimit(http.HandlerFunc(controller.GetByID))))
-	r.Handle("GET /photos/my", middleware.Auth(middleware.RateLimit(http.HandlerFunc(controller.GetMine))))
-	r.Handle("GET /users/{username}/photos", middleware.Auth(middleware.RateLimit(http.HandlerFunc(controller.GetByUsername))))
+	r.Handle("POST /photos", middleware.AllowedContentType(mw.Auth(middleware.RequireScope(helper.ScopePhotosWrite)(mw.RateLimit(http.HandlerFunc(controller.Create))))))
+	r.Handle("GET /photos", mw.Auth(middleware.RequireScope(helper.ScopePhotosRead)(mw.RateLimit(http.HandlerFunc(controller.GetAll)))))
+	r.Handle("PUT /photos/{photoID}", middleware.AllowedContentType(mw.Auth(middleware.RequireScope(helper.ScopePhotosWrite)(mw.RateLimit(middleware.Preconditions(http.HandlerFunc(controller.Update)))))))
+	r.Handle("PATCH /photos/{photoID}", middleware.AllowedPatchContentType(mw.Auth(middleware.RequireScope(helper.ScopePhotosWrite)(mw.RateLimit(middleware.Preconditions(http.HandlerFunc(controller.Patch)))))))
+	r.Handle("DELETE /photos/{photoID}", mw.Auth(middleware.RequireScope(helper.ScopePhotosWrite)(mw.RateLimit(middleware.Preconditions(http.HandlerFunc(controller.Delete))))))
+	r.Handle("GET /photos/{photoID}", mw.Auth(middleware.RequireScope(helper.ScopePhotosRead)(mw.RateLimit(http.HandlerFunc(controller.GetByID)))))
+	r.Handle("GET /photos/my", mw.Auth(middleware.RequireScope(helper.ScopePhotosRead)(mw.RateLimit(http.HandlerFunc(controller.GetMine)))))
+	r.Handle("GET /photos/{photoID}/revisions", mw.Auth(middleware.RequireScope(helper.ScopePhotosRead)(mw.RateLimit(http.HandlerFunc(controller.GetRevisions)))))
+	r.Handle("GET /users/{username}/photos", mw.Auth(middleware.RequireScope(helper.ScopePhotosRead)(mw.RateLimit(http.HandlerFunc(controller.GetByUsername)))))
 }
@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"database/sql"
+	"final-project/controller"
+	"final-project/helper"
+	"final-project/middleware"
+	apitokenrepository "final-project/repository/apitoken"
+	auditrepository "final-project/repository/audit"
+	sessionrepository "final-project/repository/session"
+	apitokenservice "final-project/service/apitoken"
+	auditservice "final-project/service/audit"
+	sessionservice "final-project/service/session"
+	"log/slog"
+	"net/http"
+)
+
+func InitSessionRoutes(r *http.ServeMux, mw Middlewares, db *sql.DB, logger *slog.Logger) {
+	sessionRepo := sessionrepository.New(db)
+	auditService := auditservice.New(auditrepository.New(db), logger)
+	sessionService := sessionservice.New(sessionRepo, auditService, logger)
+	sessionController := controller.NewSessionController(sessionService)
+
+	r.Handle("GET /users/sessions", mw.Auth(middleware.RequireScope(helper.ScopeAccountRead)(mw.RateLimit(http.HandlerFunc(sessionController.GetAll)))))
+	r.Handle("DELETE /users/sessions/{sessionID}", mw.Auth(middleware.RequireScope(helper.ScopeAccountWrite)(mw.RateLimit(http.HandlerFunc(sessionController.Delete)))))
+}
+
+// NewAuth returns the authentication middleware accepting both JWTs and
+// API tokens and enforcing session revocation.
+func NewAuth(db *sql.DB, logger *slog.Logger) func(http.Handler) http.Handler {
+	auditService := auditservice.New(auditrepository.New(db), logger)
+	apiTokenService := apitokenservice.New(apitokenrepository.New(db), auditService, logger)
+	sessionService := sessionservice.New(sessionrepository.New(db), auditService, logger)
+
+	return middleware.NewAuth(apiTokenService, sessionService, logger)
+}
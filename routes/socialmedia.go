@@ -3,6 +3,7 @@ package routes
 import (
 	"database/sql"
 	"final-project/controller"
+	"final-project/helper"
 	"final-project/middleware"
 	socialmediarepository "final-project/repository/socialmedia"
 	socialmediaservice "final-project/service/socialmedia"
@@ -10,15 +11,16 @@ import (
 	"net/http"
 )
 
-func InitSocialMediaRoutes(r *http.ServeMux, db *sql.DB, logger *slog.Logger) {
+func InitSocialMediaRoutes(r *http.ServeMux, mw Middlewares, db *sql.DB, logger *slog.Logger) {
 	socialMediaRepo := socialmediarepository.New(db)
 	socialMediaService := socialmediaservice.New(socialMediaRepo, logger)
 	socialMediaController := controller.NewSocialMediaController(socialMediaService)
 
-	r.Handle("POST /socialmedias", middleware.AllowedContentType(middleware.Auth(middleware.RateLimit(http.HandlerFunc(socialMediaController.Create)))))
-	r.Handle("GET /socialmedias", middleware.Auth(middleware.RateLimit(http.HandlerFunc(socialMediaController.GetAll))))
-	r.Handle("PUT /socialmedias/{socialMediaID}", middleware.AllowedContentType(middleware.Auth(middleware.RateLimit(http.HandlerFunc(socialMediaController.Update)))))
-	r.Handle("DELETE /socialmedias/{socialMediaID}", middleware.Auth(middleware.RateLimit(http.HandlerFunc(socialMediaController.Delete))))
-	r.Handle("GET /socialmedias/{socialMediaID}", middleware.Auth(middleware.RateLimit(http.HandlerFunc(socialMediaController.GetByID))))
-	r.Handle("GET /socialmedias/my", middleware.Auth(middleware.RateLimit(http.HandlerFunc(socialMediaController.GetMine))))
+	r.Handle("POST /socialmedias", middleware.AllowedContentType(mw.Auth(middleware.RequireScope(helper.ScopeSocialMediasWrite)(mw.RateLimit(http.HandlerFunc(socialMediaController.Create))))))
+	r.Handle("GET /socialmedias", mw.Auth(middleware.RequireScope(helper.ScopeSocialMediasRead)(mw.RateLimit(http.HandlerFunc(socialMediaController.GetAll)))))
+	r.Handle("PUT /socialmedias/{socialMediaID}", middleware.AllowedContentType(mw.Auth(middleware.RequireScope(helper.ScopeSocialMediasWrite)(mw.RateLimit(middleware.Preconditions(http.HandlerFunc(socialMediaController.Update)))))))
+	r.Handle("PATCH /socialmedias/{socialMediaID}", middleware.AllowedPatchContentType(mw.Auth(middleware.RequireScope(helper.ScopeSocialMediasWrite)(mw.RateLimit(middleware.Preconditions(http.HandlerFunc(socialMediaController.Patch)))))))
+	r.Handle("DELETE /socialmedias/{socialMediaID}", mw.Auth(middleware.RequireScope(helper.ScopeSocialMediasWrite)(mw.RateLimit(middleware.Preconditions(http.HandlerFunc(socialMediaController.Delete))))))
+	r.Handle("GET /socialmedias/{socialMediaID}", mw.Auth(middleware.RequireScope(helper.ScopeSocialMediasRead)(mw.RateLimit(http.HandlerFunc(socialMediaController.GetByID)))))
+	r.Handle("GET /socialmedias/my", mw.Auth(middleware.RequireScope(helper.ScopeSocialMediasRead)(mw.RateLimit(http.HandlerFunc(socialMediaController.GetMine)))))
 }
@@ -3,20 +3,78 @@ package routes
 import (
 	"database/sql"
 	"final-project/controller"
+	"final-project/helper"
+	"final-project/lib/config"
+	"final-project/lib/mail"
+	"final-project/lib/oidc"
+	"final-project/lib/worker"
 	"final-project/middleware"
+	auditrepository "final-project/repository/audit"
+	sessionrepository "final-project/repository/session"
 	userrepository "final-project/repository/user"
+	"final-project/service"
+	auditservice "final-project/service/audit"
 	userservice "final-project/service/user"
 	"log/slog"
 	"net/http"
 )
 
-func InitUserRoutes(r *http.ServeMux, db *sql.DB, logger *slog.Logger) {
+// NewUserService is shared by the user routes and InitJobs.
+func NewUserService(db *sql.DB, logger *slog.Logger, conf config.App, mailer mail.Mailer, pool *worker.Pool) service.UserService {
 	userRepo := userrepository.New(db)
-	userService := userservice.New(userRepo, logger)
+	sessionRepo := sessionrepository.New(db)
+	auditService := auditservice.New(auditrepository.New(db), logger)
+	return userservice.New(userRepo, sessionRepo, auditService, mailer, pool, userOptions(conf), logger)
+}
+
+func InitUserRoutes(r *http.ServeMux, mw Middlewares, userService service.UserService) {
 	userController := controller.NewUserController(userService)
 
-	r.Handle("POST /users/register", middleware.AllowedContentType(http.HandlerFunc(userController.Register)))
-	r.Handle("POST /users/login", middleware.AllowedContentType(http.HandlerFunc(userController.Login)))
-	r.Handle("PUT /users", middleware.AllowedContentType(middleware.Auth(middleware.RateLimit(http.HandlerFunc(userController.Update)))))
-	r.Handle("DELETE /users", middleware.Auth(middleware.RateLimit(http.HandlerFunc(userController.Delete))))
+	r.Handle("POST /users/register", middleware.AllowedContentType(mw.RateLimit(http.HandlerFunc(userController.Register))))
+	r.Handle("POST /users/login", middleware.AllowedContentType(mw.RateLimit(http.HandlerFunc(userController.Login))))
+	r.Handle("GET /users", mw.Auth(middleware.RequireScope(helper.ScopeAccountRead)(mw.RateLimit(http.HandlerFunc(userController.Get)))))
+	r.Handle("PUT /users", middleware.AllowedContentType(mw.Auth(middleware.RequireScope(helper.ScopeAccountWrite)(mw.RateLimit(middleware.Preconditions(http.HandlerFunc(userController.Update)))))))
+	r.Handle("PATCH /users", middleware.AllowedPatchContentType(mw.Auth(middleware.RequireScope(helper.ScopeAccountWrite)(mw.RateLimit(middleware.Preconditions(http.HandlerFunc(userController.Patch)))))))
+	r.Handle("DELETE /users", mw.Auth(middleware.RequireScope(helper.ScopeAccountWrite)(mw.RateLimit(middleware.Preconditions(http.HandlerFunc(userController.Delete))))))
+	r.Handle("POST /users/login/2fa", middleware.AllowedContentType(mw.RateLimit(http.HandlerFunc(userController.LoginTOTP))))
+	r.Handle("POST /users/2fa/setup", mw.Auth(middleware.RequireScope(helper.ScopeAccountWrite)(mw.RateLimit(http.HandlerFunc(userController.SetupTOTP)))))
+	r.Handle("POST /users/2fa/verify", middleware.AllowedContentType(mw.Auth(middleware.RequireScope(helper.ScopeAccountWrite)(mw.RateLimit(http.HandlerFunc(userController.VerifyTOTP))))))
+	r.Handle("POST /users/2fa/disable", middleware.AllowedContentType(mw.Auth(middleware.RequireScope(helper.ScopeAccountWrite)(mw.RateLimit(http.HandlerFunc(userController.DisableTOTP))))))
+	r.Handle("POST /users/2fa/recovery-codes", middleware.AllowedContentType(mw.Auth(middleware.RequireScope(helper.ScopeAccountWrite)(mw.RateLimit(http.HandlerFunc(userController.RegenerateRecoveryCodes))))))
+	r.Handle("GET /users/oidc/{provider}/login", mw.RateLimit(http.HandlerFunc(userController.OIDCLogin)))
+	r.Handle("GET /users/oidc/{provider}/callback", mw.RateLimit(http.HandlerFunc(userController.OIDCCallback)))
+	r.Handle("POST /users/oidc/register", middleware.AllowedContentType(mw.RateLimit(http.HandlerFunc(userController.OIDCRegister))))
+	r.Handle("POST /users/oidc/link", middleware.AllowedContentType(mw.Auth(middleware.RequireScope(helper.ScopeAccountWrite)(mw.RateLimit(http.HandlerFunc(userController.OIDCLink))))))
+	r.Handle("POST /admin/users/{userID}/unlock", middleware.AdminClientCert(mw.Auth(middleware.RequireScope(helper.ScopeAdmin)(middleware.RequireRole(helper.RoleAdmin)(mw.RateLimit(http.HandlerFunc(userController.Unlock)))))))
+}
+
+func userOptions(conf config.App) userservice.Options {
+	return userservice.Options{
+		DeletionDelay:     conf.AccountDeletionDelay,
+		AnonymizeComments: conf.AnonymizeComments,
+		Lockout: userservice.Lockout{
+			MaxFailures:   conf.LoginLockout.MaxFailures,
+			Duration:      conf.LoginLockout.Duration,
+			IPMaxFailures: conf.LoginLockout.IPMaxFailures,
+			IPWindow:      conf.LoginLockout.IPWindow,
+			DelayBase:     conf.LoginLockout.DelayBase,
+			DelayMax:      conf.LoginLockout.DelayMax,
+		},
+		TOTPIssuer:    conf.TOTPIssuer,
+		OIDCProviders: oidcProviders(conf),
+	}
+}
+
+func oidcProviders(conf config.App) map[string]*oidc.Provider {
+	providers := make(map[string]*oidc.Provider, len(conf.OIDCProviders))
+	for name, p := range conf.OIDCProviders {
+		providers[name] = oidc.New(oidc.Config{
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+		})
+	}
+	return providers
 }
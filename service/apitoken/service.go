@@ -0,0 +1,200 @@
+package apitokenservice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"final-project/dto"
+	"final-project/helper"
+	"final-project/lib/tracing"
+	"final-project/model"
+	"final-project/repository"
+	"final-project/service"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type apiTokenService struct {
+	apiTokenRepo repository.APITokenRepository
+	audit        service.AuditRecorder
+	logger       *slog.Logger
+}
+
+func New(apiTokenRepo repository.APITokenRepository, audit service.AuditRecorder, logger *slog.Logger) *apiTokenService {
+	return &apiTokenService{apiTokenRepo, audit, logger}
+}
+
+func (s *apiTokenService) Create(ctx context.Context, data dto.APITokenRequest) (_ dto.APITokenCreateResponse, err error) {
+	ctx, span := tracing.Start(ctx, "apiTokenService.Create")
+	defer tracing.End(span, &err)
+
+	var resp dto.APITokenCreateResponse
+
+	principal, err := s.sessionUser(ctx)
+	if err != nil {
+		return resp, err
+	}
+
+	// a token can't do more than the login that created it
+	for _, scope := range data.Scopes {
+		if !slices.Contains(principal.Scopes, scope) {
+			s.logger.WarnContext(ctx, "API token requested with a scope the caller doesn't hold", "scope", scope)
+			return resp, helper.NewResponseError(helper.ErrMissingScope, http.StatusForbidden)
+		}
+	}
+
+	token, prefix, err := helper.GenerateAPIToken()
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	apiToken := model.APIToken{
+		UserID:    principal.UserID,
+		Name:      data.Name,
+		Prefix:    prefix,
+		TokenHash: helper.HashAPIToken(token),
+		Scopes:    data.Scopes,
+	}
+	if data.ExpiresIn != "" {
+		expiresIn, _ := time.ParseDuration(data.ExpiresIn)
+		apiToken.ExpiresAt = sql.NullTime{Time: time.Now().Add(expiresIn), Valid: true}
+	}
+
+	apiToken, err = s.apiTokenRepo.Save(ctx, apiToken)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	s.audit.Record(ctx, model.AuditEvent{
+		UserID:     principal.UserID,
+		Action:     model.AuditAPITokenCreated,
+		TargetType: "api_token",
+		TargetID:   strconv.FormatUint(apiToken.ID, 10),
+		Metadata:   map[string]string{"name": apiToken.Name, "scopes": strings.Join(apiToken.Scopes, " ")},
+	})
+
+	resp.APITokenResponse = toResponse(apiToken)
+	resp.Token = token
+
+	return resp, nil
+}
+
+func (s *apiTokenService) GetAll(ctx context.Context) (_ []dto.APITokenResponse, err error) {
+	ctx, span := tracing.Start(ctx, "apiTokenService.GetAll")
+	defer tracing.End(span, &err)
+
+	principal, err := s.sessionUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.apiTokenRepo.FindByUserID(ctx, principal.UserID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		return nil, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	resp := make([]dto.APITokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		resp = append(resp, toResponse(token))
+	}
+
+	return resp, nil
+}
+
+func (s *apiTokenService) Delete(ctx context.Context, id uint64) (err error) {
+	ctx, span := tracing.Start(ctx, "apiTokenService.Delete")
+	defer tracing.End(span, &err)
+
+	principal, err := s.sessionUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.apiTokenRepo.Delete(ctx, id, principal.UserID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		if errors.Is(err, sql.ErrNoRows) {
+			return helper.NewResponseError(helper.ErrAPITokenNotFound, http.StatusNotFound)
+		}
+		return helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	s.audit.Record(ctx, model.AuditEvent{
+		UserID:     principal.UserID,
+		Action:     model.AuditAPITokenDeleted,
+		TargetType: "api_token",
+		TargetID:   strconv.FormatUint(id, 10),
+	})
+
+	return nil
+}
+
+// lastUsedResolution is how stale a token's last_used_at may get before
+// Authenticate updates it.
+const lastUsedResolution = time.Minute
+
+// Authenticate resolves an API token sent in the Authorization header to
+// the user it belongs to. Tokens of locked users and of users scheduled for
+// deletion are refused.
+func (s *apiTokenService) Authenticate(ctx context.Context, token string) (_ helper.Principal, err error) {
+	ctx, span := tracing.Start(ctx, "apiTokenService.Authenticate")
+	defer tracing.End(span, &err)
+
+	apiToken, err := s.apiTokenRepo.Use(ctx, helper.HashAPIToken(token), time.Now().Add(-lastUsedResolution))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return helper.Principal{}, helper.NewResponseError(helper.ErrNotLoggedIn, http.StatusUnauthorized)
+		}
+		s.logger.ErrorContext(ctx, err.Error())
+		return helper.Principal{}, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	return helper.Principal{
+		UserID:     apiToken.UserID,
+		Role:       apiToken.User.Role,
+		APITokenID: apiToken.ID,
+		Scopes:     apiToken.Scopes,
+	}, nil
+}
+
+// sessionUser returns the caller, API tokens can't be used to manage API
+// tokens so a leaked one can't mint more.
+func (s *apiTokenService) sessionUser(ctx context.Context) (helper.Principal, error) {
+	principal, ok := helper.UserFromContext(ctx)
+	if !ok {
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
+		return principal, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	if principal.APITokenID != 0 {
+		s.logger.WarnContext(ctx, "API token management with an API token", "api_token_id", principal.APITokenID)
+		return principal, helper.NewResponseError(helper.ErrAPITokenNotAllowed, http.StatusForbidden)
+	}
+
+	return principal, nil
+}
+
+func toResponse(token model.APIToken) dto.APITokenResponse {
+	resp := dto.APITokenResponse{
+		ID:        token.ID,
+		Name:      token.Name,
+		Prefix:    token.Prefix,
+		Scopes:    token.Scopes,
+		CreatedAt: token.CreatedAt,
+	}
+	if token.ExpiresAt.Valid {
+		resp.ExpiresAt = &token.ExpiresAt.Time
+	}
+	if token.LastUsedAt.Valid {
+		resp.LastUsedAt = &token.LastUsedAt.Time
+	}
+
+	return resp
+}
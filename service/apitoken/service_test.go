@@ -0,0 +1,99 @@
+package apitokenservice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"final-project/dto"
+	"final-project/helper"
+	"final-project/model"
+	"final-project/repository"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// tokenRepo serves a single token, the owner checks live in its SQL and are
+// covered by the repository's integration test.
+type tokenRepo struct {
+	repository.APITokenRepository
+
+	token       model.APIToken
+	hash        string
+	touchBefore time.Time
+	saved       []model.APIToken
+}
+
+func (r *tokenRepo) Save(_ context.Context, token model.APIToken) (model.APIToken, error) {
+	token.ID = uint64(len(r.saved) + 1)
+	r.saved = append(r.saved, token)
+	return token, nil
+}
+
+type auditLog struct {
+	events []model.AuditEvent
+}
+
+func (a *auditLog) Record(_ context.Context, event model.AuditEvent) {
+	a.events = append(a.events, event)
+}
+
+func (r *tokenRepo) Use(_ context.Context, hash string, touchBefore time.Time) (model.APIToken, error) {
+	r.touchBefore = touchBefore
+	if hash != r.hash {
+		return model.APIToken{}, fmt.Errorf("apiTokenRepository.Use: %w", sql.ErrNoRows)
+	}
+	return r.token, nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	const token = "mgt_0123456789abcdef"
+	repo := &tokenRepo{
+		token: model.APIToken{ID: 3, UserID: 7, Scopes: []string{helper.ScopePhotosRead}, User: model.User{Role: helper.RoleUser}},
+		hash:  helper.HashAPIToken(token),
+	}
+	s := New(repo, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	principal, err := s.Authenticate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Authenticate() = %v", err)
+	}
+	if principal.UserID != 7 || principal.APITokenID != 3 || principal.Role != helper.RoleUser || len(principal.Scopes) != 1 {
+		t.Errorf("Authenticate() = %+v", principal)
+	}
+	if age := time.Since(repo.touchBefore); age < lastUsedResolution || age > lastUsedResolution+time.Minute {
+		t.Errorf("touchBefore is %v ago, want about %v", age, lastUsedResolution)
+	}
+
+	_, err = s.Authenticate(context.Background(), "mgt_unknown")
+	var respErr *helper.ResponseError
+	if !errors.As(err, &respErr) || respErr.Code() != http.StatusUnauthorized {
+		t.Errorf("Authenticate() with an unknown token = %v, want a %d", err, http.StatusUnauthorized)
+	}
+}
+
+func TestCreateLimitsScopesToTheCaller(t *testing.T) {
+	repo := &tokenRepo{}
+	s := New(repo, &auditLog{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := helper.ContextWithUser(context.Background(), helper.Principal{UserID: 7, Role: helper.RoleAdmin, Scopes: []string{helper.ScopeAccountWrite}})
+
+	_, err := s.Create(ctx, dto.APITokenRequest{Name: "ci", Scopes: []string{helper.ScopeAdmin}})
+	var respErr *helper.ResponseError
+	if !errors.As(err, &respErr) || respErr.Code() != http.StatusForbidden || err.Error() != helper.ErrMissingScope.Error() {
+		t.Errorf("Create() with the admin scope = %v, want a %d", err, http.StatusForbidden)
+	}
+	if len(repo.saved) != 0 {
+		t.Fatalf("saved %+v, want no token", repo.saved)
+	}
+
+	resp, err := s.Create(ctx, dto.APITokenRequest{Name: "ci", Scopes: []string{helper.ScopeAccountWrite}})
+	if err != nil {
+		t.Fatalf("Create() with the caller's scope = %v", err)
+	}
+	if resp.Token == "" || len(repo.saved) != 1 {
+		t.Errorf("Create() = %+v, saved %+v, want a token", resp, repo.saved)
+	}
+}
@@ -0,0 +1,112 @@
+package auditservice
+
+import (
+	"context"
+	"final-project/dto"
+	"final-project/helper"
+	"final-project/lib/tracing"
+	"final-project/model"
+	"final-project/repository"
+	"log/slog"
+	"net/http"
+)
+
+type auditService struct {
+	auditRepo repository.AuditRepository
+	logger    *slog.Logger
+}
+
+func New(auditRepo repository.AuditRepository, logger *slog.Logger) *auditService {
+	return &auditService{auditRepo, logger}
+}
+
+// Record appends an event to the audit log. The actor defaults to the
+// authenticated user and the client address and user agent are taken from
+// the request. A failure is only logged, it never fails the action itself.
+func (s *auditService) Record(ctx context.Context, event model.AuditEvent) {
+	ctx, span := tracing.Start(ctx, "auditService.Record")
+	defer span.End()
+
+	if event.ActorID == 0 {
+		if principal, ok := helper.UserFromContext(ctx); ok {
+			event.ActorID = principal.UserID
+		}
+	}
+	event.IP, _ = ctx.Value(helper.ClientIPKey).(string)
+	event.UserAgent, _ = ctx.Value(helper.UserAgentKey).(string)
+
+	if err := s.auditRepo.Save(context.WithoutCancel(ctx), event); err != nil {
+		s.logger.ErrorContext(ctx, err.Error(), "cause", "record audit event", "action", event.Action)
+	}
+}
+
+// GetMine returns the events about the current user's own account.
+func (s *auditService) GetMine(ctx context.Context, query dto.AuditEventQuery) (_ []dto.AuditEventResponse, err error) {
+	ctx, span := tracing.Start(ctx, "auditService.GetMine")
+	defer tracing.End(span, &err)
+
+	principal, ok := helper.UserFromContext(ctx)
+	if !ok {
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
+		return nil, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	filter := toFilter(query)
+	filter.ActorID = 0
+	filter.UserID = principal.UserID
+
+	return s.find(ctx, filter)
+}
+
+func (s *auditService) Search(ctx context.Context, query dto.AuditEventQuery) (_ []dto.AuditEventResponse, err error) {
+	ctx, span := tracing.Start(ctx, "auditService.Search")
+	defer tracing.End(span, &err)
+
+	return s.find(ctx, toFilter(query))
+}
+
+func (s *auditService) find(ctx context.Context, filter model.AuditFilter) ([]dto.AuditEventResponse, error) {
+	events, err := s.auditRepo.Find(ctx, filter)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		return nil, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	resp := make([]dto.AuditEventResponse, 0, len(events))
+	for _, event := range events {
+		item := dto.AuditEventResponse{
+			ID:         event.ID,
+			Action:     event.Action,
+			TargetType: event.TargetType,
+			TargetID:   event.TargetID,
+			IP:         event.IP,
+			UserAgent:  event.UserAgent,
+			Metadata:   event.Metadata,
+			CreatedAt:  event.CreatedAt,
+		}
+		if event.ActorID != 0 {
+			item.ActorID = &event.ActorID
+		}
+		if event.UserID != 0 {
+			item.UserID = &event.UserID
+		}
+		resp = append(resp, item)
+	}
+
+	return resp, nil
+}
+
+func toFilter(query dto.AuditEventQuery) model.AuditFilter {
+	return model.AuditFilter{
+		ActorID:    query.ActorID,
+		UserID:     query.UserID,
+		Action:     query.Action,
+		TargetType: query.TargetType,
+		TargetID:   query.TargetID,
+		IP:         query.IP,
+		Since:      query.Since,
+		Until:      query.Until,
+		BeforeID:   query.BeforeID,
+		Limit:      query.Limit,
+	}
+}
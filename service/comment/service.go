@@ -6,10 +6,13 @@ import (
 	"errors"
 	"final-project/dto"
 	"final-project/helper"
+	"final-project/lib/metrics"
+	"final-project/lib/tracing"
 	"final-project/model"
 	"final-project/repository"
 	"log/slog"
 	"net/http"
+	"time"
 )
 
 type commentService struct {
@@ -22,15 +25,17 @@ func New(commentRepo repository.CommentRepository, photoRepo repository.PhotoRep
 	return &commentService{commentRepo, photoRepo, logger}
 }
 
-func (s *commentService) Create(ctx context.Context, data dto.CommentRequest) (dto.CommentCreateResponse, error) {
+func (s *commentService) Create(ctx context.Context, data dto.CommentRequest) (_ dto.CommentCreateResponse, err error) {
+	ctx, span := tracing.Start(ctx, "commentService.Create")
+	defer tracing.End(span, &err)
+
 	var (
 		resp dto.CommentCreateResponse
-		err  error
 	)
 
-	userID, ok := ctx.Value(helper.UserIDKey).(float64)
+	principal, ok := helper.UserFromContext(ctx)
 	if !ok {
-		s.logger.ErrorContext(ctx, "userID is not float64", "cause", "ctx.Value(helper.UserIDKey).(float64)")
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
 		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
@@ -45,7 +50,7 @@ func (s *commentService) Create(ctx context.Context, data dto.CommentRequest) (d
 
 	comment := model.Comment{
 		PhotoID: data.PhotoID,
-		UserID:  uint64(userID),
+		UserID:  principal.UserID,
 		Message: data.Message,
 	}
 
@@ -55,6 +60,8 @@ func (s *commentService) Create(ctx context.Context, data dto.CommentRequest) (d
 		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
+	metrics.Count(metrics.EventCommentCreated)
+
 	resp = dto.CommentCreateResponse{
 		ID:        comment.ID,
 		PhotoID:   comment.PhotoID,
@@ -66,7 +73,10 @@ func (s *commentService) Create(ctx context.Context, data dto.CommentRequest) (d
 	return resp, nil
 }
 
-func (s *commentService) GetAll(ctx context.Context) ([]dto.CommentResponse, error) {
+func (s *commentService) GetAll(ctx context.Context) (_ []dto.CommentResponse, err error) {
+	ctx, span := tracing.Start(ctx, "commentService.GetAll")
+	defer tracing.End(span, &err)
+
 	var resp []dto.CommentResponse
 
 	comments, err := s.commentRepo.FindAll(ctx)
@@ -78,7 +88,7 @@ func (s *commentService) GetAll(ctx context.Context) ([]dto.CommentResponse, err
 	resp = make([]dto.CommentResponse, 0, len(comments))
 
 	for _, comment := range comments {
-		resp = append(resp, dto.CommentResponse{
+		item := dto.CommentResponse{
 			ID:        comment.ID,
 			PhotoID:   comment.PhotoID,
 			UserID:    comment.UserID,
@@ -97,17 +107,48 @@ func (s *commentService) GetAll(ctx context.Context) ([]dto.CommentResponse, err
 				URL:     comment.Photo.URL,
 				UserID:  comment.Photo.UserID,
 			},
-		})
+		}
+
+		if comment.EditedAt.Valid {
+			item.Edited = true
+			item.EditedAt = &comment.EditedAt.Time
+		}
+
+		if comment.Photo.EditedAt.Valid {
+			item.Photo.Edited = true
+			item.Photo.EditedAt = &comment.Photo.EditedAt.Time
+		}
+
+		resp = append(resp, item)
 	}
 
 	return resp, nil
 }
 
-func (s *commentService) Update(ctx context.Context, commentID uint64, data dto.CommentRequest) (resp dto.CommentUpdateResponse, err error) {
+func (s *commentService) Update(ctx context.Context, commentID uint64, data dto.CommentRequest) (_ dto.CommentUpdateResponse, err error) {
+	ctx, span := tracing.Start(ctx, "commentService.Update")
+	defer tracing.End(span, &err)
+
+	return s.update(ctx, commentID, func(comment *model.Comment) {
+		comment.Message = data.Message
+	})
+}
+
+func (s *commentService) Patch(ctx context.Context, commentID uint64, data dto.CommentPatchRequest) (_ dto.CommentUpdateResponse, err error) {
+	ctx, span := tracing.Start(ctx, "commentService.Patch")
+	defer tracing.End(span, &err)
+
+	return s.update(ctx, commentID, func(comment *model.Comment) {
+		if data.Message.Set {
+			comment.Message = data.Message.Value
+		}
+	})
+}
 
-	userID, ok := ctx.Value(helper.UserIDKey).(float64)
+func (s *commentService) update(ctx context.Context, commentID uint64, apply func(*model.Comment)) (resp dto.CommentUpdateResponse, err error) {
+	principal, ok := helper.UserFromContext(ctx)
 	if !ok {
-		s.logger.ErrorContext(ctx, "userID is not float64", "cause", "ctx.Value(helper.UserIDKey).(float64)")
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
 		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
@@ -120,14 +161,19 @@ func (s *commentService) Update(ctx context.Context, commentID uint64, data dto.
 		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
-	if comment.UserID != uint64(userID) {
-		s.logger.ErrorContext(ctx, "user is not the owner of the comment", "cause", "comment.UserID != uint64(userID)")
+	if comment.UserID != principal.UserID {
+		s.logger.ErrorContext(ctx, "user is not the owner of the comment", "cause", "comment.UserID != principal.UserID")
 		return resp, helper.NewResponseError(helper.ErrNotAllowed, http.StatusForbidden)
 	}
 
-	comment.Message = data.Message
+	if !helper.CheckIfMatch(ctx, helper.ETag(comment.ID, comment.UpdatedAt)) {
+		s.logger.ErrorContext(ctx, "comment has been modified since it was fetched", "cause", "helper.CheckIfMatch")
+		return resp, helper.NewResponseError(helper.ErrPreconditionFailed, http.StatusPreconditionFailed)
+	}
+
+	apply(&comment)
 
-	comment, err = s.commentRepo.Update(ctx, comment)
+	comment, err = s.commentRepo.Update(ctx, comment, principal.UserID)
 	if err != nil {
 		s.logger.ErrorContext(ctx, err.Error(), "cause", "s.commentRepo.Update")
 		if errors.Is(err, sql.ErrNoRows) {
@@ -144,23 +190,59 @@ func (s *commentService) Update(ctx context.Context, commentID uint64, data dto.
 		UpdatedAt: comment.UpdatedAt,
 	}
 
+	if comment.EditedAt.Valid {
+		resp.Edited = true
+		resp.EditedAt = &comment.EditedAt.Time
+	}
+
 	return resp, nil
 }
 
 func (s *commentService) Delete(ctx context.Context, commentID uint64) (err error) {
-	userID, ok := ctx.Value(helper.UserIDKey).(float64)
+	ctx, span := tracing.Start(ctx, "commentService.Delete")
+	defer tracing.End(span, &err)
+
+	principal, ok := helper.UserFromContext(ctx)
 	if !ok {
-		s.logger.ErrorContext(ctx, "userID is not float64", "cause", "ctx.Value(helper.UserIDKey).(float64)")
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
 		return helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
+	var version time.Time
+	if helper.HasIfMatch(ctx) {
+		comment, err := s.commentRepo.FindByID(ctx, commentID)
+		if err != nil {
+			s.logger.ErrorContext(ctx, err.Error(), "cause", "s.commentRepo.FindByID")
+			if errors.Is(err, sql.ErrNoRows) {
+				return helper.NewResponseError(helper.ErrNotAllowed, http.StatusForbidden)
+			}
+			return helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+		}
+
+		if comment.UserID != principal.UserID {
+			s.logger.ErrorContext(ctx, "user is not the owner of the comment", "cause", "comment.UserID != principal.UserID")
+			return helper.NewResponseError(helper.ErrNotAllowed, http.StatusForbidden)
+		}
+
+		if !helper.CheckIfMatch(ctx, helper.ETag(comment.ID, comment.UpdatedAt)) {
+			s.logger.ErrorContext(ctx, "comment has been modified since it was fetched", "cause", "helper.CheckIfMatch")
+			return helper.NewResponseError(helper.ErrPreconditionFailed, http.StatusPreconditionFailed)
+		}
+		version = comment.UpdatedAt
+	}
+
 	err = s.commentRepo.Delete(ctx, model.Comment{
-		ID:     commentID,
-		UserID: uint64(userID),
+		ID:        commentID,
+		UserID:    principal.UserID,
+		UpdatedAt: version,
 	})
 	if err != nil {
 		s.logger.ErrorContext(ctx, err.Error(), "cause", "s.commentRepo.Delete")
 		if errors.Is(err, sql.ErrNoRows) {
+			if !version.IsZero() {
+				// modified or deleted since the If-Match check above
+				return helper.NewResponseError(helper.ErrPreconditionFailed, http.StatusPreconditionFailed)
+			}
 			return helper.NewResponseError(helper.ErrNotAllowed, http.StatusForbidden)
 		}
 		return helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
@@ -169,7 +251,10 @@ func (s *commentService) Delete(ctx context.Context, commentID uint64) (err erro
 	return nil
 }
 
-func (s *commentService) GetByID(ctx context.Context, commentID uint64) (dto.CommentResponse, error) {
+func (s *commentService) GetByID(ctx context.Context, commentID uint64) (_ dto.CommentResponse, err error) {
+	ctx, span := tracing.Start(ctx, "commentService.GetByID")
+	defer tracing.End(span, &err)
+
 	var resp dto.CommentResponse
 
 	comment, err := s.commentRepo.FindByID(ctx, commentID)
@@ -202,13 +287,26 @@ func (s *commentService) GetByID(ctx context.Context, commentID uint64) (dto.Com
 		},
 	}
 
+	if comment.EditedAt.Valid {
+		resp.Edited = true
+		resp.EditedAt = &comment.EditedAt.Time
+	}
+
+	if comment.Photo.EditedAt.Valid {
+		resp.Photo.Edited = true
+		resp.Photo.EditedAt = &comment.Photo.EditedAt.Time
+	}
+
 	return resp, nil
 }
 
-func (s *commentService) GetByPhotoID(ctx context.Context, photoID uint64) ([]dto.CommentGetByPhotoIDResponse, error) {
+func (s *commentService) GetByPhotoID(ctx context.Context, photoID uint64) (_ []dto.CommentGetByPhotoIDResponse, err error) {
+	ctx, span := tracing.Start(ctx, "commentService.GetByPhotoID")
+	defer tracing.End(span, &err)
+
 	var resp []dto.CommentGetByPhotoIDResponse
 
-	_, err := s.photoRepo.FindByID(ctx, photoID)
+	_, err = s.photoRepo.FindByID(ctx, photoID)
 	if err != nil {
 		s.logger.ErrorContext(ctx, err.Error(), "cause", "s.photoRepo.FindByID")
 		if errors.Is(err, sql.ErrNoRows) {
@@ -226,7 +324,7 @@ func (s *commentService) GetByPhotoID(ctx context.Context, photoID uint64) ([]dt
 	resp = make([]dto.CommentGetByPhotoIDResponse, 0, len(comments))
 
 	for _, comment := range comments {
-		resp = append(resp, dto.CommentGetByPhotoIDResponse{
+		item := dto.CommentGetByPhotoIDResponse{
 			ID:        comment.ID,
 			PhotoID:   comment.PhotoID,
 			UserID:    comment.UserID,
@@ -238,13 +336,23 @@ func (s *commentService) GetByPhotoID(ctx context.Context, photoID uint64) ([]dt
 				Username: comment.User.Username,
 				Email:    comment.User.Email,
 			},
-		})
+		}
+
+		if comment.EditedAt.Valid {
+			item.Edited = true
+			item.EditedAt = &comment.EditedAt.Time
+		}
+
+		resp = append(resp, item)
 	}
 
 	return resp, nil
 }
 
-func (s *commentService) GetByUserID(ctx context.Context, userID uint64) ([]dto.CommentGetByUserIDResponse, error) {
+func (s *commentService) GetByUserID(ctx context.Context, userID uint64) (_ []dto.CommentGetByUserIDResponse, err error) {
+	ctx, span := tracing.Start(ctx, "commentService.GetByUserID")
+	defer tracing.End(span, &err)
+
 	var resp []dto.CommentGetByUserIDResponse
 
 	comments, err := s.commentRepo.FindByUserID(ctx, userID)
@@ -256,7 +364,7 @@ func (s *commentService) GetByUserID(ctx context.Context, userID uint64) ([]dto.
 	resp = make([]dto.CommentGetByUserIDResponse, 0, len(comments))
 
 	for _, comment := range comments {
-		resp = append(resp, dto.CommentGetByUserIDResponse{
+		item := dto.CommentGetByUserIDResponse{
 			ID:        comment.ID,
 			PhotoID:   comment.PhotoID,
 			UserID:    comment.UserID,
@@ -270,6 +378,58 @@ func (s *commentService) GetByUserID(ctx context.Context, userID uint64) ([]dto.
 				URL:     comment.Photo.URL,
 				UserID:  comment.Photo.UserID,
 			},
+		}
+
+		if comment.EditedAt.Valid {
+			item.Edited = true
+			item.EditedAt = &comment.EditedAt.Time
+		}
+
+		if comment.Photo.EditedAt.Valid {
+			item.Photo.Edited = true
+			item.Photo.EditedAt = &comment.Photo.EditedAt.Time
+		}
+
+		resp = append(resp, item)
+	}
+
+	return resp, nil
+}
+
+func (s *commentService) GetRevisions(ctx context.Context, commentID uint64) (_ []dto.CommentRevisionResponse, err error) {
+	ctx, span := tracing.Start(ctx, "commentService.GetRevisions")
+	defer tracing.End(span, &err)
+
+	var resp []dto.CommentRevisionResponse
+
+	_, err = s.commentRepo.FindByID(ctx, commentID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error(), "cause", "s.commentRepo.FindByID")
+		if errors.Is(err, sql.ErrNoRows) {
+			return resp, helper.NewResponseError(helper.ErrCommentNotFound, http.StatusNotFound)
+		}
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	revisions, err := s.commentRepo.FindRevisions(ctx, commentID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error(), "cause", "s.commentRepo.FindRevisions")
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	resp = make([]dto.CommentRevisionResponse, 0, len(revisions))
+
+	for _, revision := range revisions {
+		resp = append(resp, dto.CommentRevisionResponse{
+			ID:        revision.ID,
+			CommentID: revision.CommentID,
+			Message:   revision.Message,
+			EditedAt:  revision.CreatedAt,
+			EditedBy: dto.User{
+				ID:       revision.EditorID,
+				Username: revision.Editor.Username,
+				Email:    revision.Editor.Email,
+			},
 		})
 	}
 
@@ -0,0 +1,461 @@
+package exportservice
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"final-project/dto"
+	"final-project/helper"
+	"final-project/lib/tracing"
+	"final-project/lib/worker"
+	"final-project/model"
+	"final-project/repository"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type exportService struct {
+	exportRepo      repository.ExportRepository
+	userRepo        repository.UserRepository
+	photoRepo       repository.PhotoRepository
+	commentRepo     repository.CommentRepository
+	likeRepo        repository.LikeRepository
+	socialMediaRepo repository.SocialMediaRepository
+	pool            *worker.Pool
+	dir             string
+	timeout         time.Duration
+	retention       time.Duration
+	logger          *slog.Logger
+}
+
+func New(
+	exportRepo repository.ExportRepository,
+	userRepo repository.UserRepository,
+	photoRepo repository.PhotoRepository,
+	commentRepo repository.CommentRepository,
+	likeRepo repository.LikeRepository,
+	socialMediaRepo repository.SocialMediaRepository,
+	pool *worker.Pool,
+	dir string,
+	timeout time.Duration,
+	retention time.Duration,
+	logger *slog.Logger,
+) *exportService {
+	return &exportService{exportRepo, userRepo, photoRepo, commentRepo, likeRepo, socialMediaRepo, pool, dir, timeout, retention, logger}
+}
+
+func (s *exportService) Create(ctx context.Context) (_ dto.ExportResponse, err error) {
+	ctx, span := tracing.Start(ctx, "exportService.Create")
+	defer tracing.End(span, &err)
+
+	var resp dto.ExportResponse
+
+	principal, ok := helper.UserFromContext(ctx)
+	if !ok {
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	export, err := s.exportRepo.Save(ctx, model.Export{UserID: principal.UserID, Status: model.ExportPending})
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		pqErr := new(pq.Error)
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" && pqErr.Constraint == "idx_user_export_active" {
+			return resp, helper.NewResponseError(helper.ErrExportInProgress, http.StatusConflict)
+		}
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	err = s.submit(ctx, export)
+	if err != nil {
+		return resp, helper.NewResponseError(helper.ErrServiceBusy, http.StatusServiceUnavailable)
+	}
+
+	return toResponse(export), nil
+}
+
+// Resume queues the exports left pending by a previous run, it's called
+// once at startup.
+func (s *exportService) Resume(ctx context.Context) {
+	ctx, span := tracing.Start(ctx, "exportService.Resume")
+	defer span.End()
+
+	exports, err := s.exportRepo.FindPending(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error(), "cause", "resume exports")
+		return
+	}
+
+	for _, export := range exports {
+		if s.submit(ctx, export) != nil {
+			return
+		}
+	}
+	if len(exports) > 0 {
+		s.logger.InfoContext(ctx, "pending exports resumed", "count", len(exports))
+	}
+}
+
+// FailStale fails the exports that haven't made progress within the
+// timeout, such as those being built when the server crashed. It runs as a
+// background job.
+func (s *exportService) FailStale(ctx context.Context) {
+	ctx, span := tracing.Start(ctx, "exportService.FailStale")
+	defer span.End()
+
+	n, err := s.exportRepo.FailStale(ctx, time.Now().Add(-s.timeout), helper.ErrExportTimedOut.Error())
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error(), "cause", "fail stale exports")
+		return
+	}
+	if n > 0 {
+		s.logger.WarnContext(ctx, "stale exports failed", "count", n)
+	}
+}
+
+// PurgeExpired deletes the exports finished longer than the retention ago
+// and their archives. It runs as a background job.
+func (s *exportService) PurgeExpired(ctx context.Context) {
+	ctx, span := tracing.Start(ctx, "exportService.PurgeExpired")
+	defer span.End()
+
+	files, err := s.exportRepo.DeleteExpired(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error(), "cause", "purge expired exports")
+		return
+	}
+
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.logger.ErrorContext(ctx, err.Error(), "cause", "remove expired export")
+		}
+	}
+	if len(files) > 0 {
+		s.logger.InfoContext(ctx, "expired exports purged", "count", len(files))
+	}
+}
+
+func (s *exportService) submit(ctx context.Context, export model.Export) error {
+	err := s.pool.Submit(func(ctx context.Context) {
+		s.build(ctx, export)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error(), "cause", "export job rejected")
+		s.fail(ctx, export, helper.ErrServiceBusy)
+	}
+	return err
+}
+
+func (s *exportService) GetByID(ctx context.Context, id uint64) (_ dto.ExportResponse, err error) {
+	ctx, span := tracing.Start(ctx, "exportService.GetByID")
+	defer tracing.End(span, &err)
+
+	export, err := s.find(ctx, id)
+	if err != nil {
+		return dto.ExportResponse{}, err
+	}
+
+	return toResponse(export), nil
+}
+
+func (s *exportService) GetFile(ctx context.Context, id uint64) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "exportService.GetFile")
+	defer tracing.End(span, &err)
+
+	export, err := s.find(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	if export.Status != model.ExportReady || !export.FilePath.Valid {
+		return "", helper.NewResponseError(helper.ErrExportNotReady, http.StatusConflict)
+	}
+
+	return export.FilePath.String, nil
+}
+
+func (s *exportService) find(ctx context.Context, id uint64) (model.Export, error) {
+	principal, ok := helper.UserFromContext(ctx)
+	if !ok {
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
+		return model.Export{}, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	export, err := s.exportRepo.FindByID(ctx, id)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		if errors.Is(err, sql.ErrNoRows) {
+			return export, helper.NewResponseError(helper.ErrExportNotFound, http.StatusNotFound)
+		}
+		return export, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	if export.UserID != principal.UserID {
+		s.logger.ErrorContext(ctx, "export.UserID != principal.UserID: user is not the owner of the export")
+		return export, helper.NewResponseError(helper.ErrExportNotFound, http.StatusNotFound)
+	}
+
+	return export, nil
+}
+
+func (s *exportService) build(ctx context.Context, export model.Export) {
+	if err := s.exportRepo.Claim(ctx, export.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// already built or being built after being queued twice
+			return
+		}
+		s.logger.ErrorContext(ctx, err.Error(), "cause", "export status update failed")
+		s.fail(ctx, export, helper.ErrInternal)
+		return
+	}
+	export.Status = model.ExportProcessing
+
+	path := filepath.Join(s.dir, fmt.Sprintf("export-%d-%d.zip", export.UserID, export.ID))
+	if err := s.writeArchive(ctx, path, export.UserID); err != nil {
+		os.Remove(path)
+		if ctx.Err() != nil {
+			// the server is shutting down, Resume picks it up on the next start
+			s.requeue(ctx, export)
+			return
+		}
+		s.logger.ErrorContext(ctx, err.Error(), "cause", "export build failed")
+		s.fail(ctx, export, helper.ErrInternal)
+		return
+	}
+
+	ready := export
+	ready.Status = model.ExportReady
+	ready.FilePath = sql.NullString{String: path, Valid: true}
+	ready.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if !s.updateStatus(ctx, ready, export.Status) {
+		os.Remove(path)
+	}
+}
+
+func (s *exportService) requeue(ctx context.Context, export model.Export) {
+	from := export.Status
+	export.Status = model.ExportPending
+	s.updateStatus(context.WithoutCancel(ctx), export, from)
+}
+
+func (s *exportService) fail(ctx context.Context, export model.Export, cause error) {
+	from := export.Status
+	export.Status = model.ExportFailed
+	export.Error = sql.NullString{String: cause.Error(), Valid: true}
+	export.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	s.updateStatus(context.WithoutCancel(ctx), export, from)
+}
+
+// updateStatus reports whether export was moved on from the status from,
+// it isn't when FailStale got to it first.
+func (s *exportService) updateStatus(ctx context.Context, export model.Export, from string) bool {
+	err := s.exportRepo.UpdateStatus(ctx, export, from)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.logger.WarnContext(ctx, "export status changed while it was being built", "export_id", export.ID, "status", export.Status)
+		return false
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error(), "cause", "export status update failed")
+		return false
+	}
+	return true
+}
+
+func (s *exportService) writeArchive(ctx context.Context, path string, userID uint64) (err error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("exportService.writeArchive: %w", err)
+	}
+
+	photos, err := s.photoRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("exportService.writeArchive: %w", err)
+	}
+
+	comments, err := s.commentRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("exportService.writeArchive: %w", err)
+	}
+
+	likes, err := s.likeRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("exportService.writeArchive: %w", err)
+	}
+
+	socialMedias, err := s.socialMediaRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("exportService.writeArchive: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("exportService.writeArchive: %w", err)
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("exportService.writeArchive: %w", cerr)
+		}
+	}()
+
+	zw := zip.NewWriter(f)
+
+	profile := dto.ExportProfile{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		Age:       user.Age,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+	profileRows := [][]string{
+		{"id", "username", "email", "age", "created_at", "updated_at"},
+		{formatUint(profile.ID), profile.Username, profile.Email, formatUint(profile.Age), formatTime(profile.CreatedAt), formatTime(profile.UpdatedAt)},
+	}
+
+	exportPhotos := make([]dto.ExportPhoto, 0, len(photos))
+	photoRows := [][]string{{"id", "title", "caption", "photo_url", "created_at", "updated_at", "edited_at"}}
+	for _, photo := range photos {
+		item := dto.ExportPhoto{
+			ID:        photo.ID,
+			Title:     photo.Title,
+			Caption:   photo.Caption.String,
+			URL:       photo.URL,
+			CreatedAt: photo.CreatedAt,
+			UpdatedAt: photo.UpdatedAt,
+		}
+		if photo.EditedAt.Valid {
+			item.EditedAt = &photo.EditedAt.Time
+		}
+		exportPhotos = append(exportPhotos, item)
+		photoRows = append(photoRows, []string{formatUint(item.ID), item.Title, item.Caption, item.URL, formatTime(item.CreatedAt), formatTime(item.UpdatedAt), formatNullTime(photo.EditedAt)})
+	}
+
+	exportComments := make([]dto.ExportComment, 0, len(comments))
+	commentRows := [][]string{{"id", "photo_id", "message", "created_at", "updated_at", "edited_at"}}
+	for _, comment := range comments {
+		item := dto.ExportComment{
+			ID:        comment.ID,
+			PhotoID:   comment.PhotoID,
+			Message:   comment.Message,
+			CreatedAt: comment.CreatedAt,
+			UpdatedAt: comment.UpdatedAt,
+		}
+		if comment.EditedAt.Valid {
+			item.EditedAt = &comment.EditedAt.Time
+		}
+		exportComments = append(exportComments, item)
+		commentRows = append(commentRows, []string{formatUint(item.ID), formatUint(item.PhotoID), item.Message, formatTime(item.CreatedAt), formatTime(item.UpdatedAt), formatNullTime(comment.EditedAt)})
+	}
+
+	exportLikes := make([]dto.ExportLike, 0, len(likes))
+	likeRows := [][]string{{"id", "photo_id", "created_at"}}
+	for _, like := range likes {
+		item := dto.ExportLike{
+			ID:        like.ID,
+			PhotoID:   like.PhotoID,
+			CreatedAt: like.CreatedAt,
+		}
+		exportLikes = append(exportLikes, item)
+		likeRows = append(likeRows, []string{formatUint(item.ID), formatUint(item.PhotoID), formatTime(item.CreatedAt)})
+	}
+
+	exportSocialMedias := make([]dto.ExportSocialMedia, 0, len(socialMedias))
+	socialMediaRows := [][]string{{"id", "name", "social_media_url", "created_at", "updated_at"}}
+	for _, socialMedia := range socialMedias {
+		item := dto.ExportSocialMedia{
+			ID:        socialMedia.ID,
+			Name:      socialMedia.Name,
+			URL:       socialMedia.URL,
+			CreatedAt: socialMedia.CreatedAt,
+			UpdatedAt: socialMedia.UpdatedAt,
+		}
+		exportSocialMedias = append(exportSocialMedias, item)
+		socialMediaRows = append(socialMediaRows, []string{formatUint(item.ID), item.Name, item.URL, formatTime(item.CreatedAt), formatTime(item.UpdatedAt)})
+	}
+
+	entries := []struct {
+		name string
+		data any
+		rows [][]string
+	}{
+		{"profile", profile, profileRows},
+		{"photos", exportPhotos, photoRows},
+		{"comments", exportComments, commentRows},
+		{"likes", exportLikes, likeRows},
+		{"social_medias", exportSocialMedias, socialMediaRows},
+	}
+
+	for _, entry := range entries {
+		if err := writeJSON(zw, entry.name+".json", entry.data); err != nil {
+			return fmt.Errorf("exportService.writeArchive: %w", err)
+		}
+		if err := writeCSV(zw, entry.name+".csv", entry.rows); err != nil {
+			return fmt.Errorf("exportService.writeArchive: %w", err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("exportService.writeArchive: %w", err)
+	}
+
+	return nil
+}
+
+func writeJSON(zw *zip.Writer, name string, data any) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+func writeCSV(zw *zip.Writer, name string, rows [][]string) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+func toResponse(export model.Export) dto.ExportResponse {
+	resp := dto.ExportResponse{
+		ID:        export.ID,
+		Status:    export.Status,
+		Error:     export.Error.String,
+		CreatedAt: export.CreatedAt,
+	}
+	if export.CompletedAt.Valid {
+		resp.CompletedAt = &export.CompletedAt.Time
+	}
+
+	return resp
+}
+
+func formatUint(n uint64) string {
+	return strconv.FormatUint(n, 10)
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+func formatNullTime(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return formatTime(t.Time)
+}
@@ -0,0 +1,180 @@
+package exportservice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"final-project/helper"
+	"final-project/lib/worker"
+	"final-project/model"
+	"final-project/repository"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// exportRepo keeps exports in memory and enforces one in progress per
+// user like idx_user_export_active does.
+type exportRepo struct {
+	repository.ExportRepository
+
+	mu      sync.Mutex
+	exports map[uint64]model.Export
+	expired []string
+	before  time.Time
+}
+
+func (r *exportRepo) Save(_ context.Context, export model.Export) (model.Export, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range r.exports {
+		if e.UserID == export.UserID && (e.Status == model.ExportPending || e.Status == model.ExportProcessing) {
+			return model.Export{}, &pq.Error{Code: "23505", Constraint: "idx_user_export_active"}
+		}
+	}
+	export.ID = uint64(len(r.exports) + 1)
+	r.exports[export.ID] = export
+	return export, nil
+}
+
+func (r *exportRepo) Claim(_ context.Context, id uint64) error {
+	return r.UpdateStatus(context.Background(), model.Export{ID: id, Status: model.ExportProcessing}, model.ExportPending)
+}
+
+func (r *exportRepo) UpdateStatus(_ context.Context, export model.Export, from string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.exports[export.ID].Status != from {
+		return sql.ErrNoRows
+	}
+	current := r.exports[export.ID]
+	current.Status, current.FilePath = export.Status, export.FilePath
+	r.exports[export.ID] = current
+	return nil
+}
+
+func (r *exportRepo) DeleteExpired(_ context.Context, before time.Time) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.before = before
+	return r.expired, nil
+}
+
+func (r *exportRepo) status(id uint64) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.exports[id].Status
+}
+
+// userRepo runs onFind while the archive is being built.
+type userRepo struct {
+	repository.UserRepository
+	onFind func()
+}
+
+func (r *userRepo) FindByID(_ context.Context, id uint64) (model.User, error) {
+	if r.onFind != nil {
+		r.onFind()
+	}
+	return model.User{ID: id}, nil
+}
+
+type photoRepo struct{ repository.PhotoRepository }
+
+func (photoRepo) FindByUserID(context.Context, uint64) ([]model.Photo, error) { return nil, nil }
+
+type commentRepo struct{ repository.CommentRepository }
+
+func (commentRepo) FindByUserID(context.Context, uint64) ([]model.Comment, error) { return nil, nil }
+
+type likeRepo struct{ repository.LikeRepository }
+
+func (likeRepo) FindByUserID(context.Context, uint64) ([]model.Like, error) { return nil, nil }
+
+type socialMediaRepo struct {
+	repository.SocialMediaRepository
+}
+
+func (socialMediaRepo) FindByUserID(context.Context, uint64) ([]model.SocialMedia, error) {
+	return nil, nil
+}
+
+func newTestService(t *testing.T, repo *exportRepo, users *userRepo) *exportService {
+	t.Helper()
+	// created first so it's removed after the pool has finished its jobs
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	pool := worker.New(1, 10, logger)
+	t.Cleanup(func() { pool.Shutdown(context.Background()) })
+	return New(repo, users, photoRepo{}, commentRepo{}, likeRepo{}, socialMediaRepo{}, pool, dir, time.Hour, 24*time.Hour, logger)
+}
+
+func TestBuildKeepsStaleFailure(t *testing.T) {
+	repo := &exportRepo{exports: map[uint64]model.Export{}}
+	users := &userRepo{}
+	s := newTestService(t, repo, users)
+
+	export, err := repo.Save(context.Background(), model.Export{UserID: 7, Status: model.ExportPending})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// FailStale gets to the export while it's being built
+	users.onFind = func() {
+		repo.UpdateStatus(context.Background(), model.Export{ID: export.ID, Status: model.ExportFailed}, model.ExportProcessing)
+	}
+
+	s.build(context.Background(), export)
+
+	if got := repo.status(export.ID); got != model.ExportFailed {
+		t.Errorf("status = %s, want it to stay %s", got, model.ExportFailed)
+	}
+	if files, _ := filepath.Glob(filepath.Join(s.dir, "*")); len(files) != 0 {
+		t.Errorf("export dir has %v, want the archive removed", files)
+	}
+}
+
+func TestCreateRefusesSecondExport(t *testing.T) {
+	repo := &exportRepo{exports: map[uint64]model.Export{
+		1: {ID: 1, UserID: 7, Status: model.ExportProcessing},
+	}}
+	s := newTestService(t, repo, &userRepo{})
+	ctx := helper.ContextWithUser(context.Background(), helper.Principal{UserID: 7})
+
+	_, err := s.Create(ctx)
+	var respErr *helper.ResponseError
+	if !errors.As(err, &respErr) || respErr.Code() != http.StatusConflict || err.Error() != helper.ErrExportInProgress.Error() {
+		t.Errorf("Create() = %v, want a %d", err, http.StatusConflict)
+	}
+
+	other := helper.ContextWithUser(context.Background(), helper.Principal{UserID: 8})
+	if _, err := s.Create(other); err != nil {
+		t.Errorf("Create() for another user = %v, want nil", err)
+	}
+}
+
+func TestPurgeExpired(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "export-7-1.zip")
+	if err := os.WriteFile(archive, []byte("zip"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &exportRepo{expired: []string{archive, filepath.Join(dir, "already-gone.zip")}}
+	s := newTestService(t, repo, &userRepo{})
+
+	s.PurgeExpired(context.Background())
+
+	if _, err := os.Stat(archive); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("archive still exists: %v", err)
+	}
+	if age := time.Since(repo.before); age < s.retention || age > s.retention+time.Minute {
+		t.Errorf("deleted exports completed %v ago, want about %v", age, s.retention)
+	}
+}
@@ -3,23 +3,41 @@ package service
 import (
 	"context"
 	"final-project/dto"
+	"final-project/helper"
+	"final-project/model"
 )
 
 type UserService interface {
 	Create(context.Context, dto.UserRequest) (dto.UserCreateResponse, error)
 	Login(context.Context, dto.UserRequest) (dto.UserLoginResponse, error)
+	Get(context.Context) (dto.UserResponse, error)
 	Update(context.Context, dto.UserRequest) (dto.UserUpdateResponse, error)
-	Delete(context.Context) error
+	Patch(context.Context, dto.UserPatchRequest) (dto.UserUpdateResponse, error)
+	Delete(context.Context) (dto.UserDeleteResponse, error)
+	Unlock(context.Context, uint64) error
+	LoginTOTP(context.Context, dto.UserLoginTOTPRequest) (dto.UserLoginResponse, error)
+	SetupTOTP(context.Context) (dto.TOTPSetupResponse, error)
+	EnableTOTP(context.Context, dto.TOTPCodeRequest) (dto.RecoveryCodesResponse, error)
+	DisableTOTP(context.Context, dto.TOTPCodeRequest) error
+	RegenerateRecoveryCodes(context.Context, dto.TOTPCodeRequest) (dto.RecoveryCodesResponse, error)
+	OIDCAuthURL(context.Context, string) (string, string, error)
+	OIDCCallback(context.Context, string, string, string, string) (dto.UserLoginResponse, error)
+	OIDCRegister(context.Context, dto.OIDCRegisterRequest) (dto.UserLoginResponse, error)
+	OIDCLink(context.Context, dto.OIDCLinkRequest) error
+	PurgeScheduled(context.Context)
+	PurgeLoginFailures(context.Context)
 }
 
 type PhotoService interface {
 	Create(context.Context, dto.PhotoRequest) (dto.PhotoCreateResponse, error)
 	GetAll(context.Context) ([]dto.PhotoResponse, error)
 	Update(context.Context, uint64, dto.PhotoRequest) (dto.PhotoUpdateResponse, error)
+	Patch(context.Context, uint64, dto.PhotoPatchRequest) (dto.PhotoUpdateResponse, error)
 	Delete(context.Context, uint64) error
 	GetByID(context.Context, uint64) (dto.PhotoResponse, error)
 	GetByUserID(context.Context, uint64) ([]dto.PhotoResponse, error)
 	GetByUsername(context.Context, string) ([]dto.PhotoResponse, error)
+	GetRevisions(context.Context, uint64) ([]dto.PhotoRevisionResponse, error)
 }
 
 type LikeService interface {
@@ -33,17 +51,52 @@ type CommentService interface {
 	Create(context.Context, dto.CommentRequest) (dto.CommentCreateResponse, error)
 	GetAll(context.Context) ([]dto.CommentResponse, error)
 	Update(context.Context, uint64, dto.CommentRequest) (dto.CommentUpdateResponse, error)
+	Patch(context.Context, uint64, dto.CommentPatchRequest) (dto.CommentUpdateResponse, error)
 	Delete(context.Context, uint64) error
 	GetByID(context.Context, uint64) (dto.CommentResponse, error)
 	GetByPhotoID(context.Context, uint64) ([]dto.CommentGetByPhotoIDResponse, error)
 	GetByUserID(context.Context, uint64) ([]dto.CommentGetByUserIDResponse, error)
+	GetRevisions(context.Context, uint64) ([]dto.CommentRevisionResponse, error)
 }
 
 type SocialMediaService interface {
 	Create(context.Context, dto.SocialMediaRequest) (dto.SocialMediaCreateResponse, error)
 	GetAll(context.Context) ([]dto.SocialMediaResponse, error)
 	Update(context.Context, uint64, dto.SocialMediaRequest) (dto.SocialMediaUpdateResponse, error)
+	Patch(context.Context, uint64, dto.SocialMediaPatchRequest) (dto.SocialMediaUpdateResponse, error)
 	Delete(context.Context, uint64) error
 	GetByID(context.Context, uint64) (dto.SocialMediaResponse, error)
 	GetByUserID(context.Context, uint64) ([]dto.SocialMediaGetByUserIDResponse, error)
 }
+
+type ExportService interface {
+	Create(context.Context) (dto.ExportResponse, error)
+	GetByID(context.Context, uint64) (dto.ExportResponse, error)
+	GetFile(context.Context, uint64) (string, error)
+	Resume(context.Context)
+	FailStale(context.Context)
+	PurgeExpired(context.Context)
+}
+
+type APITokenService interface {
+	Create(context.Context, dto.APITokenRequest) (dto.APITokenCreateResponse, error)
+	GetAll(context.Context) ([]dto.APITokenResponse, error)
+	Delete(context.Context, uint64) error
+	Authenticate(context.Context, string) (helper.Principal, error)
+}
+
+type SessionService interface {
+	GetAll(context.Context) ([]dto.SessionResponse, error)
+	Delete(context.Context, uint64) error
+	Check(context.Context, helper.Principal) error
+}
+
+type AuditService interface {
+	GetMine(context.Context, dto.AuditEventQuery) ([]dto.AuditEventResponse, error)
+	Search(context.Context, dto.AuditEventQuery) ([]dto.AuditEventResponse, error)
+}
+
+// AuditRecorder is used by the other services to write to the audit log.
+type AuditRecorder interface {
+	Record(context.Context, model.AuditEvent)
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"final-project/dto"
 	"final-project/helper"
+	"final-project/lib/metrics"
+	"final-project/lib/tracing"
 	"final-project/model"
 	"final-project/repository"
 	"log/slog"
@@ -24,18 +26,21 @@ func New(likeRepository repository.LikeRepository, photoRepository repository.Ph
 	return &likeService{likeRepository, photoRepository, logger}
 }
 
-func (s *likeService) Create(ctx context.Context, data dto.LikeRequest) (dto.LikeCreateResponse, error) {
+func (s *likeService) Create(ctx context.Context, data dto.LikeRequest) (_ dto.LikeCreateResponse, err error) {
+	ctx, span := tracing.Start(ctx, "likeService.Create")
+	defer tracing.End(span, &err)
+
 	var (
 		resp dto.LikeCreateResponse
 	)
 
-	userID, ok := ctx.Value(helper.UserIDKey).(float64)
+	principal, ok := helper.UserFromContext(ctx)
 	if !ok {
-		s.logger.ErrorContext(ctx, "userID is not float64", "cause", "ctx.Value(helper.UserIDKey).(float64)")
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
 		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
-	_, err := s.photoRepository.FindByID(ctx, data.PhotoID)
+	_, err = s.photoRepository.FindByID(ctx, data.PhotoID)
 	if err != nil {
 		s.logger.ErrorContext(ctx, err.Error(), "cause", "s.photoRepository.FindByID")
 		if errors.Is(err, sql.ErrNoRows) {
@@ -45,7 +50,7 @@ func (s *likeService) Create(ctx context.Context, data dto.LikeRequest) (dto.Lik
 	}
 
 	like := model.Like{
-		UserID:  uint64(userID),
+		UserID:  principal.UserID,
 		PhotoID: data.PhotoID,
 	}
 
@@ -61,6 +66,8 @@ func (s *likeService) Create(ctx context.Context, data dto.LikeRequest) (dto.Lik
 		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
+	metrics.Count(metrics.EventLikeCreated)
+
 	resp = dto.LikeCreateResponse{
 		ID:        like.ID,
 		UserID:    like.UserID,
@@ -71,12 +78,15 @@ func (s *likeService) Create(ctx context.Context, data dto.LikeRequest) (dto.Lik
 	return resp, nil
 }
 
-func (s *likeService) GetByPhotoID(ctx context.Context, photoID uint64) ([]dto.LikeResponse, error) {
+func (s *likeService) GetByPhotoID(ctx context.Context, photoID uint64) (_ []dto.LikeResponse, err error) {
+	ctx, span := tracing.Start(ctx, "likeService.GetByPhotoID")
+	defer tracing.End(span, &err)
+
 	var (
 		resp []dto.LikeResponse
 	)
 
-	_, err := s.photoRepository.FindByID(ctx, photoID)
+	_, err = s.photoRepository.FindByID(ctx, photoID)
 	if err != nil {
 		s.logger.ErrorContext(ctx, err.Error())
 		if errors.Is(err, sql.ErrNoRows) {
@@ -110,15 +120,18 @@ func (s *likeService) GetByPhotoID(ctx context.Context, photoID uint64) ([]dto.L
 	return resp, nil
 }
 
-func (s *likeService) Delete(ctx context.Context, photoID uint64) error {
-	userID, ok := ctx.Value(helper.UserIDKey).(float64)
+func (s *likeService) Delete(ctx context.Context, photoID uint64) (err error) {
+	ctx, span := tracing.Start(ctx, "likeService.Delete")
+	defer tracing.End(span, &err)
+
+	principal, ok := helper.UserFromContext(ctx)
 	if !ok {
-		s.logger.ErrorContext(ctx, "ctx.Value(helper.UserIDKey).(float64): userID is not float64")
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
 		return helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
-	err := s.likeRepository.Delete(ctx, model.Like{
-		UserID:  uint64(userID),
+	err = s.likeRepository.Delete(ctx, model.Like{
+		UserID:  principal.UserID,
 		PhotoID: photoID,
 	})
 	if err != nil {
@@ -132,7 +145,10 @@ func (s *likeService) Delete(ctx context.Context, photoID uint64) error {
 	return nil
 }
 
-func (s *likeService) GetByUserID(ctx context.Context, userID uint64) ([]dto.GetLikeByUserIDResponse, error) {
+func (s *likeService) GetByUserID(ctx context.Context, userID uint64) (_ []dto.GetLikeByUserIDResponse, err error) {
+	ctx, span := tracing.Start(ctx, "likeService.GetByUserID")
+	defer tracing.End(span, &err)
+
 	var (
 		resp []dto.GetLikeByUserIDResponse
 	)
@@ -146,7 +162,7 @@ func (s *likeService) GetByUserID(ctx context.Context, userID uint64) ([]dto.Get
 	resp = make([]dto.GetLikeByUserIDResponse, 0, len(likes))
 
 	for _, like := range likes {
-		resp = append(resp, dto.GetLikeByUserIDResponse{
+		item := dto.GetLikeByUserIDResponse{
 			ID:        like.ID,
 			UserID:    like.UserID,
 			PhotoID:   like.PhotoID,
@@ -158,7 +174,14 @@ func (s *likeService) GetByUserID(ctx context.Context, userID uint64) ([]dto.Get
 				URL:     like.Photo.URL,
 				UserID:  like.Photo.UserID,
 			},
-		})
+		}
+
+		if like.Photo.EditedAt.Valid {
+			item.Photo.Edited = true
+			item.Photo.EditedAt = &like.Photo.EditedAt.Time
+		}
+
+		resp = append(resp, item)
 	}
 
 	return resp, nil
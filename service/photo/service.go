@@ -6,10 +6,13 @@ import (
 	"errors"
 	"final-project/dto"
 	"final-project/helper"
+	"final-project/lib/metrics"
+	"final-project/lib/tracing"
 	"final-project/model"
 	"final-project/repository"
 	"log/slog"
 	"net/http"
+	"time"
 )
 
 type photoService struct {
@@ -22,22 +25,24 @@ func New(userRepo repository.UserRepository, photoRepo repository.PhotoRepositor
 	return &photoService{userRepo, photoRepo, logger}
 }
 
-func (s *photoService) Create(ctx context.Context, data dto.PhotoRequest) (dto.PhotoCreateResponse, error) {
+func (s *photoService) Create(ctx context.Context, data dto.PhotoRequest) (_ dto.PhotoCreateResponse, err error) {
+	ctx, span := tracing.Start(ctx, "photoService.Create")
+	defer tracing.End(span, &err)
+
 	var (
 		resp dto.PhotoCreateResponse
-		err  error
 	)
 
-	userID, ok := ctx.Value(helper.UserIDKey).(float64)
+	principal, ok := helper.UserFromContext(ctx)
 	if !ok {
-		s.logger.ErrorContext(ctx, "ctx.Value(helper.UserIDKey).(float64): userID is not float64")
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
 		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
 	photo := model.Photo{
 		Title:  data.Title,
 		URL:    data.URL,
-		UserID: uint64(userID),
+		UserID: principal.UserID,
 	}
 
 	if data.Caption != "" {
@@ -51,6 +56,8 @@ func (s *photoService) Create(ctx context.Context, data dto.PhotoRequest) (dto.P
 		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
+	metrics.Count(metrics.EventPhotoCreated)
+
 	resp = dto.PhotoCreateResponse{
 		ID:        photo.ID,
 		Title:     photo.Title,
@@ -66,7 +73,10 @@ func (s *photoService) Create(ctx context.Context, data dto.PhotoRequest) (dto.P
 	return resp, nil
 }
 
-func (s *photoService) GetAll(ctx context.Context) ([]dto.PhotoResponse, error) {
+func (s *photoService) GetAll(ctx context.Context) (_ []dto.PhotoResponse, err error) {
+	ctx, span := tracing.Start(ctx, "photoService.GetAll")
+	defer tracing.End(span, &err)
+
 	var resp []dto.PhotoResponse
 
 	photos, err := s.photoRepo.FindAll(ctx)
@@ -96,16 +106,51 @@ func (s *photoService) GetAll(ctx context.Context) ([]dto.PhotoResponse, error)
 			item.Caption = photo.Caption.String
 		}
 
+		if photo.EditedAt.Valid {
+			item.Edited = true
+			item.EditedAt = &photo.EditedAt.Time
+		}
+
 		resp = append(resp, item)
 	}
 
 	return resp, nil
 }
 
-func (s *photoService) Update(ctx context.Context, id uint64, data dto.PhotoRequest) (resp dto.PhotoUpdateResponse, err error) {
-	userID, ok := ctx.Value(helper.UserIDKey).(float64)
+func (s *photoService) Update(ctx context.Context, id uint64, data dto.PhotoRequest) (_ dto.PhotoUpdateResponse, err error) {
+	ctx, span := tracing.Start(ctx, "photoService.Update")
+	defer tracing.End(span, &err)
+
+	return s.update(ctx, id, func(photo *model.Photo) {
+		photo.Title = data.Title
+		photo.URL = data.URL
+		photo.Caption.String = data.Caption
+		photo.Caption.Valid = data.Caption != ""
+	})
+}
+
+func (s *photoService) Patch(ctx context.Context, id uint64, data dto.PhotoPatchRequest) (_ dto.PhotoUpdateResponse, err error) {
+	ctx, span := tracing.Start(ctx, "photoService.Patch")
+	defer tracing.End(span, &err)
+
+	return s.update(ctx, id, func(photo *model.Photo) {
+		if data.Title.Set {
+			photo.Title = data.Title.Value
+		}
+		if data.URL.Set {
+			photo.URL = data.URL.Value
+		}
+		if data.Caption.Set {
+			photo.Caption.String = data.Caption.Value
+			photo.Caption.Valid = !data.Caption.Null
+		}
+	})
+}
+
+func (s *photoService) update(ctx context.Context, id uint64, apply func(*model.Photo)) (resp dto.PhotoUpdateResponse, err error) {
+	principal, ok := helper.UserFromContext(ctx)
 	if !ok {
-		s.logger.ErrorContext(ctx, "ctx.Value(helper.UserIDKey).(float64): userID is not float64")
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
 		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
@@ -118,17 +163,19 @@ func (s *photoService) Update(ctx context.Context, id uint64, data dto.PhotoRequ
 		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
-	if photo.UserID != uint64(userID) {
-		s.logger.ErrorContext(ctx, "photo.UserID != uint64(userID): user is not the owner of the photo")
+	if photo.UserID != principal.UserID {
+		s.logger.ErrorContext(ctx, "photo.UserID != principal.UserID: user is not the owner of the photo")
 		return resp, helper.NewResponseError(helper.ErrNotAllowed, http.StatusForbidden)
 	}
 
-	photo.Title = data.Title
-	photo.URL = data.URL
-	photo.Caption.String = data.Caption
-	photo.Caption.Valid = true
+	if !helper.CheckIfMatch(ctx, helper.ETag(photo.ID, photo.UpdatedAt)) {
+		s.logger.ErrorContext(ctx, "helper.CheckIfMatch: photo has been modified since it was fetched")
+		return resp, helper.NewResponseError(helper.ErrPreconditionFailed, http.StatusPreconditionFailed)
+	}
+
+	apply(&photo)
 
-	photo, err = s.photoRepo.Update(ctx, photo)
+	photo, err = s.photoRepo.Update(ctx, photo, principal.UserID)
 	if err != nil {
 		s.logger.ErrorContext(ctx, err.Error())
 		if errors.Is(err, sql.ErrNoRows) {
@@ -146,23 +193,59 @@ func (s *photoService) Update(ctx context.Context, id uint64, data dto.PhotoRequ
 		UpdatedAt: photo.UpdatedAt,
 	}
 
+	if photo.EditedAt.Valid {
+		resp.Edited = true
+		resp.EditedAt = &photo.EditedAt.Time
+	}
+
 	return resp, nil
 }
 
 func (s *photoService) Delete(ctx context.Context, id uint64) (err error) {
-	userID, ok := ctx.Value(helper.UserIDKey).(float64)
+	ctx, span := tracing.Start(ctx, "photoService.Delete")
+	defer tracing.End(span, &err)
+
+	principal, ok := helper.UserFromContext(ctx)
 	if !ok {
-		s.logger.ErrorContext(ctx, "ctx.Value(helper.UserIDKey).(float64): userID is not float64")
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
 		return helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
+	var version time.Time
+	if helper.HasIfMatch(ctx) {
+		photo, err := s.photoRepo.FindByID(ctx, id)
+		if err != nil {
+			s.logger.ErrorContext(ctx, err.Error())
+			if errors.Is(err, sql.ErrNoRows) {
+				return helper.NewResponseError(helper.ErrNotAllowed, http.StatusForbidden)
+			}
+			return helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+		}
+
+		if photo.UserID != principal.UserID {
+			s.logger.ErrorContext(ctx, "photo.UserID != principal.UserID: user is not the owner of the photo")
+			return helper.NewResponseError(helper.ErrNotAllowed, http.StatusForbidden)
+		}
+
+		if !helper.CheckIfMatch(ctx, helper.ETag(photo.ID, photo.UpdatedAt)) {
+			s.logger.ErrorContext(ctx, "helper.CheckIfMatch: photo has been modified since it was fetched")
+			return helper.NewResponseError(helper.ErrPreconditionFailed, http.StatusPreconditionFailed)
+		}
+		version = photo.UpdatedAt
+	}
+
 	err = s.photoRepo.Delete(ctx, model.Photo{
-		ID:     id,
-		UserID: uint64(userID),
+		ID:        id,
+		UserID:    principal.UserID,
+		UpdatedAt: version,
 	})
 	if err != nil {
 		s.logger.ErrorContext(ctx, err.Error())
 		if errors.Is(err, sql.ErrNoRows) {
+			if !version.IsZero() {
+				// modified or deleted since the If-Match check above
+				return helper.NewResponseError(helper.ErrPreconditionFailed, http.StatusPreconditionFailed)
+			}
 			return helper.NewResponseError(helper.ErrNotAllowed, http.StatusForbidden)
 		}
 		return helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
@@ -171,7 +254,10 @@ func (s *photoService) Delete(ctx context.Context, id uint64) (err error) {
 	return nil
 }
 
-func (s *photoService) GetByID(ctx context.Context, id uint64) (dto.PhotoResponse, error) {
+func (s *photoService) GetByID(ctx context.Context, id uint64) (_ dto.PhotoResponse, err error) {
+	ctx, span := tracing.Start(ctx, "photoService.GetByID")
+	defer tracing.End(span, &err)
+
 	var resp dto.PhotoResponse
 
 	photo, err := s.photoRepo.FindByID(ctx, id)
@@ -201,13 +287,21 @@ func (s *photoService) GetByID(ctx context.Context, id uint64) (dto.PhotoRespons
 		resp.Caption = photo.Caption.String
 	}
 
+	if photo.EditedAt.Valid {
+		resp.Edited = true
+		resp.EditedAt = &photo.EditedAt.Time
+	}
+
 	return resp, nil
 }
 
-func (s *photoService) GetByUserID(ctx context.Context, userID uint64) ([]dto.PhotoResponse, error) {
+func (s *photoService) GetByUserID(ctx context.Context, userID uint64) (_ []dto.PhotoResponse, err error) {
+	ctx, span := tracing.Start(ctx, "photoService.GetByUserID")
+	defer tracing.End(span, &err)
+
 	var resp []dto.PhotoResponse
 
-	_, err := s.userRepo.FindByID(ctx, userID)
+	_, err = s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		s.logger.ErrorContext(ctx, err.Error())
 		if errors.Is(err, sql.ErrNoRows) {
@@ -243,16 +337,24 @@ func (s *photoService) GetByUserID(ctx context.Context, userID uint64) ([]dto.Ph
 			item.Caption = photo.Caption.String
 		}
 
+		if photo.EditedAt.Valid {
+			item.Edited = true
+			item.EditedAt = &photo.EditedAt.Time
+		}
+
 		resp = append(resp, item)
 	}
 
 	return resp, nil
 }
 
-func (s *photoService) GetByUsername(ctx context.Context, username string) ([]dto.PhotoResponse, error) {
+func (s *photoService) GetByUsername(ctx context.Context, username string) (_ []dto.PhotoResponse, err error) {
+	ctx, span := tracing.Start(ctx, "photoService.GetByUsername")
+	defer tracing.End(span, &err)
+
 	var resp []dto.PhotoResponse
 
-	_, err := s.userRepo.FindByUsername(ctx, username)
+	_, err = s.userRepo.FindByUsername(ctx, username)
 	if err != nil {
 		s.logger.ErrorContext(ctx, err.Error())
 		if errors.Is(err, sql.ErrNoRows) {
@@ -288,6 +390,58 @@ func (s *photoService) GetByUsername(ctx context.Context, username string) ([]dt
 			item.Caption = photo.Caption.String
 		}
 
+		if photo.EditedAt.Valid {
+			item.Edited = true
+			item.EditedAt = &photo.EditedAt.Time
+		}
+
+		resp = append(resp, item)
+	}
+
+	return resp, nil
+}
+
+func (s *photoService) GetRevisions(ctx context.Context, id uint64) (_ []dto.PhotoRevisionResponse, err error) {
+	ctx, span := tracing.Start(ctx, "photoService.GetRevisions")
+	defer tracing.End(span, &err)
+
+	var resp []dto.PhotoRevisionResponse
+
+	_, err = s.photoRepo.FindByID(ctx, id)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		if errors.Is(err, sql.ErrNoRows) {
+			return resp, helper.NewResponseError(helper.ErrPhotoNotFound, http.StatusNotFound)
+		}
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	revisions, err := s.photoRepo.FindRevisions(ctx, id)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	resp = make([]dto.PhotoRevisionResponse, 0, len(revisions))
+
+	for _, revision := range revisions {
+		item := dto.PhotoRevisionResponse{
+			ID:       revision.ID,
+			PhotoID:  revision.PhotoID,
+			Title:    revision.Title,
+			URL:      revision.URL,
+			EditedAt: revision.CreatedAt,
+			EditedBy: dto.User{
+				ID:       revision.EditorID,
+				Email:    revision.Editor.Email,
+				Username: revision.Editor.Username,
+			},
+		}
+
+		if revision.Caption.Valid {
+			item.Caption = revision.Caption.String
+		}
+
 		resp = append(resp, item)
 	}
 
@@ -0,0 +1,122 @@
+package sessionservice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"final-project/dto"
+	"final-project/helper"
+	"final-project/lib/tracing"
+	"final-project/model"
+	"final-project/repository"
+	"final-project/service"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type sessionService struct {
+	sessionRepo repository.SessionRepository
+	audit       service.AuditRecorder
+	logger      *slog.Logger
+}
+
+func New(sessionRepo repository.SessionRepository, audit service.AuditRecorder, logger *slog.Logger) *sessionService {
+	return &sessionService{sessionRepo, audit, logger}
+}
+
+func (s *sessionService) GetAll(ctx context.Context) (_ []dto.SessionResponse, err error) {
+	ctx, span := tracing.Start(ctx, "sessionService.GetAll")
+	defer tracing.End(span, &err)
+
+	principal, ok := helper.UserFromContext(ctx)
+	if !ok {
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
+		return nil, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	sessions, err := s.sessionRepo.FindActiveByUserID(ctx, principal.UserID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		return nil, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	resp := make([]dto.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		resp = append(resp, dto.SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == principal.SessionID,
+		})
+	}
+
+	return resp, nil
+}
+
+// Delete revokes a session, the tokens issued for it stop working at once.
+func (s *sessionService) Delete(ctx context.Context, id uint64) (err error) {
+	ctx, span := tracing.Start(ctx, "sessionService.Delete")
+	defer tracing.End(span, &err)
+
+	principal, ok := helper.UserFromContext(ctx)
+	if !ok {
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
+		return helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	err = s.sessionRepo.Revoke(ctx, id, principal.UserID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		if errors.Is(err, sql.ErrNoRows) {
+			return helper.NewResponseError(helper.ErrSessionNotFound, http.StatusNotFound)
+		}
+		return helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	s.audit.Record(ctx, model.AuditEvent{
+		UserID:     principal.UserID,
+		Action:     model.AuditSessionRevoked,
+		TargetType: "session",
+		TargetID:   strconv.FormatUint(id, 10),
+	})
+
+	return nil
+}
+
+// lastSeenResolution is how stale a session's last_seen_at may get before
+// Check updates it.
+const lastSeenResolution = 5 * time.Minute
+
+// Check is called by middleware.NewAuth for every request made with a JWT.
+func (s *sessionService) Check(ctx context.Context, principal helper.Principal) (err error) {
+	ctx, span := tracing.Start(ctx, "sessionService.Check")
+	defer tracing.End(span, &err)
+
+	err = s.sessionRepo.Touch(ctx, principal.SessionID, principal.UserID, time.Now().Add(-lastSeenResolution))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return helper.NewResponseError(helper.ErrSessionRevoked, http.StatusUnauthorized)
+		}
+		s.logger.ErrorContext(ctx, err.Error())
+		return helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// PurgeExpired removes sessions that expired more than a day ago, they're
+// kept for a while so the list of sessions doesn't change under the user.
+func (s *sessionService) PurgeExpired(ctx context.Context) {
+	ctx, span := tracing.Start(ctx, "sessionService.PurgeExpired")
+	defer span.End()
+
+	err := s.sessionRepo.DeleteExpiredBefore(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error(), "cause", "purge expired sessions")
+	}
+}
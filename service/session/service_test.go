@@ -0,0 +1,48 @@
+package sessionservice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"final-project/helper"
+	"final-project/repository"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type sessionRepo struct {
+	repository.SessionRepository
+
+	active      map[uint64]bool
+	touchBefore time.Time
+}
+
+func (r *sessionRepo) Touch(_ context.Context, id, _ uint64, touchBefore time.Time) error {
+	r.touchBefore = touchBefore
+	if !r.active[id] {
+		return fmt.Errorf("sessionRepository.Touch: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+func TestCheck(t *testing.T) {
+	repo := &sessionRepo{active: map[uint64]bool{1: true}}
+	s := New(repo, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if err := s.Check(context.Background(), helper.Principal{UserID: 7, SessionID: 1}); err != nil {
+		t.Fatalf("Check() = %v", err)
+	}
+	if age := time.Since(repo.touchBefore); age < lastSeenResolution || age > lastSeenResolution+time.Minute {
+		t.Errorf("touchBefore is %v ago, want about %v", age, lastSeenResolution)
+	}
+
+	err := s.Check(context.Background(), helper.Principal{UserID: 7, SessionID: 2})
+	var respErr *helper.ResponseError
+	if !errors.As(err, &respErr) || respErr.Code() != http.StatusUnauthorized || err.Error() != helper.ErrSessionRevoked.Error() {
+		t.Errorf("Check() of a revoked session = %v, want %v", err, helper.ErrSessionRevoked)
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"errors"
 	"final-project/dto"
 	"final-project/helper"
+	"final-project/lib/tracing"
 	"final-project/model"
 	"final-project/repository"
 	"log/slog"
 	"net/http"
+	"time"
 )
 
 type socialMediaService struct {
@@ -21,20 +23,22 @@ func New(socialMediaRepo repository.SocialMediaRepository, logger *slog.Logger)
 	return &socialMediaService{socialMediaRepo, logger}
 }
 
-func (s *socialMediaService) Create(ctx context.Context, data dto.SocialMediaRequest) (dto.SocialMediaCreateResponse, error) {
+func (s *socialMediaService) Create(ctx context.Context, data dto.SocialMediaRequest) (_ dto.SocialMediaCreateResponse, err error) {
+	ctx, span := tracing.Start(ctx, "socialMediaService.Create")
+	defer tracing.End(span, &err)
+
 	var (
 		resp dto.SocialMediaCreateResponse
-		err  error
 	)
 
-	userID, ok := ctx.Value(helper.UserIDKey).(float64)
+	principal, ok := helper.UserFromContext(ctx)
 	if !ok {
-		s.logger.ErrorContext(ctx, "ctx.Value(helper.UserIDKey).(float64): userID is not float64")
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
 		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
 	socialMedia := model.SocialMedia{
-		UserID: uint64(userID),
+		UserID: principal.UserID,
 		Name:   data.Name,
 		URL:    data.URL,
 	}
@@ -56,7 +60,10 @@ func (s *socialMediaService) Create(ctx context.Context, data dto.SocialMediaReq
 	return resp, nil
 }
 
-func (s *socialMediaService) GetAll(ctx context.Context) ([]dto.SocialMediaResponse, error) {
+func (s *socialMediaService) GetAll(ctx context.Context) (_ []dto.SocialMediaResponse, err error) {
+	ctx, span := tracing.Start(ctx, "socialMediaService.GetAll")
+	defer tracing.End(span, &err)
+
 	var resp []dto.SocialMediaResponse
 
 	socialMedias, err := s.socialMediaRepo.FindAll(ctx)
@@ -86,10 +93,34 @@ func (s *socialMediaService) GetAll(ctx context.Context) ([]dto.SocialMediaRespo
 	return resp, nil
 }
 
-func (s *socialMediaService) Update(ctx context.Context, id uint64, data dto.SocialMediaRequest) (resp dto.SocialMediaUpdateResponse, err error) {
-	userID, ok := ctx.Value(helper.UserIDKey).(float64)
+func (s *socialMediaService) Update(ctx context.Context, id uint64, data dto.SocialMediaRequest) (_ dto.SocialMediaUpdateResponse, err error) {
+	ctx, span := tracing.Start(ctx, "socialMediaService.Update")
+	defer tracing.End(span, &err)
+
+	return s.update(ctx, id, func(socialMedia *model.SocialMedia) {
+		socialMedia.Name = data.Name
+		socialMedia.URL = data.URL
+	})
+}
+
+func (s *socialMediaService) Patch(ctx context.Context, id uint64, data dto.SocialMediaPatchRequest) (_ dto.SocialMediaUpdateResponse, err error) {
+	ctx, span := tracing.Start(ctx, "socialMediaService.Patch")
+	defer tracing.End(span, &err)
+
+	return s.update(ctx, id, func(socialMedia *model.SocialMedia) {
+		if data.Name.Set {
+			socialMedia.Name = data.Name.Value
+		}
+		if data.URL.Set {
+			socialMedia.URL = data.URL.Value
+		}
+	})
+}
+
+func (s *socialMediaService) update(ctx context.Context, id uint64, apply func(*model.SocialMedia)) (resp dto.SocialMediaUpdateResponse, err error) {
+	principal, ok := helper.UserFromContext(ctx)
 	if !ok {
-		s.logger.ErrorContext(ctx, "ctx.Value(helper.UserIDKey).(float64): userID is not float64")
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
 		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
@@ -102,13 +133,17 @@ func (s *socialMediaService) Update(ctx context.Context, id uint64, data dto.Soc
 		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
-	if socialMedia.UserID != uint64(userID) {
+	if socialMedia.UserID != principal.UserID {
 		s.logger.ErrorContext(ctx, "socialMedia.UserID != userID: user is not the owner of the social media")
 		return resp, helper.NewResponseError(helper.ErrNotAllowed, http.StatusForbidden)
 	}
 
-	socialMedia.Name = data.Name
-	socialMedia.URL = data.URL
+	if !helper.CheckIfMatch(ctx, helper.ETag(socialMedia.ID, socialMedia.UpdatedAt)) {
+		s.logger.ErrorContext(ctx, "helper.CheckIfMatch: social media has been modified since it was fetched")
+		return resp, helper.NewResponseError(helper.ErrPreconditionFailed, http.StatusPreconditionFailed)
+	}
+
+	apply(&socialMedia)
 
 	socialMedia, err = s.socialMediaRepo.Update(ctx, socialMedia)
 	if err != nil {
@@ -131,19 +166,50 @@ func (s *socialMediaService) Update(ctx context.Context, id uint64, data dto.Soc
 }
 
 func (s *socialMediaService) Delete(ctx context.Context, id uint64) (err error) {
-	userID, ok := ctx.Value(helper.UserIDKey).(float64)
+	ctx, span := tracing.Start(ctx, "socialMediaService.Delete")
+	defer tracing.End(span, &err)
+
+	principal, ok := helper.UserFromContext(ctx)
 	if !ok {
-		s.logger.ErrorContext(ctx, "ctx.Value(helper.UserIDKey).(float64): userID is not float64")
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
 		return helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
+	var version time.Time
+	if helper.HasIfMatch(ctx) {
+		socialMedia, err := s.socialMediaRepo.FindByID(ctx, id)
+		if err != nil {
+			s.logger.ErrorContext(ctx, err.Error())
+			if errors.Is(err, sql.ErrNoRows) {
+				return helper.NewResponseError(helper.ErrNotAllowed, http.StatusForbidden)
+			}
+			return helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+		}
+
+		if socialMedia.UserID != principal.UserID {
+			s.logger.ErrorContext(ctx, "socialMedia.UserID != principal.UserID: user is not the owner of the social media")
+			return helper.NewResponseError(helper.ErrNotAllowed, http.StatusForbidden)
+		}
+
+		if !helper.CheckIfMatch(ctx, helper.ETag(socialMedia.ID, socialMedia.UpdatedAt)) {
+			s.logger.ErrorContext(ctx, "helper.CheckIfMatch: social media has been modified since it was fetched")
+			return helper.NewResponseError(helper.ErrPreconditionFailed, http.StatusPreconditionFailed)
+		}
+		version = socialMedia.UpdatedAt
+	}
+
 	err = s.socialMediaRepo.Delete(ctx, model.SocialMedia{
-		ID:     id,
-		UserID: uint64(userID),
+		ID:        id,
+		UserID:    principal.UserID,
+		UpdatedAt: version,
 	})
 	if err != nil {
 		s.logger.ErrorContext(ctx, err.Error())
 		if errors.Is(err, sql.ErrNoRows) {
+			if !version.IsZero() {
+				// modified or deleted since the If-Match check above
+				return helper.NewResponseError(helper.ErrPreconditionFailed, http.StatusPreconditionFailed)
+			}
 			return helper.NewResponseError(helper.ErrNotAllowed, http.StatusForbidden)
 		}
 		return helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
@@ -152,7 +218,10 @@ func (s *socialMediaService) Delete(ctx context.Context, id uint64) (err error)
 	return nil
 }
 
-func (s *socialMediaService) GetByID(ctx context.Context, id uint64) (dto.SocialMediaResponse, error) {
+func (s *socialMediaService) GetByID(ctx context.Context, id uint64) (_ dto.SocialMediaResponse, err error) {
+	ctx, span := tracing.Start(ctx, "socialMediaService.GetByID")
+	defer tracing.End(span, &err)
+
 	var resp dto.SocialMediaResponse
 
 	socialMedia, err := s.socialMediaRepo.FindByID(ctx, id)
@@ -181,7 +250,10 @@ func (s *socialMediaService) GetByID(ctx context.Context, id uint64) (dto.Social
 	return resp, nil
 }
 
-func (s *socialMediaService) GetByUserID(ctx context.Context, userID uint64) ([]dto.SocialMediaGetByUserIDResponse, error) {
+func (s *socialMediaService) GetByUserID(ctx context.Context, userID uint64) (_ []dto.SocialMediaGetByUserIDResponse, err error) {
+	ctx, span := tracing.Start(ctx, "socialMediaService.GetByUserID")
+	defer tracing.End(span, &err)
+
 	var resp []dto.SocialMediaGetByUserIDResponse
 
 	socialMedias, err := s.socialMediaRepo.FindByUserID(ctx, userID)
@@ -0,0 +1,263 @@
+package userservice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"final-project/dto"
+	"final-project/helper"
+	"final-project/lib/metrics"
+	"final-project/lib/oidc"
+	"final-project/lib/tracing"
+	"final-project/model"
+	"net/http"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// OIDCAuthURL starts a login with provider. It returns the URL to redirect
+// the user to and a state token the caller has to keep (in a cookie) and
+// pass back to OIDCCallback.
+func (s *userService) OIDCAuthURL(ctx context.Context, provider string) (_ string, _ string, err error) {
+	ctx, span := tracing.Start(ctx, "userService.OIDCAuthURL")
+	defer tracing.End(span, &err)
+
+	p, ok := s.opts.OIDCProviders[provider]
+	if !ok {
+		return "", "", helper.NewResponseError(helper.ErrOIDCProviderNotFound, http.StatusNotFound)
+	}
+
+	state, nonce, verifier := oidc.GenerateState(), oidc.GenerateState(), oidc.GenerateVerifier()
+
+	stateToken, err := helper.GenerateOIDCStateJWT(provider, state, nonce, verifier)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		return "", "", helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	authURL, err := p.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error(), "provider", provider)
+		return "", "", helper.NewResponseError(helper.ErrOIDCProviderUnavailable, http.StatusBadGateway)
+	}
+
+	return authURL, stateToken, nil
+}
+
+// OIDCCallback finishes a login with provider. The identity is matched to a
+// user by a previous link first and then by email address. The identity is
+// only linked automatically when both the provider and the user have
+// verified the address, otherwise the response asks the user to log in with
+// their password and link it with OIDCLink. Without a match the response
+// asks the client to sign up.
+func (s *userService) OIDCCallback(ctx context.Context, provider, code, state, stateToken string) (_ dto.UserLoginResponse, err error) {
+	ctx, span := tracing.Start(ctx, "userService.OIDCCallback")
+	defer tracing.End(span, &err)
+
+	var resp dto.UserLoginResponse
+
+	p, ok := s.opts.OIDCProviders[provider]
+	if !ok {
+		return resp, helper.NewResponseError(helper.ErrOIDCProviderNotFound, http.StatusNotFound)
+	}
+
+	claims, err := helper.VerifyOIDCStateJWT(stateToken)
+	if err != nil || claims.Provider != provider || claims.State != state {
+		s.logger.ErrorContext(ctx, "OIDC state doesn't match", "provider", provider)
+		return resp, helper.NewResponseError(helper.ErrInvalidOIDCState, http.StatusBadRequest)
+	}
+
+	identity, err := p.Exchange(ctx, code, claims.Verifier, claims.Nonce)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error(), "provider", provider)
+		return resp, helper.NewResponseError(helper.ErrOIDCLoginFailed, http.StatusUnauthorized)
+	}
+
+	userID, err := s.userRepo.FindIDByIdentity(ctx, provider, identity.Subject)
+	if err == nil {
+		return s.oidcLogin(ctx, userID)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		s.logger.ErrorContext(ctx, err.Error())
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	if identity.Email == "" {
+		s.logger.ErrorContext(ctx, "OIDC identity without email", "provider", provider)
+		return resp, helper.NewResponseError(helper.ErrOIDCEmailRequired, http.StatusUnprocessableEntity)
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, identity.Email)
+	switch {
+	case err == nil && identity.EmailVerified && user.EmailVerified:
+		err = s.linkIdentity(ctx, user.ID, provider, identity.Subject, identity.Email)
+		if err != nil {
+			return resp, err
+		}
+		return s.oidcLogin(ctx, user.ID)
+	case err == nil:
+		s.logger.InfoContext(ctx, "OIDC identity matches an unverified email, link required", "provider", provider, "user_id", user.ID)
+		resp.LinkRequired = true
+		resp.Email = identity.Email
+		resp.LinkToken, err = helper.GenerateLinkJWT(provider, identity.Subject, identity.Email)
+		if err != nil {
+			s.logger.ErrorContext(ctx, err.Error())
+			return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+		}
+		return resp, nil
+	case !errors.Is(err, sql.ErrNoRows):
+		s.logger.ErrorContext(ctx, err.Error())
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	if !identity.EmailVerified {
+		s.logger.ErrorContext(ctx, "OIDC identity with unverified email can't sign up", "provider", provider)
+		return resp, helper.NewResponseError(helper.ErrOIDCEmailNotVerified, http.StatusUnprocessableEntity)
+	}
+
+	resp.SignupRequired = true
+	resp.Email = identity.Email
+	resp.SignupToken, err = helper.GenerateSignupJWT(provider, identity.Subject, identity.Email)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	return resp, nil
+}
+
+// OIDCRegister creates the user for an identity OIDCCallback couldn't match.
+// The account has a random password, it's only reachable through the
+// provider until the user sets one. Signup tokens are only issued for
+// verified addresses, so the email starts out verified.
+func (s *userService) OIDCRegister(ctx context.Context, data dto.OIDCRegisterRequest) (_ dto.UserLoginResponse, err error) {
+	ctx, span := tracing.Start(ctx, "userService.OIDCRegister")
+	defer tracing.End(span, &err)
+
+	var (
+		resp dto.UserLoginResponse
+		user model.User
+	)
+
+	claims, err := helper.VerifySignupJWT(data.SignupToken)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		return resp, helper.NewResponseError(helper.ErrInvalidSignupToken, http.StatusUnauthorized)
+	}
+
+	_, err = s.userRepo.FindIDByIdentity(ctx, claims.Provider, claims.Subject)
+	if err == nil {
+		s.logger.ErrorContext(ctx, "signup token used for an identity that is already linked", "provider", claims.Provider)
+		return resp, helper.NewResponseError(helper.ErrInvalidSignupToken, http.StatusUnauthorized)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		s.logger.ErrorContext(ctx, err.Error())
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	user.Username = data.Username
+	user.Email = claims.Email
+	user.Age = data.Age
+	user.EmailVerified = true
+
+	user.Password, err = helper.HashPassword(oidc.GenerateVerifier())
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	user, err = s.userRepo.SaveWithIdentity(ctx, user, model.UserIdentity{
+		Provider: claims.Provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		pqErr := new(pq.Error)
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			// the same token used twice at once
+			if pqErr.Constraint == "user_identity_pkey" {
+				return resp, helper.NewResponseError(helper.ErrInvalidSignupToken, http.StatusUnauthorized)
+			}
+			return resp, helper.NewResponseError(helper.ErrDuplicateUserEmail, http.StatusConflict)
+		}
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	s.audit.Record(ctx, model.AuditEvent{
+		ActorID:  user.ID,
+		UserID:   user.ID,
+		Action:   model.AuditAccountCreated,
+		Metadata: map[string]string{"provider": claims.Provider},
+	})
+	metrics.Count(metrics.EventRegistration)
+
+	return s.completeLogin(ctx, user, nil)
+}
+
+// OIDCLink links the identity of a link token from OIDCCallback to the
+// logged in user.
+func (s *userService) OIDCLink(ctx context.Context, data dto.OIDCLinkRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "userService.OIDCLink")
+	defer tracing.End(span, &err)
+
+	principal, ok := helper.UserFromContext(ctx)
+	if !ok {
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
+		return helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	claims, err := helper.VerifyLinkJWT(data.LinkToken)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		return helper.NewResponseError(helper.ErrInvalidLinkToken, http.StatusBadRequest)
+	}
+
+	return s.linkIdentity(ctx, principal.UserID, claims.Provider, claims.Subject, claims.Email)
+}
+
+func (s *userService) linkIdentity(ctx context.Context, userID uint64, provider, subject, email string) error {
+	err := s.userRepo.LinkIdentity(ctx, model.UserIdentity{
+		Provider: provider,
+		Subject:  subject,
+		UserID:   userID,
+		Email:    email,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		pqErr := new(pq.Error)
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return helper.NewResponseError(helper.ErrInvalidLinkToken, http.StatusBadRequest)
+		}
+		return helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	s.logger.InfoContext(ctx, "OIDC identity linked", "provider", provider, "user_id", userID)
+	s.audit.Record(ctx, model.AuditEvent{
+		ActorID:    userID,
+		UserID:     userID,
+		Action:     model.AuditIdentityLinked,
+		TargetType: "identity",
+		TargetID:   provider,
+		Metadata:   map[string]string{"email": helper.MaskEmail(email)},
+	})
+	return nil
+}
+
+func (s *userService) oidcLogin(ctx context.Context, userID uint64) (dto.UserLoginResponse, error) {
+	var resp dto.UserLoginResponse
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	if user.LockedUntil.Valid && user.LockedUntil.Time.After(time.Now()) {
+		s.logger.WarnContext(ctx, "login refused, account is locked", "user_id", user.ID)
+		return resp, helper.NewResponseError(lockedError(user.LockedUntil.Time), http.StatusLocked)
+	}
+
+	return s.firstFactorPassed(ctx, user, nil)
+}
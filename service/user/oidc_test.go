@@ -0,0 +1,189 @@
+package userservice
+
+import (
+	"context"
+	"final-project/dto"
+	"final-project/helper"
+	"final-project/lib/oidc"
+	"final-project/lib/oidc/oidctest"
+	"final-project/model"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+const redirectURL = "http://mygram.test/callback"
+
+func useTestJWTKeys(t *testing.T) {
+	t.Helper()
+	keys, expiresIn, issuer, audience := helper.JWTKeys, helper.JWTExpiresIn, helper.JWTIssuer, helper.JWTAudience
+	t.Cleanup(func() {
+		helper.JWTKeys, helper.JWTExpiresIn, helper.JWTIssuer, helper.JWTAudience = keys, expiresIn, issuer, audience
+	})
+
+	helper.JWTKeys = helper.NewKeySet(helper.NewHMACKey([]byte("secret")))
+	helper.JWTExpiresIn = time.Minute
+	helper.JWTIssuer, helper.JWTAudience = "mygram", "mygram-api"
+}
+
+// oidcLogin runs a login with the test provider up to the callback and
+// returns the callback's result.
+func oidcLogin(t *testing.T, s *userService) (dto.UserLoginResponse, error) {
+	t.Helper()
+	ctx := context.Background()
+
+	authURL, stateToken, err := s.OIDCAuthURL(ctx, "test")
+	if err != nil {
+		t.Fatalf("OIDCAuthURL() = %v", err)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	res, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := location.Query()
+
+	return s.OIDCCallback(ctx, "test", query.Get("code"), query.Get("state"), stateToken)
+}
+
+func newOIDCTestService(t *testing.T, identity oidc.Identity, users ...model.User) (*userService, *userRepo) {
+	t.Helper()
+	useTestJWTKeys(t)
+
+	server := oidctest.NewServer(identity)
+	t.Cleanup(server.Close)
+
+	repo := newUserRepo(users...)
+	s, _ := newTestService(t, repo, &auditLog{}, &mailbox{})
+	s.opts.OIDCProviders = map[string]*oidc.Provider{"test": oidc.New(server.Config(redirectURL))}
+	return s, repo
+}
+
+func TestOIDCCallbackLinksVerifiedEmail(t *testing.T) {
+	identity := oidc.Identity{Subject: "1234", Email: "budi@rocketmail.com", EmailVerified: true}
+	s, repo := newOIDCTestService(t, identity, model.User{ID: 1, Email: "budi@rocketmail.com", EmailVerified: true})
+
+	resp, err := oidcLogin(t, s)
+	if err != nil {
+		t.Fatalf("OIDCCallback() = %v", err)
+	}
+	if resp.Token == "" || resp.LinkRequired || resp.SignupRequired {
+		t.Errorf("OIDCCallback() = %+v, want a login", resp)
+	}
+	if got := repo.identities["test/1234"].UserID; got != 1 {
+		t.Errorf("identity linked to user %d, want 1", got)
+	}
+}
+
+func TestOIDCCallbackRequiresExplicitLink(t *testing.T) {
+	tests := []struct {
+		name          string
+		identity      oidc.Identity
+		localVerified bool
+	}{
+		{"local email unverified", oidc.Identity{Subject: "1234", Email: "budi@rocketmail.com", EmailVerified: true}, false},
+		{"provider email unverified", oidc.Identity{Subject: "1234", Email: "budi@rocketmail.com"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newOIDCTestService(t, tt.identity, model.User{ID: 1, Email: "budi@rocketmail.com", EmailVerified: tt.localVerified})
+
+			resp, err := oidcLogin(t, s)
+			if err != nil {
+				t.Fatalf("OIDCCallback() = %v", err)
+			}
+			if !resp.LinkRequired || resp.LinkToken == "" || resp.Token != "" {
+				t.Fatalf("OIDCCallback() = %+v, want link_required", resp)
+			}
+			if len(repo.identities) != 0 {
+				t.Fatalf("identities = %v, want none before the explicit link", repo.identities)
+			}
+
+			ctx := helper.ContextWithUser(context.Background(), helper.Principal{UserID: 1})
+			if err := s.OIDCLink(ctx, dto.OIDCLinkRequest{LinkToken: resp.LinkToken}); err != nil {
+				t.Fatalf("OIDCLink() = %v", err)
+			}
+			if got := repo.identities["test/1234"].UserID; got != 1 {
+				t.Errorf("identity linked to user %d, want 1", got)
+			}
+
+			err = s.OIDCLink(ctx, dto.OIDCLinkRequest{LinkToken: resp.LinkToken})
+			if err == nil || err.Error() != helper.ErrInvalidLinkToken.Error() {
+				t.Errorf("OIDCLink() again = %v, want %v", err, helper.ErrInvalidLinkToken)
+			}
+
+			resp, err = oidcLogin(t, s)
+			if err != nil || resp.Token == "" {
+				t.Errorf("OIDCCallback() after linking = %+v, %v, want a login", resp, err)
+			}
+		})
+	}
+}
+
+func TestOIDCLinkRejectsSignupToken(t *testing.T) {
+	useTestJWTKeys(t)
+	s, _ := newTestService(t, newUserRepo(), &auditLog{}, &mailbox{})
+
+	token, err := helper.GenerateSignupJWT("test", "1234", "budi@rocketmail.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := helper.ContextWithUser(context.Background(), helper.Principal{UserID: 1})
+	err = s.OIDCLink(ctx, dto.OIDCLinkRequest{LinkToken: token})
+	if err == nil || err.Error() != helper.ErrInvalidLinkToken.Error() {
+		t.Errorf("OIDCLink() = %v, want %v", err, helper.ErrInvalidLinkToken)
+	}
+}
+
+func TestOIDCCallbackRefusesUnverifiedSignup(t *testing.T) {
+	s, _ := newOIDCTestService(t, oidc.Identity{Subject: "1234", Email: "budi@rocketmail.com"})
+
+	_, err := oidcLogin(t, s)
+	if code := responseCode(t, err); code != http.StatusUnprocessableEntity || err.Error() != helper.ErrOIDCEmailNotVerified.Error() {
+		t.Errorf("OIDCCallback() = %v (%d), want %v", err, code, helper.ErrOIDCEmailNotVerified)
+	}
+}
+
+func TestOIDCRegister(t *testing.T) {
+	s, repo := newOIDCTestService(t, oidc.Identity{Subject: "1234", Email: "budi@rocketmail.com", EmailVerified: true})
+
+	resp, err := oidcLogin(t, s)
+	if err != nil {
+		t.Fatalf("OIDCCallback() = %v", err)
+	}
+	if !resp.SignupRequired || resp.SignupToken == "" {
+		t.Fatalf("OIDCCallback() = %+v, want signup_required", resp)
+	}
+
+	register := dto.OIDCRegisterRequest{SignupToken: resp.SignupToken, Username: "budi", Age: 25}
+	resp, err = s.OIDCRegister(context.Background(), register)
+	if err != nil {
+		t.Fatalf("OIDCRegister() = %v", err)
+	}
+	if resp.Token == "" {
+		t.Errorf("OIDCRegister() = %+v, want a login", resp)
+	}
+
+	user, _ := repo.FindByEmail(context.Background(), "budi@rocketmail.com")
+	if !user.EmailVerified || repo.identities["test/1234"].UserID != user.ID {
+		t.Errorf("registered %+v, identities %v", user, repo.identities)
+	}
+
+	register.Username = "budi2"
+	_, err = s.OIDCRegister(context.Background(), register)
+	if code := responseCode(t, err); code != http.StatusUnauthorized || err.Error() != helper.ErrInvalidSignupToken.Error() {
+		t.Errorf("OIDCRegister() with a used token = %v (%d), want %v", err, code, helper.ErrInvalidSignupToken)
+	}
+}
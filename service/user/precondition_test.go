@@ -0,0 +1,71 @@
+package userservice
+
+import (
+	"final-project/controller"
+	"final-project/helper"
+	"final-project/middleware"
+	"final-project/model"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRequiredIfMatch walks a client through the account routes with
+// require_if_match on: it can only write after reading the ETag.
+func TestRequiredIfMatch(t *testing.T) {
+	repo := newUserRepo(model.User{ID: 1, Username: "budi", Email: "budi@rocketmail.com", Age: 25, UpdatedAt: time.Now().Add(-time.Hour)})
+	s, _ := newTestService(t, repo, &auditLog{}, &mailbox{})
+	c := controller.NewUserController(s)
+
+	auth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(helper.ContextWithUser(r.Context(), helper.Principal{UserID: 1})))
+		})
+	}
+	preconditions := middleware.NewPreconditions(true)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /users", auth(http.HandlerFunc(c.Get)))
+	mux.Handle("PATCH /users", auth(preconditions(http.HandlerFunc(c.Patch))))
+	mux.Handle("DELETE /users", auth(preconditions(http.HandlerFunc(c.Delete))))
+
+	do := func(t *testing.T, method, header, value string, want int) *httptest.ResponseRecorder {
+		t.Helper()
+		body := ""
+		if method == http.MethodPatch {
+			body = `{"username":"budi2"}`
+		}
+		r := httptest.NewRequest(method, "/users", strings.NewReader(body))
+		if header != "" {
+			r.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		if w.Code != want {
+			t.Fatalf("%s /users with %s %q = %d, want %d: %s", method, header, value, w.Code, want, w.Body.String())
+		}
+		return w
+	}
+
+	do(t, http.MethodPatch, "", "", http.StatusPreconditionRequired)
+
+	etag := do(t, http.MethodGet, "", "", http.StatusOK).Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("GET /users didn't send an ETag")
+	}
+	do(t, http.MethodGet, "If-None-Match", etag, http.StatusNotModified)
+
+	do(t, http.MethodPatch, "If-Match", `"stale"`, http.StatusPreconditionFailed)
+	patched := do(t, http.MethodPatch, "If-Match", etag, http.StatusOK).Header().Get("ETag")
+	if patched == "" || patched == etag {
+		t.Fatalf("PATCH /users sent ETag %q, want a new version after %q", patched, etag)
+	}
+	if got := do(t, http.MethodGet, "", "", http.StatusOK).Header().Get("ETag"); got != patched {
+		t.Errorf("GET /users after PATCH sent ETag %q, want %q", got, patched)
+	}
+
+	do(t, http.MethodDelete, "If-Match", etag, http.StatusPreconditionFailed)
+	do(t, http.MethodDelete, "If-Match", patched, http.StatusOK)
+}
@@ -6,28 +6,69 @@ import (
 	"errors"
 	"final-project/dto"
 	"final-project/helper"
+	"final-project/lib/mail"
+	"final-project/lib/metrics"
+	"final-project/lib/oidc"
+	"final-project/lib/tracing"
+	"final-project/lib/worker"
 	"final-project/model"
 	"final-project/repository"
+	"final-project/service"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/lib/pq"
 )
 
+type Options struct {
+	DeletionDelay     time.Duration
+	AnonymizeComments bool
+	Lockout           Lockout
+	TOTPIssuer        string
+	OIDCProviders     map[string]*oidc.Provider
+}
+
+// Lockout configures brute-force protection on login. An account is locked
+// for Duration after MaxFailures wrong passwords in a row, and a client IP
+// is refused once it has IPMaxFailures failures within IPWindow. Every
+// failed attempt is answered after a delay that doubles from DelayBase up
+// to DelayMax.
+type Lockout struct {
+	MaxFailures   uint64
+	Duration      time.Duration
+	IPMaxFailures uint64
+	IPWindow      time.Duration
+	DelayBase     time.Duration
+	DelayMax      time.Duration
+}
+
 type userService struct {
-	userRepo repository.UserRepository
-	logger   *slog.Logger
+	userRepo    repository.UserRepository
+	sessionRepo repository.SessionRepository
+	audit       service.AuditRecorder
+	mailer      mail.Mailer
+	pool        *worker.Pool
+	opts        Options
+	logger      *slog.Logger
 }
 
-func New(userRepo repository.UserRepository, logger *slog.Logger) *userService {
-	return &userService{userRepo, logger}
+// New takes the worker pool notification mails are sent from, so shutting
+// it down waits for them.
+func New(userRepo repository.UserRepository, sessionRepo repository.SessionRepository, audit service.AuditRecorder, mailer mail.Mailer, pool *worker.Pool, opts Options, logger *slog.Logger) *userService {
+	return &userService{userRepo, sessionRepo, audit, mailer, pool, opts, logger}
 }
 
-func (s *userService) Create(ctx context.Context, data dto.UserRequest) (dto.UserCreateResponse, error) {
+func (s *userService) Create(ctx context.Context, data dto.UserRequest) (_ dto.UserCreateResponse, err error) {
+	ctx, span := tracing.Start(ctx, "userService.Create")
+	defer tracing.End(span, &err)
+
 	var (
 		resp dto.UserCreateResponse
 		user model.User
-		err  error
 	)
 
 	user.Username = data.Username
@@ -52,6 +93,9 @@ func (s *userService) Create(ctx context.Context, data dto.UserRequest) (dto.Use
 		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
+	s.audit.Record(ctx, model.AuditEvent{ActorID: user.ID, UserID: user.ID, Action: model.AuditAccountCreated})
+	metrics.Count(metrics.EventRegistration)
+
 	resp.ID = user.ID
 	resp.Username = user.Username
 	resp.Email = user.Email
@@ -60,24 +104,81 @@ func (s *userService) Create(ctx context.Context, data dto.UserRequest) (dto.Use
 	return resp, nil
 }
 
-func (s *userService) Login(ctx context.Context, data dto.UserRequest) (dto.UserLoginResponse, error) {
+func (s *userService) Login(ctx context.Context, data dto.UserRequest) (_ dto.UserLoginResponse, err error) {
+	ctx, span := tracing.Start(ctx, "userService.Login")
+	defer tracing.End(span, &err)
+
 	var resp dto.UserLoginResponse
 
+	ip, ipFailures, err := s.checkClientIP(ctx)
+	if err != nil {
+		return resp, err
+	}
+
 	user, err := s.userRepo.FindByEmail(ctx, data.Email)
 	if err != nil {
 		s.logger.ErrorContext(ctx, err.Error())
 		if errors.Is(err, sql.ErrNoRows) {
+			s.audit.Record(ctx, model.AuditEvent{
+				Action:   model.AuditLoginFailed,
+				Metadata: map[string]string{"email": helper.MaskEmail(data.Email), "reason": helper.ErrUserNotFound.Error()},
+			})
+			s.loginFailed(ctx, 0, ip, ipFailures)
 			return resp, helper.NewResponseError(helper.ErrInvalidLogin, http.StatusUnauthorized)
 		}
 		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
+	if user.LockedUntil.Valid && user.LockedUntil.Time.After(time.Now()) {
+		s.logger.WarnContext(ctx, "login refused, account is locked", "user_id", user.ID)
+		s.audit.Record(ctx, model.AuditEvent{
+			UserID:   user.ID,
+			Action:   model.AuditLoginFailed,
+			Metadata: map[string]string{"reason": helper.ErrAccountLocked.Error()},
+		})
+		return resp, helper.NewResponseError(lockedError(user.LockedUntil.Time), http.StatusLocked)
+	}
+
 	if !helper.IsValidPassword(user.Password, data.Password) {
 		s.logger.ErrorContext(ctx, "invalid password")
-		return resp, helper.NewResponseError(helper.ErrInvalidLogin, http.StatusUnauthorized)
+		return resp, s.rejectLogin(ctx, user, ip, ipFailures, helper.ErrInvalidLogin)
+	}
+
+	if helper.NeedsRehash(user.Password) {
+		s.rehashPassword(ctx, user, data.Password)
+	}
+
+	return s.firstFactorPassed(ctx, user, data.Scopes)
+}
+
+// rehashPassword upgrades a hash made with old parameters while the password
+// is known. A failure only means the upgrade waits for the next login.
+func (s *userService) rehashPassword(ctx context.Context, user model.User, password string) {
+	hashed, err := helper.HashPassword(password)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error(), "cause", "rehash password")
+		return
+	}
+
+	if err := s.userRepo.RehashPassword(ctx, user.ID, user.Password, hashed); err != nil {
+		s.logger.ErrorContext(ctx, err.Error(), "cause", "rehash password", "user_id", user.ID)
 	}
+}
+
+// firstFactorPassed either finishes the login or, for users with two-factor
+// authentication, hands out the challenge for the second step.
+func (s *userService) firstFactorPassed(ctx context.Context, user model.User, scopes []string) (dto.UserLoginResponse, error) {
+	var (
+		resp dto.UserLoginResponse
+		err  error
+	)
 
-	resp.Token, err = helper.GenerateJWT(user.ID)
+	if !user.TOTPEnabled {
+		return s.completeLogin(ctx, user, scopes)
+	}
+
+	resp.TwoFactorRequired = true
+	resp.ChallengeToken, err = helper.GenerateChallengeJWT(user.ID, scopes)
 	if err != nil {
 		s.logger.ErrorContext(ctx, err.Error())
 		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
@@ -86,15 +187,544 @@ func (s *userService) Login(ctx context.Context, data dto.UserRequest) (dto.User
 	return resp, nil
 }
 
-func (s *userService) Update(ctx context.Context, data dto.UserRequest) (resp dto.UserUpdateResponse, err error) {
+// LoginTOTP is the second step of a login for users with two-factor
+// authentication. code is either a TOTP code or an unused recovery code.
+func (s *userService) LoginTOTP(ctx context.Context, data dto.UserLoginTOTPRequest) (_ dto.UserLoginResponse, err error) {
+	ctx, span := tracing.Start(ctx, "userService.LoginTOTP")
+	defer tracing.End(span, &err)
+
+	var resp dto.UserLoginResponse
+
+	ip, ipFailures, err := s.checkClientIP(ctx)
+	if err != nil {
+		return resp, err
+	}
+
+	userID, scopes, err := helper.VerifyChallengeJWT(data.ChallengeToken)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		return resp, helper.NewResponseError(helper.ErrInvalidChallengeToken, http.StatusUnauthorized)
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		if errors.Is(err, sql.ErrNoRows) {
+			return resp, helper.NewResponseError(helper.ErrInvalidChallengeToken, http.StatusUnauthorized)
+		}
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	if user.LockedUntil.Valid && user.LockedUntil.Time.After(time.Now()) {
+		s.logger.WarnContext(ctx, "login refused, account is locked", "user_id", user.ID)
+		s.audit.Record(ctx, model.AuditEvent{
+			UserID:   user.ID,
+			Action:   model.AuditLoginFailed,
+			Metadata: map[string]string{"reason": helper.ErrAccountLocked.Error()},
+		})
+		return resp, helper.NewResponseError(lockedError(user.LockedUntil.Time), http.StatusLocked)
+	}
+
+	if !user.TOTPEnabled {
+		s.logger.ErrorContext(ctx, "challenge token used by a user without two-factor authentication", "user_id", user.ID)
+		return resp, helper.NewResponseError(helper.ErrInvalidChallengeToken, http.StatusUnauthorized)
+	}
+
+	ok, err := s.checkSecondFactor(ctx, user, data.Code)
+	if err != nil {
+		return resp, err
+	}
 
-	userID, ok := ctx.Value(helper.UserIDKey).(float64)
 	if !ok {
-		s.logger.ErrorContext(ctx, "ctx.Value(helper.UserIDKey).(float64): userID is not float64")
+		s.logger.ErrorContext(ctx, "invalid two-factor code")
+		return resp, s.rejectLogin(ctx, user, ip, ipFailures, helper.ErrInvalidTOTPCode)
+	}
+
+	return s.completeLogin(ctx, user, scopes)
+}
+
+func (s *userService) completeLogin(ctx context.Context, user model.User, scopes []string) (dto.UserLoginResponse, error) {
+	var (
+		resp dto.UserLoginResponse
+		err  error
+	)
+
+	if user.FailedLogins > 0 || user.LockedUntil.Valid {
+		err = s.userRepo.Unlock(ctx, user.ID)
+		if err != nil {
+			s.logger.ErrorContext(ctx, err.Error())
+			return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+		}
+	}
+
+	if user.DeleteAfter.Valid {
+		err = s.userRepo.CancelDeletion(ctx, user.ID)
+		if err != nil {
+			s.logger.ErrorContext(ctx, err.Error())
+			return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+		}
+		resp.DeletionCancelled = true
+		s.audit.Record(ctx, model.AuditEvent{ActorID: user.ID, UserID: user.ID, Action: model.AuditAccountDeletionCancelled})
+	}
+
+	ip, _ := ctx.Value(helper.ClientIPKey).(string)
+	userAgent, _ := ctx.Value(helper.UserAgentKey).(string)
+	session, err := s.sessionRepo.Save(ctx, model.Session{
+		UserID:    user.ID,
+		UserAgent: userAgent,
+		IP:        ip,
+		ExpiresAt: time.Now().Add(helper.JWTExpiresIn),
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	resp.Token, err = helper.GenerateJWT(user.ID, user.Role, session.ID, scopes)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
 		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
-	user, err := s.userRepo.FindByID(ctx, uint64(userID))
+	s.audit.Record(ctx, model.AuditEvent{
+		ActorID:    user.ID,
+		UserID:     user.ID,
+		Action:     model.AuditLogin,
+		TargetType: "session",
+		TargetID:   strconv.FormatUint(session.ID, 10),
+	})
+
+	return resp, nil
+}
+
+func (s *userService) checkClientIP(ctx context.Context) (string, uint64, error) {
+	ip, _ := ctx.Value(helper.ClientIPKey).(string)
+	failures, err := s.userRepo.CountLoginFailuresByIP(ctx, ip, time.Now().Add(-s.opts.Lockout.IPWindow))
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		return ip, 0, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	if failures >= s.opts.Lockout.IPMaxFailures {
+		s.logger.WarnContext(ctx, "login refused, too many failures from client", "ip", ip)
+		return ip, failures, helper.NewResponseError(helper.ErrTooManyLoginAttempts, http.StatusTooManyRequests)
+	}
+
+	return ip, failures, nil
+}
+
+func (s *userService) rejectLogin(ctx context.Context, user model.User, ip string, ipFailures uint64, cause error) error {
+	s.audit.Record(ctx, model.AuditEvent{
+		UserID:   user.ID,
+		Action:   model.AuditLoginFailed,
+		Metadata: map[string]string{"reason": cause.Error()},
+	})
+
+	failed := s.loginFailed(ctx, user.ID, ip, max(ipFailures, user.FailedLogins))
+	if failed.LockedUntil.Valid && failed.LockedUntil.Time.After(time.Now()) {
+		s.audit.Record(ctx, model.AuditEvent{
+			UserID:   user.ID,
+			Action:   model.AuditAccountLocked,
+			Metadata: map[string]string{"locked_until": failed.LockedUntil.Time.UTC().Format(time.RFC3339)},
+		})
+		s.notifyLocked(ctx, user, failed.LockedUntil.Time)
+		return helper.NewResponseError(lockedError(failed.LockedUntil.Time), http.StatusLocked)
+	}
+	return helper.NewResponseError(cause, http.StatusUnauthorized)
+}
+
+// loginFailed records a failed attempt and then waits before the failure is
+// answered, longer the more failures came before it.
+func (s *userService) loginFailed(ctx context.Context, userID uint64, ip string, previous uint64) model.User {
+	user, err := s.userRepo.RecordLoginFailure(ctx, userID, ip, s.opts.Lockout.MaxFailures, s.opts.Lockout.Duration)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error(), "cause", "record login failure")
+	}
+
+	timer := time.NewTimer(loginDelay(previous, s.opts.Lockout.DelayBase, s.opts.Lockout.DelayMax))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	return user
+}
+
+func (s *userService) notifyLocked(ctx context.Context, user model.User, until time.Time) {
+	body := fmt.Sprintf("Hi %s,\n\n"+
+		"Your MyGram account has been locked until %s because of too many failed login attempts.\n"+
+		"If this wasn't you, someone may be trying to guess your password. Consider changing it once the lock expires.\n",
+		user.Username, until.Format(time.RFC1123))
+
+	err := s.pool.Submit(func(ctx context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+		if err := s.mailer.Send(ctx, user.Email, "Your MyGram account has been locked", body); err != nil {
+			s.logger.ErrorContext(ctx, err.Error(), "cause", "send lockout notification", "user_id", user.ID)
+		}
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error(), "cause", "send lockout notification", "user_id", user.ID)
+	}
+}
+
+func (s *userService) Unlock(ctx context.Context, userID uint64) (err error) {
+	ctx, span := tracing.Start(ctx, "userService.Unlock")
+	defer tracing.End(span, &err)
+
+	err = s.userRepo.Unlock(ctx, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		if errors.Is(err, sql.ErrNoRows) {
+			return helper.NewResponseError(helper.ErrUserNotFound, http.StatusNotFound)
+		}
+		return helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	s.audit.Record(ctx, model.AuditEvent{UserID: userID, Action: model.AuditAccountUnlocked})
+
+	return nil
+}
+
+// PurgeLoginFailures drops failed login records that no longer count
+// towards the per-IP limit.
+func (s *userService) PurgeLoginFailures(ctx context.Context) {
+	ctx, span := tracing.Start(ctx, "userService.PurgeLoginFailures")
+	defer span.End()
+
+	err := s.userRepo.DeleteLoginFailuresBefore(ctx, time.Now().Add(-s.opts.Lockout.IPWindow))
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error(), "cause", "purge login failures")
+	}
+}
+
+func (s *userService) SetupTOTP(ctx context.Context) (_ dto.TOTPSetupResponse, err error) {
+	ctx, span := tracing.Start(ctx, "userService.SetupTOTP")
+	defer tracing.End(span, &err)
+
+	var resp dto.TOTPSetupResponse
+
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return resp, err
+	}
+
+	if user.TOTPEnabled {
+		s.logger.ErrorContext(ctx, "two-factor authentication is already enabled", "user_id", user.ID)
+		return resp, helper.NewResponseError(helper.ErrTOTPAlreadyEnabled, http.StatusConflict)
+	}
+
+	secret, err := helper.GenerateTOTPSecret()
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	err = s.userRepo.SetTOTPSecret(ctx, user.ID, secret)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		if errors.Is(err, sql.ErrNoRows) {
+			return resp, helper.NewResponseError(helper.ErrTOTPAlreadyEnabled, http.StatusConflict)
+		}
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	resp.Secret = secret
+	resp.URI = helper.TOTPURI(s.opts.TOTPIssuer, user.Email, secret)
+
+	return resp, nil
+}
+
+func (s *userService) EnableTOTP(ctx context.Context, data dto.TOTPCodeRequest) (_ dto.RecoveryCodesResponse, err error) {
+	ctx, span := tracing.Start(ctx, "userService.EnableTOTP")
+	defer tracing.End(span, &err)
+
+	var resp dto.RecoveryCodesResponse
+
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return resp, err
+	}
+
+	if user.TOTPEnabled {
+		s.logger.ErrorContext(ctx, "two-factor authentication is already enabled", "user_id", user.ID)
+		return resp, helper.NewResponseError(helper.ErrTOTPAlreadyEnabled, http.StatusConflict)
+	}
+
+	if !user.TOTPSecret.Valid {
+		s.logger.ErrorContext(ctx, "two-factor authentication hasn't been set up", "user_id", user.ID)
+		return resp, helper.NewResponseError(helper.ErrTOTPNotSetUp, http.StatusConflict)
+	}
+
+	step, ok := helper.ValidateTOTP(user.TOTPSecret.String, data.Code, time.Now())
+	if !ok {
+		s.logger.ErrorContext(ctx, "invalid two-factor code", "user_id", user.ID)
+		return resp, helper.NewResponseError(helper.ErrInvalidTOTPCode, http.StatusBadRequest)
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	err = s.userRepo.EnableTOTP(ctx, user.ID, step, hashes)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		if errors.Is(err, sql.ErrNoRows) {
+			return resp, helper.NewResponseError(helper.ErrTOTPAlreadyEnabled, http.StatusConflict)
+		}
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	s.audit.Record(ctx, model.AuditEvent{UserID: user.ID, Action: model.AuditTOTPEnabled})
+
+	resp.RecoveryCodes = codes
+
+	return resp, nil
+}
+
+func (s *userService) DisableTOTP(ctx context.Context, data dto.TOTPCodeRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "userService.DisableTOTP")
+	defer tracing.End(span, &err)
+
+	user, err := s.currentEnrolledUser(ctx, data.Code)
+	if err != nil {
+		return err
+	}
+
+	err = s.userRepo.DisableTOTP(ctx, user.ID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		return helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	s.audit.Record(ctx, model.AuditEvent{UserID: user.ID, Action: model.AuditTOTPDisabled})
+	s.revokeSessions(ctx, user.ID, true, model.AuditTOTPDisabled)
+
+	return nil
+}
+
+func (s *userService) RegenerateRecoveryCodes(ctx context.Context, data dto.TOTPCodeRequest) (_ dto.RecoveryCodesResponse, err error) {
+	ctx, span := tracing.Start(ctx, "userService.RegenerateRecoveryCodes")
+	defer tracing.End(span, &err)
+
+	var resp dto.RecoveryCodesResponse
+
+	user, err := s.currentEnrolledUser(ctx, data.Code)
+	if err != nil {
+		return resp, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	err = s.userRepo.ReplaceRecoveryCodes(ctx, user.ID, hashes)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	s.audit.Record(ctx, model.AuditEvent{UserID: user.ID, Action: model.AuditRecoveryCodesRegenerated})
+
+	resp.RecoveryCodes = codes
+
+	return resp, nil
+}
+
+func (s *userService) currentUser(ctx context.Context) (model.User, error) {
+	principal, ok := helper.UserFromContext(ctx)
+	if !ok {
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
+		return model.User{}, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	user, err := s.userRepo.FindByID(ctx, principal.UserID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		if errors.Is(err, sql.ErrNoRows) {
+			return user, helper.NewResponseError(helper.ErrUserNotFound, http.StatusNotFound)
+		}
+		return user, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	return user, nil
+}
+
+// currentEnrolledUser returns the current user after checking that they
+// have two-factor authentication enabled and that code is valid for it.
+func (s *userService) currentEnrolledUser(ctx context.Context, code string) (model.User, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return user, err
+	}
+
+	if !user.TOTPEnabled {
+		s.logger.ErrorContext(ctx, "two-factor authentication is not enabled", "user_id", user.ID)
+		return user, helper.NewResponseError(helper.ErrTOTPNotEnabled, http.StatusConflict)
+	}
+
+	ok, err := s.checkSecondFactor(ctx, user, code)
+	if err != nil {
+		return user, err
+	}
+
+	if !ok {
+		s.logger.ErrorContext(ctx, "invalid two-factor code", "user_id", user.ID)
+		return user, helper.NewResponseError(helper.ErrInvalidTOTPCode, http.StatusBadRequest)
+	}
+
+	return user, nil
+}
+
+// checkSecondFactor accepts a TOTP code that hasn't been used yet or an
+// unused recovery code, and consumes it.
+func (s *userService) checkSecondFactor(ctx context.Context, user model.User, code string) (bool, error) {
+	var err error
+
+	if step, ok := helper.ValidateTOTP(user.TOTPSecret.String, code, time.Now()); ok {
+		err = s.userRepo.UseTOTPStep(ctx, user.ID, step)
+	} else {
+		err = s.userRepo.UseRecoveryCode(ctx, user.ID, helper.HashRecoveryCode(code))
+	}
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		s.logger.ErrorContext(ctx, err.Error())
+		return false, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	return true, nil
+}
+
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes, err := helper.GenerateRecoveryCodes(10)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, helper.HashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// revokeSessions signs a user out after a security relevant change, on
+// every device but the one making the request when keepCurrent is set.
+// Failures are only logged, the change itself has been made already.
+func (s *userService) revokeSessions(ctx context.Context, userID uint64, keepCurrent bool, reason string) {
+	var except uint64
+	if principal, ok := helper.UserFromContext(ctx); ok && keepCurrent {
+		except = principal.SessionID
+	}
+
+	n, err := s.sessionRepo.RevokeAllByUserID(ctx, userID, except)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error(), "cause", "revoke sessions", "user_id", userID)
+		return
+	}
+
+	if n > 0 {
+		s.audit.Record(ctx, model.AuditEvent{
+			UserID:     userID,
+			Action:     model.AuditSessionRevoked,
+			TargetType: "session",
+			Metadata:   map[string]string{"reason": reason, "count": strconv.FormatInt(n, 10)},
+		})
+	}
+}
+
+func lockedError(until time.Time) error {
+	return fmt.Errorf("%w until %s", helper.ErrAccountLocked, until.UTC().Format(time.RFC3339))
+}
+
+func loginDelay(previous uint64, base, limit time.Duration) time.Duration {
+	delay := base
+	for range min(previous, 32) {
+		if delay >= limit {
+			break
+		}
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// Get returns the current user, its UpdatedAt is the version If-Match is
+// compared with.
+func (s *userService) Get(ctx context.Context) (_ dto.UserResponse, err error) {
+	ctx, span := tracing.Start(ctx, "userService.Get")
+	defer tracing.End(span, &err)
+
+	var resp dto.UserResponse
+
+	principal, ok := helper.UserFromContext(ctx)
+	if !ok {
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	user, err := s.userRepo.FindByID(ctx, principal.UserID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error())
+		if errors.Is(err, sql.ErrNoRows) {
+			return resp, helper.NewResponseError(helper.ErrUserNotFound, http.StatusNotFound)
+		}
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	resp = dto.UserResponse{
+		ID:            user.ID,
+		Age:           user.Age,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		Username:      user.Username,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+	}
+
+	return resp, nil
+}
+
+func (s *userService) Update(ctx context.Context, data dto.UserRequest) (_ dto.UserUpdateResponse, err error) {
+	ctx, span := tracing.Start(ctx, "userService.Update")
+	defer tracing.End(span, &err)
+
+	return s.update(ctx, func(user *model.User) {
+		user.Email = data.Email
+		user.Username = data.Username
+	})
+}
+
+func (s *userService) Patch(ctx context.Context, data dto.UserPatchRequest) (_ dto.UserUpdateResponse, err error) {
+	ctx, span := tracing.Start(ctx, "userService.Patch")
+	defer tracing.End(span, &err)
+
+	return s.update(ctx, func(user *model.User) {
+		if data.Email.Set {
+			user.Email = data.Email.Value
+		}
+		if data.Username.Set {
+			user.Username = data.Username.Value
+		}
+	})
+}
+
+func (s *userService) update(ctx context.Context, apply func(*model.User)) (resp dto.UserUpdateResponse, err error) {
+	principal, ok := helper.UserFromContext(ctx)
+	if !ok {
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	user, err := s.userRepo.FindByID(ctx, principal.UserID)
 	if err != nil {
 		s.logger.ErrorContext(ctx, err.Error())
 		if errors.Is(err, sql.ErrNoRows) {
@@ -103,8 +733,13 @@ func (s *userService) Update(ctx context.Context, data dto.UserRequest) (resp dt
 		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
-	user.Email = data.Email
-	user.Username = data.Username
+	if !helper.CheckIfMatch(ctx, helper.ETag(user.ID, user.UpdatedAt)) {
+		s.logger.ErrorContext(ctx, "helper.CheckIfMatch: user has been modified since it was fetched")
+		return resp, helper.NewResponseError(helper.ErrPreconditionFailed, http.StatusPreconditionFailed)
+	}
+
+	previous := user
+	apply(&user)
 
 	user, err = s.userRepo.Update(ctx, user)
 	if err != nil {
@@ -121,6 +756,22 @@ func (s *userService) Update(ctx context.Context, data dto.UserRequest) (resp dt
 		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
+	if user.Email != previous.Email {
+		s.audit.Record(ctx, model.AuditEvent{
+			UserID:   user.ID,
+			Action:   model.AuditEmailChanged,
+			Metadata: map[string]string{"from": helper.MaskEmail(previous.Email), "to": helper.MaskEmail(user.Email)},
+		})
+		s.revokeSessions(ctx, user.ID, true, model.AuditEmailChanged)
+	}
+	if user.Username != previous.Username {
+		s.audit.Record(ctx, model.AuditEvent{
+			UserID:   user.ID,
+			Action:   model.AuditUsernameChanged,
+			Metadata: map[string]string{"from": previous.Username, "to": user.Username},
+		})
+	}
+
 	resp = dto.UserUpdateResponse{
 		ID:        user.ID,
 		Username:  user.Username,
@@ -132,21 +783,89 @@ func (s *userService) Update(ctx context.Context, data dto.UserRequest) (resp dt
 	return resp, nil
 }
 
-func (s *userService) Delete(ctx context.Context) error {
-	userID, ok := ctx.Value(helper.UserIDKey).(float64)
+func (s *userService) Delete(ctx context.Context) (_ dto.UserDeleteResponse, err error) {
+	ctx, span := tracing.Start(ctx, "userService.Delete")
+	defer tracing.End(span, &err)
+
+	var resp dto.UserDeleteResponse
+
+	principal, ok := helper.UserFromContext(ctx)
 	if !ok {
-		s.logger.ErrorContext(ctx, "ctx.Value(helper.UserIDKey).(float64): userID is not float64")
-		return helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+		s.logger.ErrorContext(ctx, "helper.UserFromContext: no authenticated user in context")
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+	}
+
+	var version time.Time
+	if helper.HasIfMatch(ctx) {
+		user, err := s.userRepo.FindByID(ctx, principal.UserID)
+		if err != nil {
+			s.logger.ErrorContext(ctx, err.Error())
+			if errors.Is(err, sql.ErrNoRows) {
+				return resp, helper.NewResponseError(helper.ErrUserNotFound, http.StatusNotFound)
+			}
+			return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+		}
+
+		if !helper.CheckIfMatch(ctx, helper.ETag(user.ID, user.UpdatedAt)) {
+			s.logger.ErrorContext(ctx, "helper.CheckIfMatch: user has been modified since it was fetched")
+			return resp, helper.NewResponseError(helper.ErrPreconditionFailed, http.StatusPreconditionFailed)
+		}
+		version = user.UpdatedAt
 	}
 
-	err := s.userRepo.Delete(ctx, uint64(userID))
+	user, err := s.userRepo.ScheduleDeletion(ctx, principal.UserID, version, time.Now().Add(s.opts.DeletionDelay))
 	if err != nil {
 		s.logger.ErrorContext(ctx, err.Error())
 		if errors.Is(err, sql.ErrNoRows) {
-			return helper.NewResponseError(helper.ErrUserNotFound, http.StatusNotFound)
+			if !version.IsZero() {
+				// modified since the If-Match check above
+				return resp, helper.NewResponseError(helper.ErrPreconditionFailed, http.StatusPreconditionFailed)
+			}
+			return resp, helper.NewResponseError(helper.ErrUserNotFound, http.StatusNotFound)
 		}
-		return helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
+		return resp, helper.NewResponseError(helper.ErrInternal, http.StatusInternalServerError)
 	}
 
-	return nil
+	s.audit.Record(ctx, model.AuditEvent{
+		UserID:   user.ID,
+		Action:   model.AuditAccountDeletionScheduled,
+		Metadata: map[string]string{"delete_after": user.DeleteAfter.Time.UTC().Format(time.RFC3339)},
+	})
+	s.revokeSessions(ctx, user.ID, false, model.AuditAccountDeletionScheduled)
+
+	resp.DeleteAfter = user.DeleteAfter.Time
+
+	return resp, nil
+}
+
+// PurgeScheduled removes every account whose cooling-off period is over. It
+// runs as a background job, so failures are only logged.
+func (s *userService) PurgeScheduled(ctx context.Context) {
+	ctx, span := tracing.Start(ctx, "userService.PurgeScheduled")
+	defer span.End()
+
+	userIDs, err := s.userRepo.FindDueForDeletion(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, err.Error(), "cause", "purge scheduled users")
+		return
+	}
+
+	for _, userID := range userIDs {
+		files, err := s.userRepo.Purge(ctx, userID, s.opts.AnonymizeComments)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				s.logger.ErrorContext(ctx, err.Error(), "cause", "purge scheduled users", "user_id", userID)
+			}
+			continue
+		}
+
+		for _, file := range files {
+			if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+				s.logger.ErrorContext(ctx, err.Error(), "cause", "purge scheduled users", "user_id", userID)
+			}
+		}
+
+		s.audit.Record(ctx, model.AuditEvent{UserID: userID, Action: model.AuditAccountPurged})
+		s.logger.InfoContext(ctx, "user purged", "user_id", userID)
+	}
 }
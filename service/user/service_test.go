@@ -0,0 +1,411 @@
+package userservice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"final-project/dto"
+	"final-project/helper"
+	"final-project/lib/mail"
+	"final-project/lib/worker"
+	"final-project/model"
+	"final-project/repository"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// userRepo keeps users and login failures in memory. Methods the tests
+// don't need panic through the nil embedded interface.
+type userRepo struct {
+	repository.UserRepository
+
+	mu         sync.Mutex
+	users      map[uint64]model.User
+	identities map[string]model.UserIdentity
+	ipFailures map[string]uint64
+}
+
+func newUserRepo(users ...model.User) *userRepo {
+	r := &userRepo{users: map[uint64]model.User{}, identities: map[string]model.UserIdentity{}, ipFailures: map[string]uint64{}}
+	for _, u := range users {
+		r.users[u.ID] = u
+	}
+	return r
+}
+
+func (r *userRepo) FindByEmail(_ context.Context, email string) (model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return model.User{}, sql.ErrNoRows
+}
+
+func (r *userRepo) FindByID(_ context.Context, id uint64) (model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return model.User{}, sql.ErrNoRows
+	}
+	return u, nil
+}
+
+func (r *userRepo) CountLoginFailuresByIP(_ context.Context, ip string, _ time.Time) (uint64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ipFailures[ip], nil
+}
+
+func (r *userRepo) RecordLoginFailure(_ context.Context, userID uint64, ip string, maxFailures uint64, lockFor time.Duration) (model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ipFailures[ip]++
+	u, ok := r.users[userID]
+	if !ok {
+		return model.User{}, nil
+	}
+	u.FailedLogins++
+	if u.FailedLogins >= maxFailures {
+		u.LockedUntil = sql.NullTime{Time: time.Now().Add(lockFor), Valid: true}
+	}
+	r.users[userID] = u
+	return u, nil
+}
+
+func (r *userRepo) FindIDByIdentity(_ context.Context, provider, subject string) (uint64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	identity, ok := r.identities[provider+"/"+subject]
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+	return identity.UserID, nil
+}
+
+func (r *userRepo) LinkIdentity(_ context.Context, identity model.UserIdentity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.linkIdentity(identity)
+}
+
+func (r *userRepo) linkIdentity(identity model.UserIdentity) error {
+	key := identity.Provider + "/" + identity.Subject
+	if _, ok := r.identities[key]; ok {
+		return &pq.Error{Code: "23505", Constraint: "user_identity_pkey"}
+	}
+	r.identities[key] = identity
+	return nil
+}
+
+func (r *userRepo) SaveWithIdentity(_ context.Context, user model.User, identity model.UserIdentity) (model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.Email == user.Email {
+			return model.User{}, &pq.Error{Code: "23505", Constraint: "user__email_key"}
+		}
+	}
+	user.ID = uint64(len(r.users) + 1)
+	identity.UserID = user.ID
+	if err := r.linkIdentity(identity); err != nil {
+		return model.User{}, err
+	}
+	r.users[user.ID] = user
+	return user, nil
+}
+
+func (r *userRepo) Update(_ context.Context, user model.User) (model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user.UpdatedAt = time.Now()
+	r.users[user.ID] = user
+	return user, nil
+}
+
+func (r *userRepo) ScheduleDeletion(_ context.Context, userID uint64, _, deleteAfter time.Time) (model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[userID]
+	if !ok {
+		return model.User{}, sql.ErrNoRows
+	}
+	u.DeleteAfter = sql.NullTime{Time: deleteAfter, Valid: true}
+	r.users[userID] = u
+	return u, nil
+}
+
+// UseRecoveryCode accepts the hash of "recovery" once.
+func (r *userRepo) UseRecoveryCode(_ context.Context, userID uint64, codeHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if codeHash != helper.HashRecoveryCode("recovery") {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *userRepo) DisableTOTP(_ context.Context, userID uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u := r.users[userID]
+	u.TOTPEnabled = false
+	r.users[userID] = u
+	return nil
+}
+
+type sessionRepo struct {
+	repository.SessionRepository
+
+	mu     sync.Mutex
+	nextID uint64
+	// revoked holds the exceptID of every RevokeAllByUserID call
+	revoked []uint64
+}
+
+func (r *sessionRepo) RevokeAllByUserID(_ context.Context, _, exceptID uint64) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.revoked = append(r.revoked, exceptID)
+	return 1, nil
+}
+
+func (r *sessionRepo) Save(_ context.Context, session model.Session) (model.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	session.ID = r.nextID
+	return session, nil
+}
+
+type auditLog struct {
+	mu     sync.Mutex
+	events []model.AuditEvent
+}
+
+func (a *auditLog) Record(_ context.Context, e model.AuditEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, e)
+}
+
+func (a *auditLog) actions() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	actions := make([]string, 0, len(a.events))
+	for _, e := range a.events {
+		actions = append(actions, e.Action)
+	}
+	return actions
+}
+
+type sentMail struct {
+	to, subject string
+}
+
+type mailbox struct {
+	mu   sync.Mutex
+	sent []sentMail
+}
+
+func (m *mailbox) Send(_ context.Context, to, subject, _ string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, sentMail{to, subject})
+	return nil
+}
+
+var _ mail.Mailer = (*mailbox)(nil)
+
+func newTestService(t *testing.T, repo *userRepo, audit *auditLog, mailer mail.Mailer) (*userService, *worker.Pool) {
+	t.Helper()
+	return newTestServiceWithSessions(t, repo, &sessionRepo{}, audit, mailer)
+}
+
+func newTestServiceWithSessions(t *testing.T, repo *userRepo, sessions *sessionRepo, audit *auditLog, mailer mail.Mailer) (*userService, *worker.Pool) {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	pool := worker.New(1, 10, logger)
+	t.Cleanup(func() { pool.Shutdown(context.Background()) })
+
+	opts := Options{Lockout: Lockout{
+		MaxFailures:   3,
+		Duration:      time.Hour,
+		IPMaxFailures: 5,
+		IPWindow:      time.Hour,
+		DelayBase:     time.Millisecond,
+		DelayMax:      4 * time.Millisecond,
+	}}
+	return New(repo, sessions, audit, mailer, pool, opts, logger), pool
+}
+
+func responseCode(t *testing.T, err error) int {
+	t.Helper()
+	var respErr *helper.ResponseError
+	if !errors.As(err, &respErr) {
+		t.Fatalf("error %v is not a *helper.ResponseError", err)
+	}
+	return respErr.Code()
+}
+
+func TestLoginDelay(t *testing.T) {
+	tests := []struct {
+		previous uint64
+		want     time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{3, 8 * time.Second},
+		{5, 30 * time.Second},
+		{1 << 40, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := loginDelay(tt.previous, time.Second, 30*time.Second); got != tt.want {
+			t.Errorf("loginDelay(%d) = %v, want %v", tt.previous, got, tt.want)
+		}
+	}
+}
+
+func TestLoginLocksAccountAfterMaxFailures(t *testing.T) {
+	hash, err := helper.HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := newUserRepo(model.User{ID: 1, Username: "alice", Email: "alice@example.com", Password: hash})
+	audit := &auditLog{}
+	mailer := &mailbox{}
+	s, pool := newTestService(t, repo, audit, mailer)
+
+	ctx := context.WithValue(context.Background(), helper.ClientIPKey, "192.0.2.1")
+	login := dto.UserRequest{Email: "alice@example.com", Password: "wrong"}
+
+	for i := range 2 {
+		_, err := s.Login(ctx, login)
+		if code := responseCode(t, err); code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: code = %d, want %d", i+1, code, http.StatusUnauthorized)
+		}
+	}
+
+	_, err = s.Login(ctx, login)
+	if code := responseCode(t, err); code != http.StatusLocked {
+		t.Fatalf("third attempt: code = %d, want %d", code, http.StatusLocked)
+	}
+	if !strings.HasPrefix(err.Error(), helper.ErrAccountLocked.Error()) {
+		t.Errorf("third attempt: error = %q, want it to start with %q", err, helper.ErrAccountLocked)
+	}
+
+	// the right password doesn't help while the account is locked
+	_, err = s.Login(ctx, dto.UserRequest{Email: "alice@example.com", Password: "correct horse"})
+	if code := responseCode(t, err); code != http.StatusLocked {
+		t.Errorf("login while locked: code = %d, want %d", code, http.StatusLocked)
+	}
+
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() = %v", err)
+	}
+	if len(mailer.sent) != 1 || mailer.sent[0].to != "alice@example.com" {
+		t.Errorf("sent %+v, want one notification to alice@example.com", mailer.sent)
+	}
+
+	want := []string{
+		model.AuditLoginFailed, model.AuditLoginFailed,
+		model.AuditLoginFailed, model.AuditAccountLocked,
+		model.AuditLoginFailed,
+	}
+	got := audit.actions()
+	if len(got) != len(want) {
+		t.Fatalf("audit actions = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("audit action %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLoginRefusesClientIPAfterMaxFailures(t *testing.T) {
+	repo := newUserRepo()
+	audit := &auditLog{}
+	s, _ := newTestService(t, repo, audit, &mailbox{})
+
+	ctx := context.WithValue(context.Background(), helper.ClientIPKey, "192.0.2.2")
+	login := dto.UserRequest{Email: "nobody@example.com", Password: "wrong"}
+
+	for i := range 5 {
+		_, err := s.Login(ctx, login)
+		if code := responseCode(t, err); code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: code = %d, want %d", i+1, code, http.StatusUnauthorized)
+		}
+	}
+
+	_, err := s.Login(ctx, login)
+	if code := responseCode(t, err); code != http.StatusTooManyRequests {
+		t.Errorf("sixth attempt: code = %d, want %d", code, http.StatusTooManyRequests)
+	}
+
+	// the audit log outlives purged accounts, it doesn't keep addresses
+	if got := audit.events[0].Metadata["email"]; got != "n***@example.com" {
+		t.Errorf("audited email = %q, want it masked", got)
+	}
+
+	other := context.WithValue(context.Background(), helper.ClientIPKey, "192.0.2.3")
+	_, err = s.Login(other, login)
+	if code := responseCode(t, err); code != http.StatusUnauthorized {
+		t.Errorf("other client: code = %d, want %d", code, http.StatusUnauthorized)
+	}
+}
+
+func TestSecurityChangesRevokeSessions(t *testing.T) {
+	const current = 5
+	user := model.User{ID: 1, Username: "alice", Email: "alice@example.com", TOTPEnabled: true}
+	ctx := helper.ContextWithUser(context.Background(), helper.Principal{UserID: 1, SessionID: current})
+
+	tests := []struct {
+		name string
+		run  func(*userService) error
+		// want is the session kept by each revocation
+		want []uint64
+	}{
+		{"email change", func(s *userService) error {
+			_, err := s.Patch(ctx, dto.UserPatchRequest{Email: dto.Optional[string]{Set: true, Value: "alice@example.org"}})
+			return err
+		}, []uint64{current}},
+		{"username change", func(s *userService) error {
+			_, err := s.Patch(ctx, dto.UserPatchRequest{Username: dto.Optional[string]{Set: true, Value: "alice2"}})
+			return err
+		}, nil},
+		{"two-factor disabled", func(s *userService) error {
+			return s.DisableTOTP(ctx, dto.TOTPCodeRequest{Code: "recovery"})
+		}, []uint64{current}},
+		{"deletion scheduled", func(s *userService) error {
+			_, err := s.Delete(ctx)
+			return err
+		}, []uint64{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := &sessionRepo{}
+			s, _ := newTestServiceWithSessions(t, newUserRepo(user), sessions, &auditLog{}, &mailbox{})
+
+			if err := tt.run(s); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(sessions.revoked, tt.want) {
+				t.Errorf("revoked all sessions but %v, want %v", sessions.revoked, tt.want)
+			}
+		})
+	}
+}